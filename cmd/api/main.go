@@ -2,14 +2,19 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/gateway"
 	"project-app-bioskop-golang-homework-anas/internal/handler"
 	"project-app-bioskop-golang-homework-anas/internal/middleware"
 	"project-app-bioskop-golang-homework-anas/internal/repository"
@@ -67,53 +72,93 @@ func main() {
 	)
 	logger.Info("Email service initialized")
 
+	// Initialize Payment Gateways (simulator until real providers are plugged in)
+	paymentGateways, err := gateway.NewSimulatorRegistry(cfg.Payment.SimulatorMode, cfg.Payment.SimulatorMethodModes)
+	if err != nil {
+		logger.Fatal("Failed to initialize payment gateways", zap.Error(err))
+	}
+	logger.Info("Payment gateways initialized", zap.String("simulator_mode", cfg.Payment.SimulatorMode))
+
 	// Initialize Repositories
 	userRepo := repository.NewUserRepository(db)
 	authTokenRepo := repository.NewAuthTokenRepository(db)
 	otpRepo := repository.NewOTPRepository(db)
 	cinemaRepo := repository.NewCinemaRepository(db)
+	movieRepo := repository.NewMovieRepository(db)
 	showtimeRepo := repository.NewShowtimeRepository(db)
 	seatRepo := repository.NewSeatRepository(db)
+	screenRepo := repository.NewScreenRepository(db)
+	priceRuleRepo := repository.NewPriceRuleRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
 	paymentMethodRepo := repository.NewPaymentMethodRepository(db)
 	bookingRepo := repository.NewBookingRepository(db)
 	paymentRepo := repository.NewPaymentRepository(db)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
 	logger.Info("Repositories initialized")
 
 	// Initialize Services
 	otpService := service.NewOTPService(otpRepo, userRepo, emailService, logger.Log)            
 	authService := service.NewAuthService(userRepo, authTokenRepo, otpService, cfg, logger.Log) 
-	cinemaService := service.NewCinemaService(cinemaRepo, logger.Log)
-	seatService := service.NewSeatService(seatRepo, showtimeRepo, cinemaRepo, logger.Log)
+	cinemaService := service.NewCinemaService(cinemaRepo, screenRepo, logger.Log)
+	movieService := service.NewMovieService(movieRepo, showtimeRepo, logger.Log)
+	showtimeService := service.NewShowtimeService(showtimeRepo, cinemaRepo, movieRepo, screenRepo, cfg, logger.Log)
+	pricingService := service.NewPricingService(priceRuleRepo, logger.Log)
+	promotionService := service.NewPromotionService(promotionRepo, logger.Log)
+	seatService := service.NewSeatService(seatRepo, showtimeRepo, cinemaRepo, screenRepo, pricingService, cfg, logger.Log)
 	paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo, logger.Log)
-	bookingService := service.NewBookingService(bookingRepo, showtimeRepo, seatRepo, paymentMethodRepo, logger.Log)
-	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, paymentMethodRepo, logger.Log)
-	backgroundService := service.NewBackgroundService(authTokenRepo, otpRepo, logger.Log) 
+	bookingService := service.NewBookingService(bookingRepo, showtimeRepo, seatRepo, paymentMethodRepo, pricingService, promotionService, paymentGateways, cfg, logger.Log)
+	paymentService := service.NewPaymentService(paymentRepo, bookingRepo, paymentMethodRepo, promotionService, paymentGateways, cfg, logger.Log)
+	ticketService := service.NewTicketService(bookingRepo, cfg, logger.Log)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg, logger.Log)
+	backgroundService := service.NewBackgroundService(authTokenRepo, otpRepo, bookingRepo, idempotencyRepo, logger.Log) 
 	logger.Info("Services initialized")
 
+	// CLI subcommand: generate recurring showtimes instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "generate-schedule" {
+		if err := runGenerateSchedule(showtimeService, os.Args[2:]); err != nil {
+			fmt.Printf("Failed to generate schedule: %v\n", err)
+			logger.Fatal("Failed to generate schedule", zap.Error(err))
+		}
+		return
+	}
+
+	// Bootstrap first admin from config
+	if err := authService.BootstrapAdmin(context.Background()); err != nil {
+		logger.Fatal("Failed to bootstrap admin", zap.Error(err))
+	}
+
 	// Initialize Handlers
 	authHandler := handler.NewAuthHandler(authService, logger.Log)
 	otpHandler := handler.NewOTPHandler(otpService, logger.Log)
 	cinemaHandler := handler.NewCinemaHandler(cinemaService, logger.Log)
+	movieHandler := handler.NewMovieHandler(movieService, logger.Log)
+	showtimeHandler := handler.NewShowtimeHandler(showtimeService, logger.Log)
 	seatHandler := handler.NewSeatHandler(seatService, logger.Log)
 	paymentMethodHandler := handler.NewPaymentMethodHandler(paymentMethodService, logger.Log)
 	bookingHandler := handler.NewBookingHandler(bookingService, logger.Log)
 	paymentHandler := handler.NewPaymentHandler(paymentService, logger.Log)
+	ticketHandler := handler.NewTicketHandler(ticketService, logger.Log)
 	logger.Info("Handlers initialized")
 
 	// Initialize Middlewares
 	authMiddleware := middleware.NewAuthMiddleware(authService, logger.Log)
+	idempotencyMiddleware := middleware.NewIdempotencyMiddleware(idempotencyService, logger.Log)
 	logger.Info("Middlewares initialized")
 
 	// Setup Router
 	appRouter := router.NewRouter(
 		authHandler,
 		cinemaHandler,
+		movieHandler,
+		showtimeHandler,
 		seatHandler,
 		paymentMethodHandler,
 		bookingHandler,
 		paymentHandler,
 		otpHandler,
+		ticketHandler,
 		authMiddleware,
+		idempotencyMiddleware,
 		logger.Log,
 	)
 	httpHandler := appRouter.SetupRoutes()
@@ -131,6 +176,8 @@ func main() {
 	// Start background jobs
 	backgroundService.StartTokenCleanup(1 * time.Hour)
 	backgroundService.StartOTPCleanup(30 * time.Minute) // cleanup every 30 min
+	backgroundService.StartBookingExpiry(1 * time.Minute)
+	backgroundService.StartIdempotencyKeyCleanup(1 * time.Hour)
 	logger.Info("Background jobs started")
 
 	// Start server in goroutine
@@ -146,13 +193,39 @@ func main() {
 		fmt.Printf("   POST /api/resend-otp                  - Resend OTP \n")
 		fmt.Printf("   GET  /api/cinemas                     - Get all cinemas\n")
 		fmt.Printf("   GET  /api/cinemas/{id}                - Get cinema detail\n")
-		fmt.Printf("   GET  /api/cinemas/{id}/seats          - Get seat availability\n")
+		fmt.Printf("   GET  /api/cinemas/{id}/seats          - Get seat availability (?showtime_id=)\n")
+		fmt.Printf("   GET  /api/cinemas/{id}/showtimes      - Get cinema showtimes (?date=)\n")
+		fmt.Printf("   GET  /api/movies                      - Get all movies (?genre=&rating=)\n")
+		fmt.Printf("   GET  /api/movies/{id}                 - Get movie detail with showtimes\n")
+		fmt.Printf("   GET  /api/movies/{id}/showtimes       - Get movie showtimes (?from=&to=)\n")
 		fmt.Printf("   GET  /api/payment-methods             - Get payment methods\n")
-		fmt.Printf("   POST /api/pay                         - Process payment\n")
+		fmt.Printf("   POST /api/payments/webhook/{provider} - Payment provider webhook\n")
 		fmt.Printf("\n PROTECTED ENDPOINTS (Require Token):\n")
 		fmt.Printf("   POST /api/logout                      - Logout user\n")
 		fmt.Printf("   POST /api/booking                     - Create booking\n")
+		fmt.Printf("   GET  /api/bookings/{code}             - Get booking by code\n")
+		fmt.Printf("   GET  /api/bookings/{code}/ticket      - Get e-ticket QR code\n")
+		fmt.Printf("   POST /api/bookings/{id}/cancel        - Cancel booking\n")
+		fmt.Printf("   POST /api/pay                         - Process payment\n")
 		fmt.Printf("   GET  /api/user/bookings               - Get user bookings\n")
+		fmt.Printf("\n STAFF ENDPOINTS (Require Token, role staff/admin):\n")
+		fmt.Printf("   POST /api/checkin                     - Check in e-ticket\n")
+		fmt.Printf("\n ADMIN ENDPOINTS (Require Token, role admin):\n")
+		fmt.Printf("   POST /api/admin/cinemas               - Create cinema\n")
+		fmt.Printf("   PUT  /api/admin/cinemas/{id}          - Update cinema\n")
+		fmt.Printf("   DEL  /api/admin/cinemas/{id}          - Delete cinema\n")
+		fmt.Printf("   POST /api/admin/cinemas/{id}/screens  - Create screen\n")
+		fmt.Printf("   PUT  /api/admin/cinemas/{id}/screens/{screenId}/layout - Import seat map\n")
+		fmt.Printf("   POST /api/admin/cinemas/{id}/screens/{screenId}/seats  - Create seat\n")
+		fmt.Printf("   PUT  /api/admin/seats/{id}            - Update seat\n")
+		fmt.Printf("   DEL  /api/admin/seats/{id}            - Delete seat\n")
+		fmt.Printf("   POST /api/admin/movies                - Create movie\n")
+		fmt.Printf("   PUT  /api/admin/movies/{id}           - Update movie\n")
+		fmt.Printf("   DEL  /api/admin/movies/{id}           - Delete movie\n")
+		fmt.Printf("   POST /api/admin/showtimes             - Create showtime\n")
+		fmt.Printf("   POST /api/admin/showtimes/generate    - Generate recurring showtimes\n")
+		fmt.Printf("   PUT  /api/admin/showtimes/{id}        - Update showtime\n")
+		fmt.Printf("   DEL  /api/admin/showtimes/{id}        - Delete showtime\n")
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server", zap.Error(err))
@@ -180,3 +253,71 @@ func main() {
 	logger.Info("Server exited gracefully")
 	fmt.Println("Server stopped")
 }
+
+// runGenerateSchedule menjalankan generator jadwal berulang dari command line, mis.
+// go run cmd/api/main.go generate-schedule -screen 1 -movie 2 -from 2026-11-01 -to 2026-11-30 -times 13:00,19:00 -price 50000
+func runGenerateSchedule(showtimeService service.ShowtimeService, args []string) error {
+	flags := flag.NewFlagSet("generate-schedule", flag.ContinueOnError)
+	screenID := flags.Int("screen", 0, "screen (studio) ID")
+	movieID := flags.Int("movie", 0, "movie ID")
+	from := flags.String("from", "", "first date, YYYY-MM-DD")
+	to := flags.String("to", "", "last date (inclusive), YYYY-MM-DD")
+	times := flags.String("times", "", "comma separated start times, e.g. 13:00,19:00")
+	days := flags.String("days", "", "comma separated days of week (0 = Sunday), empty = every day")
+	price := flags.String("price", "", "ticket price, e.g. 50000")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	req := domain.ScheduleRequest{
+		ScreenID: *screenID,
+		MovieID:  *movieID,
+		From:     *from,
+		To:       *to,
+	}
+
+	for _, value := range strings.Split(*times, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			req.Times = append(req.Times, value)
+		}
+	}
+
+	for _, value := range strings.Split(*days, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		day, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid day of week %q", value)
+		}
+		req.DaysOfWeek = append(req.DaysOfWeek, day)
+	}
+
+	amount, err := domain.ParseMoney(*price)
+	if err != nil {
+		return fmt.Errorf("invalid price %q: %w", *price, err)
+	}
+	req.Price = amount
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		return err
+	}
+
+	// On failure the result still lists the showtimes created before the error
+	result, err := showtimeService.GenerateSchedule(context.Background(), &req)
+	if result == nil {
+		return err
+	}
+
+	fmt.Printf("Created %d showtime(s):\n", len(result.Created))
+	for _, showtime := range result.Created {
+		fmt.Printf("   #%d  %s\n", showtime.ID, showtime.StartsAt().Format("2006-01-02 15:04"))
+	}
+
+	fmt.Printf("Skipped %d slot(s):\n", len(result.Skipped))
+	for _, skip := range result.Skipped {
+		fmt.Printf("   %s %s  %s\n", skip.Date, skip.Time, skip.Reason)
+	}
+
+	return err
+}
@@ -2,17 +2,23 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Token    TokenConfig
-	SMTP     SMTPConfig
-	Log      LogConfig
+	App         AppConfig
+	Database    DatabaseConfig
+	Token       TokenConfig
+	SMTP        SMTPConfig
+	Log         LogConfig
+	Booking     BookingConfig
+	Payment     PaymentConfig
+	Idempotency IdempotencyConfig
+	Admin       AdminConfig
+	Showtime    ShowtimeConfig
 }
 
 type AppConfig struct {
@@ -49,6 +55,35 @@ type LogConfig struct {
 	File  string
 }
 
+type BookingConfig struct {
+	PaymentWindow          time.Duration // batas waktu bayar sebelum booking pending expired
+	CancellationFreeWindow time.Duration // gratis batal jika lebih dari ini sebelum showtime
+	CancellationFeePercent float64       // potongan refund jika batal di dalam window
+	SalesCutoff            time.Duration // penjualan tiket ditutup sekian lama setelah showtime mulai (negatif = sebelum mulai)
+	TicketSecret           string        // secret HMAC untuk tanda tangan e-ticket
+}
+
+type PaymentConfig struct {
+	SimulatorMode        string            // mode default simulator: succeed, decline, timeout, async
+	SimulatorMethodModes map[string]string // override mode per payment_methods.code
+	WebhookSecrets       map[string]string // secret HMAC webhook per payment_methods.code
+}
+
+type IdempotencyConfig struct {
+	KeyTTL time.Duration // lama response disimpan untuk di-replay
+}
+
+// AdminConfig adalah akun admin pertama yang dibuat saat startup (kosong = tidak dibuat)
+type AdminConfig struct {
+	Username string
+	Email    string
+	Password string
+}
+
+type ShowtimeConfig struct {
+	CleaningBuffer time.Duration // jeda studio setelah film selesai sebelum showtime berikutnya
+}
+
 // LoadConfig membaca konfigurasi dari file .env
 func LoadConfig() (*Config, error) {
 	viper.SetConfigFile(".env")
@@ -63,6 +98,63 @@ func LoadConfig() (*Config, error) {
 		expiryHours = 24 // default 24 jam
 	}
 
+	paymentWindowMinutes := viper.GetInt("BOOKING_PAYMENT_WINDOW_MINUTES")
+	if paymentWindowMinutes == 0 {
+		paymentWindowMinutes = 15 // default 15 menit
+	}
+
+	cancellationFreeHours := viper.GetInt("CANCELLATION_FREE_HOURS")
+	if cancellationFreeHours == 0 {
+		cancellationFreeHours = 24 // default 24 jam
+	}
+
+	cancellationFeePercent := 25.0 // default potongan 25%
+	if viper.IsSet("CANCELLATION_FEE_PERCENT") {
+		cancellationFeePercent = viper.GetFloat64("CANCELLATION_FEE_PERCENT")
+	}
+
+	salesCutoffMinutes := 15 // default 15 menit setelah film mulai
+	if viper.IsSet("BOOKING_SALES_CUTOFF_MINUTES") {
+		salesCutoffMinutes = viper.GetInt("BOOKING_SALES_CUTOFF_MINUTES")
+	}
+
+	simulatorMode := viper.GetString("PAYMENT_SIMULATOR_MODE")
+	if simulatorMode == "" {
+		simulatorMode = "succeed"
+	}
+
+	// Format: GOPAY:async,OVO:decline
+	// Default: e-wallet dan transfer bank dikonfirmasi lewat webhook
+	methodModes := "GOPAY:async,OVO:async,BANK_TRANSFER:async"
+	if viper.IsSet("PAYMENT_SIMULATOR_METHOD_MODES") {
+		methodModes = viper.GetString("PAYMENT_SIMULATOR_METHOD_MODES")
+	}
+
+	simulatorMethodModes := parseMethodValues(methodModes)
+
+	// Format: GOPAY:secret-gopay,OVO:secret-ovo
+	// Each provider signs its webhooks with its own secret, a leaked secret can only forge that provider
+	webhookSecrets := parseMethodValues(viper.GetString("PAYMENT_WEBHOOK_SECRETS"))
+
+	// E-tickets are signed with their own key, a leaked ticket secret must not be able to forge auth tokens
+	ticketSecret := viper.GetString("TICKET_SECRET")
+	if ticketSecret == "" {
+		return nil, fmt.Errorf("TICKET_SECRET is required")
+	}
+	if ticketSecret == viper.GetString("TOKEN_SECRET") {
+		return nil, fmt.Errorf("TICKET_SECRET must be different from TOKEN_SECRET")
+	}
+
+	idempotencyKeyTTLHours := viper.GetInt("IDEMPOTENCY_KEY_TTL_HOURS")
+	if idempotencyKeyTTLHours == 0 {
+		idempotencyKeyTTLHours = 24 // default 24 jam
+	}
+
+	cleaningBufferMinutes := 15 // default 15 menit
+	if viper.IsSet("SHOWTIME_CLEANING_BUFFER_MINUTES") {
+		cleaningBufferMinutes = viper.GetInt("SHOWTIME_CLEANING_BUFFER_MINUTES")
+	}
+
 	config := &Config{
 		App: AppConfig{
 			Name: viper.GetString("APP_NAME"),
@@ -93,6 +185,29 @@ func LoadConfig() (*Config, error) {
 			Level: viper.GetString("LOG_LEVEL"),
 			File:  viper.GetString("LOG_FILE"),
 		},
+		Booking: BookingConfig{
+			PaymentWindow:          time.Duration(paymentWindowMinutes) * time.Minute,
+			CancellationFreeWindow: time.Duration(cancellationFreeHours) * time.Hour,
+			CancellationFeePercent: cancellationFeePercent,
+			SalesCutoff:            time.Duration(salesCutoffMinutes) * time.Minute,
+			TicketSecret:           ticketSecret,
+		},
+		Payment: PaymentConfig{
+			SimulatorMode:        simulatorMode,
+			SimulatorMethodModes: simulatorMethodModes,
+			WebhookSecrets:       webhookSecrets,
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL: time.Duration(idempotencyKeyTTLHours) * time.Hour,
+		},
+		Admin: AdminConfig{
+			Username: viper.GetString("ADMIN_USERNAME"),
+			Email:    viper.GetString("ADMIN_EMAIL"),
+			Password: viper.GetString("ADMIN_PASSWORD"),
+		},
+		Showtime: ShowtimeConfig{
+			CleaningBuffer: time.Duration(cleaningBufferMinutes) * time.Minute,
+		},
 	}
 
 	return config, nil
@@ -110,3 +225,16 @@ func (c *Config) GetDatabaseDSN() string {
 		c.Database.SSLMode,
 	)
 }
+
+// parseMethodValues membaca daftar "CODE:value" yang dipisah koma menjadi map per payment method code
+func parseMethodValues(value string) map[string]string {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		code, v, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+		values[strings.ToUpper(strings.TrimSpace(code))] = strings.TrimSpace(v)
+	}
+	return values
+}
@@ -7,19 +7,39 @@ import (
 )
 
 type Booking struct {
-	ID          int       `json:"id" db:"id"`
-	UserID      int       `json:"user_id" db:"user_id"`
-	ShowtimeID  int       `json:"showtime_id" db:"showtime_id"`
-	SeatID      int       `json:"seat_id" db:"seat_id"`
-	BookingCode string    `json:"booking_code" db:"booking_code"`
-	Status      string    `json:"status" db:"status"`
-	TotalPrice  float64   `json:"total_price" db:"total_price"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID             int        `json:"id" db:"id"`
+	UserID         int        `json:"user_id" db:"user_id"`
+	ShowtimeID     int        `json:"showtime_id" db:"showtime_id"`
+	BookingCode    string     `json:"booking_code" db:"booking_code"`
+	Status         string     `json:"status" db:"status"`
+	TotalPrice     Money      `json:"total_price" db:"total_price"` // sudah dikurangi discount_amount
+	DiscountAmount Money      `json:"discount_amount" db:"discount_amount"`
+	PromoCode      *string    `json:"promo_code,omitempty" db:"promo_code"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"` // batas waktu bayar untuk booking pending
+	CheckedInAt    *time.Time `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
 	// Relations
-	Showtime *Showtime `json:"showtime,omitempty"`
-	Seat     *Seat     `json:"seat,omitempty"`
-	Payment  *Payment  `json:"payment,omitempty"`
+	Showtime *Showtime      `json:"showtime,omitempty"`
+	Seats    []*BookingSeat `json:"seats,omitempty"`
+	Payment  *Payment       `json:"payment,omitempty"`
+	// Redemption dicatat bersama booking saat Reserve (tidak dikirim ke client)
+	Redemption *PromotionRedemption `json:"-"`
+}
+
+// BookingSeat adalah satu kursi di dalam sebuah booking
+type BookingSeat struct {
+	ID         int        `json:"id" db:"id"`
+	BookingID  int        `json:"booking_id" db:"booking_id"`
+	ShowtimeID int        `json:"showtime_id" db:"showtime_id"`
+	SeatID     int        `json:"seat_id" db:"seat_id"`
+	Price      Money      `json:"price" db:"price"`
+	ReleasedAt *time.Time `json:"released_at,omitempty" db:"released_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	// Rincian harga saat booking dibuat (kosong untuk booking lama)
+	PriceBreakdown *SeatPrice `json:"price_breakdown,omitempty"`
+	// Relations
+	Seat *Seat `json:"seat,omitempty"`
 }
 
 // PaymentDetails adalah custom type untuk handle JSONB
@@ -49,14 +69,17 @@ func (p *PaymentDetails) Scan(value interface{}) error {
 }
 
 type Payment struct {
-	ID              int            `json:"id" db:"id"`
-	BookingID       int            `json:"booking_id" db:"booking_id"`
-	PaymentMethodID int            `json:"payment_method_id" db:"payment_method_id"`
-	Amount          float64        `json:"amount" db:"amount"`
-	Status          string         `json:"status" db:"status"`
-	PaymentDetails  PaymentDetails `json:"payment_details,omitempty" db:"payment_details"`
-	PaidAt          *time.Time     `json:"paid_at" db:"paid_at"`
-	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+	ID                int            `json:"id" db:"id"`
+	BookingID         int            `json:"booking_id" db:"booking_id"`
+	PaymentMethodID   int            `json:"payment_method_id" db:"payment_method_id"`
+	Amount            Money          `json:"amount" db:"amount"`
+	Status            string         `json:"status" db:"status"`
+	PaymentDetails    PaymentDetails `json:"payment_details,omitempty" db:"payment_details"`
+	PaidAt            *time.Time     `json:"paid_at" db:"paid_at"`
+	RefundAmount      *Money         `json:"refund_amount,omitempty" db:"refund_amount"`
+	RefundedAt        *time.Time     `json:"refunded_at,omitempty" db:"refunded_at"`
+	ProviderReference *string        `json:"provider_reference,omitempty" db:"provider_reference"`
+	CreatedAt         time.Time      `json:"created_at" db:"created_at"`
 	// Relations
 	PaymentMethod *PaymentMethod `json:"payment_method,omitempty"`
 }
@@ -71,15 +94,25 @@ type PaymentMethod struct {
 
 // Request DTOs
 type BookingRequest struct {
-	CinemaID      int    `json:"cinema_id" validate:"required"`
-	SeatID        int    `json:"seat_id" validate:"required"`
-	Date          string `json:"date" validate:"required"`
-	Time          string `json:"time" validate:"required"`
+	ShowtimeID int   `json:"showtime_id,omitempty"`
+	SeatIDs    []int `json:"seat_ids" validate:"required,min=1,dive,required"`
+	// Legacy: dipakai untuk mencari showtime jika showtime_id kosong
+	CinemaID      int    `json:"cinema_id,omitempty" validate:"required_without=ShowtimeID"`
+	Date          string `json:"date,omitempty" validate:"required_without=ShowtimeID"`
+	Time          string `json:"time,omitempty" validate:"required_without=ShowtimeID"`
 	PaymentMethod string `json:"payment_method" validate:"required"`
+	PromoCode     string `json:"promo_code,omitempty" validate:"omitempty,max=50"`
 }
 
 type PaymentRequest struct {
 	BookingID      int            `json:"booking_id" validate:"required"`
 	PaymentMethod  string         `json:"payment_method" validate:"required"`
 	PaymentDetails PaymentDetails `json:"payment_details"`
+	PromoCode      string         `json:"promo_code,omitempty" validate:"omitempty,max=50"`
+}
+
+// PaymentWebhook adalah notifikasi status pembayaran dari provider
+type PaymentWebhook struct {
+	Reference string `json:"reference" validate:"required"`
+	Status    string `json:"status" validate:"required,oneof=success failed"`
 }
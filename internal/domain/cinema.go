@@ -1,15 +1,34 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type Cinema struct {
 	ID          int       `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"`
 	Location    string    `json:"location" db:"location"`
 	Description string    `json:"description" db:"description"`
+	Timezone    string    `json:"timezone" db:"timezone"` // zona IANA, mis. Asia/Jakarta (WIB)
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
+// TimeLocation adalah zona waktu lokal cinema
+func (c *Cinema) TimeLocation() *time.Location {
+	return TimezoneLocation(c.Timezone)
+}
+
+// Screen adalah studio/auditorium di dalam cinema
+type Screen struct {
+	ID        int         `json:"id" db:"id"`
+	CinemaID  int         `json:"cinema_id" db:"cinema_id"`
+	Name      string      `json:"name" db:"name"`
+	Layout    *SeatLayout `json:"layout,omitempty" db:"layout"`
+	CreatedAt time.Time   `json:"created_at" db:"created_at"`
+}
+
 type Movie struct {
 	ID          int       `json:"id" db:"id"`
 	Title       string    `json:"title" db:"title"`
@@ -19,32 +38,207 @@ type Movie struct {
 	PosterURL   string    `json:"poster_url" db:"poster_url"`
 	Rating      string    `json:"rating" db:"rating"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	// Relations
+	Showtimes []*Showtime `json:"showtimes,omitempty"`
+}
+
+// MovieFilter berisi filter opsional untuk daftar film
+type MovieFilter struct {
+	Genre  string
+	Rating string
 }
 
 type Showtime struct {
-	ID        int       `json:"id" db:"id"`
-	CinemaID  int       `json:"cinema_id" db:"cinema_id"`
-	MovieID   int       `json:"movie_id" db:"movie_id"`
-	ShowDate  time.Time `json:"show_date" db:"show_date"`
-	ShowTime  time.Time `json:"show_time" db:"show_time"`
-	Price     float64   `json:"price" db:"price"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID                    int       `json:"id" db:"id"`
+	CinemaID              int       `json:"cinema_id" db:"cinema_id"`
+	ScreenID              int       `json:"screen_id" db:"screen_id"`
+	MovieID               int       `json:"movie_id" db:"movie_id"`
+	ShowDate              time.Time `json:"show_date" db:"show_date"`       // tanggal lokal cinema
+	ShowTime              time.Time `json:"show_time" db:"show_time"`       // jam lokal cinema
+	Timezone              string    `json:"timezone"`                       // zona waktu cinema
+	EndsAt                time.Time `json:"ends_at" db:"ends_at"`           // film selesai (mulai + durasi)
+	CleaningBufferMinutes int       `json:"-" db:"cleaning_buffer_minutes"` // jeda bersih-bersih studio setelah film
+	Price                 Money     `json:"price" db:"price"`
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
 	// Relations
 	Cinema *Cinema `json:"cinema,omitempty"`
+	Screen *Screen `json:"screen,omitempty"`
 	Movie  *Movie  `json:"movie,omitempty"`
 }
 
+// TimeLocation adalah zona waktu cinema tempat showtime diputar
+func (s *Showtime) TimeLocation() *time.Location {
+	return TimezoneLocation(s.Timezone)
+}
+
+// StartsAt menggabungkan show_date dan show_time menjadi waktu mulai tayang di zona waktu cinema
+func (s *Showtime) StartsAt() time.Time {
+	return time.Date(
+		s.ShowDate.Year(), s.ShowDate.Month(), s.ShowDate.Day(),
+		s.ShowTime.Hour(), s.ShowTime.Minute(), s.ShowTime.Second(), 0,
+		s.TimeLocation(),
+	)
+}
+
+// SalesCloseAt adalah batas penjualan tiket: waktu mulai tayang ditambah cutoff
+func (s *Showtime) SalesCloseAt(cutoff time.Duration) time.Time {
+	return s.StartsAt().Add(cutoff)
+}
+
+// ScreenFreeAt adalah waktu studio bisa dipakai showtime berikutnya: film selesai ditambah buffer bersih-bersih
+func (s *Showtime) ScreenFreeAt() time.Time {
+	return s.EndsAt.Add(time.Duration(s.CleaningBufferMinutes) * time.Minute)
+}
+
+// Format show_date dan show_time di response
+const (
+	ShowDateLayout = "2006-01-02"
+	ShowTimeLayout = "15:04"
+)
+
+// showtimeJSON adalah Showtime tanpa method MarshalJSON, supaya tidak rekursif
+type showtimeJSON Showtime
+
+// MarshalJSON menulis show_date/show_time sebagai tanggal dan jam lokal cinema (2026-01-11, 19:00)
+// dan menambahkan starts_at (RFC3339 dengan offset zona cinema, mis. 2026-01-11T19:00:00+08:00)
+func (s Showtime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		showtimeJSON
+		ShowDate string    `json:"show_date"`
+		ShowTime string    `json:"show_time"`
+		StartsAt time.Time `json:"starts_at"`
+	}{showtimeJSON(s), s.ShowDate.Format(ShowDateLayout), s.ShowTime.Format(ShowTimeLayout), s.StartsAt()})
+}
+
+// ShowtimeAvailability adalah showtime beserta jumlah kursi yang masih tersedia
+type ShowtimeAvailability struct {
+	*Showtime
+	TotalSeats     int `json:"total_seats"`
+	AvailableSeats int `json:"available_seats"`
+}
+
+// MarshalJSON menulis field showtime beserta jumlah kursi; tanpa ini MarshalJSON Showtime
+// yang ter-embed akan dipakai dan jumlah kursi hilang dari response
+func (a ShowtimeAvailability) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		showtimeJSON
+		ShowDate       string    `json:"show_date"`
+		ShowTime       string    `json:"show_time"`
+		StartsAt       time.Time `json:"starts_at"`
+		TotalSeats     int       `json:"total_seats"`
+		AvailableSeats int       `json:"available_seats"`
+	}{
+		showtimeJSON(*a.Showtime),
+		a.ShowDate.Format(ShowDateLayout),
+		a.ShowTime.Format(ShowTimeLayout),
+		a.StartsAt(),
+		a.TotalSeats,
+		a.AvailableSeats,
+	})
+}
+
 type Seat struct {
-	ID         int       `json:"id" db:"id"`
-	CinemaID   int       `json:"cinema_id" db:"cinema_id"`
-	SeatRow    string    `json:"seat_row" db:"seat_row"`
-	SeatNumber int       `json:"seat_number" db:"seat_number"`
-	SeatType   string    `json:"seat_type" db:"seat_type"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ID           int       `json:"id" db:"id"`
+	CinemaID     int       `json:"cinema_id" db:"cinema_id"`
+	ScreenID     int       `json:"screen_id" db:"screen_id"`
+	SeatRow      string    `json:"seat_row" db:"seat_row"`
+	SeatNumber   int       `json:"seat_number" db:"seat_number"`
+	SeatType     string    `json:"seat_type" db:"seat_type"`
+	GridX        *int      `json:"x,omitempty" db:"grid_x"`
+	GridY        *int      `json:"y,omitempty" db:"grid_y"`
+	IsAccessible bool      `json:"is_accessible" db:"is_accessible"`
+	IsBlocked    bool      `json:"is_blocked" db:"is_blocked"`
+	PairSeatID   *int      `json:"pair_seat_id,omitempty" db:"pair_seat_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Label mengembalikan nama kursi, mis. A1
+func (s *Seat) Label() string {
+	return fmt.Sprintf("%s%d", s.SeatRow, s.SeatNumber)
 }
 
 type SeatAvailability struct {
-	Seat       *Seat `json:"seat"`
-	IsBooked   bool  `json:"is_booked"`
-	ShowtimeID int   `json:"showtime_id"`
+	Seat       *Seat      `json:"seat"`
+	IsBooked   bool       `json:"is_booked"`
+	ShowtimeID int        `json:"showtime_id"`
+	Price      *SeatPrice `json:"price,omitempty"`
+}
+
+// Admin request DTOs
+
+// CinemaRequest adalah body untuk membuat atau mengubah cinema
+type CinemaRequest struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Location    string `json:"location" validate:"required,max=255"`
+	Description string `json:"description"`
+	Timezone    string `json:"timezone" validate:"omitempty,oneof=Asia/Jakarta Asia/Makassar Asia/Jayapura"` // default Asia/Jakarta (WIB)
+}
+
+// ScreenRequest adalah body untuk membuat studio baru di cinema
+type ScreenRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// MovieRequest adalah body untuk membuat atau mengubah film
+type MovieRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Duration    int    `json:"duration" validate:"required,min=1,max=600"` // dalam menit
+	Genre       string `json:"genre" validate:"max=100"`
+	PosterURL   string `json:"poster_url" validate:"omitempty,url,max=500"`
+	Rating      string `json:"rating" validate:"max=10"`
+}
+
+// ShowtimeRequest adalah body untuk membuat atau mengubah showtime; cinema diambil dari studio
+type ShowtimeRequest struct {
+	ScreenID int    `json:"screen_id" validate:"required"`
+	MovieID  int    `json:"movie_id" validate:"required"`
+	Date     string `json:"date" validate:"required"` // YYYY-MM-DD
+	Time     string `json:"time" validate:"required"` // HH:MM atau HH:MM:SS
+	Price    Money  `json:"price" validate:"gt=0"`
+}
+
+// ScheduleRequest adalah pola jadwal berulang, mis. film 2 di studio 1 setiap hari jam 13:00 dan 19:00
+// dari 2026-11-01 sampai 2026-11-30. DaysOfWeek kosong berarti setiap hari.
+type ScheduleRequest struct {
+	ScreenID   int      `json:"screen_id" validate:"required"`
+	MovieID    int      `json:"movie_id" validate:"required"`
+	From       string   `json:"from" validate:"required"`                      // YYYY-MM-DD
+	To         string   `json:"to" validate:"required"`                        // YYYY-MM-DD, inklusif
+	Times      []string `json:"times" validate:"required,min=1,dive,required"` // HH:MM
+	DaysOfWeek []int    `json:"days_of_week" validate:"dive,min=0,max=6"`      // 0 = Minggu
+	Price      Money    `json:"price" validate:"gt=0"`
+}
+
+// ScheduleSkip adalah slot jadwal yang tidak dibuat beserta alasannya
+type ScheduleSkip struct {
+	Date   string `json:"date"`
+	Time   string `json:"time"`
+	Reason string `json:"reason"`
+}
+
+// ScheduleResult adalah hasil GenerateSchedule: showtime yang dibuat dan slot yang dilewati
+type ScheduleResult struct {
+	Created []*Showtime    `json:"created"`
+	Skipped []ScheduleSkip `json:"skipped"`
+}
+
+// SeatRequest adalah body untuk menambah satu kursi ke studio
+type SeatRequest struct {
+	Row        string `json:"row" validate:"required,max=2"`
+	Number     int    `json:"number" validate:"required,min=1"`
+	Type       string `json:"type,omitempty" validate:"omitempty,oneof=regular vip premium"`
+	X          *int   `json:"x,omitempty" validate:"required_with=Y,omitempty,min=0"`
+	Y          *int   `json:"y,omitempty" validate:"required_with=X,omitempty,min=0"`
+	Accessible bool   `json:"accessible,omitempty"`
+	Blocked    bool   `json:"blocked,omitempty"`
+}
+
+// SeatUpdateRequest adalah body untuk mengubah kursi; baris dan nomor kursi tetap
+type SeatUpdateRequest struct {
+	Type       string `json:"type" validate:"required,oneof=regular vip premium"`
+	X          *int   `json:"x,omitempty" validate:"required_with=Y,omitempty,min=0"`
+	Y          *int   `json:"y,omitempty" validate:"required_with=X,omitempty,min=0"`
+	Accessible bool   `json:"accessible"`
+	Blocked    bool   `json:"blocked"`
 }
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowtime_StartsAt_CinemaTimezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		want     string
+	}{
+		{TimezoneWIB, "2026-01-11T19:00:00+07:00"},
+		{TimezoneWITA, "2026-01-11T19:00:00+08:00"},
+		{TimezoneWIT, "2026-01-11T19:00:00+09:00"},
+		{"", "2026-01-11T19:00:00+07:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			showtime := &Showtime{
+				ShowDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+				ShowTime: time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+				Timezone: tt.timezone,
+			}
+
+			assert.Equal(t, tt.want, showtime.StartsAt().Format(time.RFC3339))
+		})
+	}
+}
+
+func TestShowtimeAvailability_MarshalJSON(t *testing.T) {
+	availability := &ShowtimeAvailability{
+		Showtime: &Showtime{
+			ID:       1,
+			ShowDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+			ShowTime: time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+			Timezone: TimezoneWITA,
+		},
+		TotalSeats:     50,
+		AvailableSeats: 48,
+	}
+
+	data, err := json.Marshal(availability)
+	require.NoError(t, err)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, "2026-01-11T19:00:00+08:00", body["starts_at"])
+	assert.Equal(t, "2026-01-11", body["show_date"])
+	assert.Equal(t, "19:00", body["show_time"])
+	assert.Equal(t, TimezoneWITA, body["timezone"])
+	assert.EqualValues(t, 1, body["id"])
+	assert.EqualValues(t, 48, body["available_seats"])
+}
+
+func TestShowtime_MarshalJSON_LocalDateAndTime(t *testing.T) {
+	showtime := Showtime{
+		ID:       1,
+		ShowDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+		ShowTime: time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+		Timezone: TimezoneWIT,
+	}
+
+	data, err := json.Marshal(showtime)
+	require.NoError(t, err)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, "2026-01-11", body["show_date"])
+	assert.Equal(t, "19:30", body["show_time"])
+	assert.Equal(t, "2026-01-11T19:30:00+09:00", body["starts_at"])
+}
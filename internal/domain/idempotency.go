@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+type IdempotencyKey struct {
+	ID           int        `json:"id" db:"id"`
+	UserID       int        `json:"user_id" db:"user_id"`
+	Key          string     `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash  string     `json:"request_hash" db:"request_hash"`
+	StatusCode   int        `json:"status_code" db:"status_code"`
+	ResponseBody []byte     `json:"-" db:"response_body"`
+	CompletedAt  *time.Time `json:"completed_at" db:"completed_at"`
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsCompleted mengecek apakah response untuk key ini sudah tersimpan
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.CompletedAt != nil
+}
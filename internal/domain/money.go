@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Currency adalah mata uang semua nominal Money
+const Currency = "IDR"
+
+// moneyScale adalah jumlah sen dalam satu rupiah, sama dengan presisi DECIMAL(10,2)
+const moneyScale = 100
+
+// Money adalah nominal rupiah dalam satuan sen (integer minor unit).
+// Penjumlahan dan pengurangan Money selalu exact; perkalian dengan rate
+// atau persen dibulatkan ke sen terdekat (half away from zero).
+type Money int64
+
+// NewMoney membuat Money dari nominal rupiah utuh
+func NewMoney(rupiah int64) Money {
+	return Money(rupiah * moneyScale)
+}
+
+// MoneyFromFloat membuat Money dari float, dibulatkan ke sen terdekat
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * moneyScale))
+}
+
+// ParseMoney membaca nominal desimal seperti "50000" atau "33333.33".
+// Lebih dari dua angka di belakang koma ditolak supaya tidak ada pembulatan diam-diam.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	digits := strings.TrimPrefix(s, "-")
+
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+	if whole == "" || !isDigits(whole) || (hasFrac && (frac == "" || !isDigits(frac))) {
+		return 0, fmt.Errorf("invalid money amount %q", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("invalid money amount %q: more than 2 decimal places", s)
+	}
+
+	// Pad the fraction to exactly two digits, "5" means 50 sen
+	frac += strings.Repeat("0", 2-len(frac))
+	sen, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount %q: out of range", s)
+	}
+	if len(digits) < len(s) {
+		sen = -sen
+	}
+
+	return Money(sen), nil
+}
+
+// Rupiah mengembalikan nominal rupiah utuh (sen dibuang)
+func (m Money) Rupiah() int64 {
+	return int64(m) / moneyScale
+}
+
+// Float64 hanya untuk tampilan atau integrasi yang butuh float, jangan dipakai untuk hitungan
+func (m Money) Float64() float64 {
+	return float64(m) / moneyScale
+}
+
+// String mengembalikan nominal dengan dua angka desimal, mis. "50000.00"
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/moneyScale, v%moneyScale)
+}
+
+// MulRate mengalikan nominal dengan rate desimal (mis. multiplier harga 1.25)
+func (m Money) MulRate(rate float64) Money {
+	r := decimalRat(rate)
+	r.Mul(r, new(big.Rat).SetInt64(int64(m)))
+	return roundRat(r)
+}
+
+// Percent menghitung percent persen dari nominal, mis. Percent(25) = 25%
+func (m Money) Percent(percent float64) Money {
+	r := decimalRat(percent)
+	r.Mul(r, big.NewRat(int64(m), 100))
+	return roundRat(r)
+}
+
+// Min mengembalikan nominal yang lebih kecil
+func (m Money) Min(other Money) Money {
+	if other < m {
+		return other
+	}
+	return m
+}
+
+// decimalRat memakai bentuk desimal terpendek dari f, jadi 1.15 berarti tepat 115/100
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return r
+}
+
+// roundRat membulatkan r ke integer terdekat, half away from zero
+func roundRat(r *big.Rat) Money {
+	return Money(roundRatInt(r).Int64())
+}
+
+func roundRatInt(r *big.Rat) *big.Int {
+	num, den := new(big.Int).Abs(r.Num()), r.Denom()
+
+	// floor((2*num + den) / (2*den)) rounds half up on the absolute value
+	num.Mul(num, big.NewInt(2)).Add(num, den)
+	num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+	if r.Sign() < 0 {
+		num.Neg(num)
+	}
+
+	return num
+}
+
+// MarshalJSON menulis nominal sebagai angka JSON dengan dua desimal
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON menerima angka atau string angka, mis. 50000, 50000.5, atau "50000.50"
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	parsed, err := ParseMoney(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner untuk kolom DECIMAL/NUMERIC
+func (m *Money) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		return fmt.Errorf("cannot scan NULL into *domain.Money")
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return fmt.Errorf("cannot scan non-finite numeric into *domain.Money")
+	}
+
+	// value = Int * 10^Exp, in sen that is Int * 10^(Exp+2)
+	r := new(big.Rat).SetInt(n.Int)
+	exp := int64(n.Exp) + 2
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(abs64(exp)), nil)
+	if exp >= 0 {
+		r.Mul(r, new(big.Rat).SetInt(scale))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(scale))
+	}
+
+	sen := roundRatInt(r)
+	if !sen.IsInt64() {
+		return fmt.Errorf("numeric value out of range for domain.Money")
+	}
+
+	*m = Money(sen.Int64())
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -2, Valid: true}, nil
+}
+
+// Scan implements sql.Scanner interface
+func (m *Money) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return fmt.Errorf("cannot scan NULL into *domain.Money")
+	case Money:
+		*m = v
+	case int64:
+		*m = NewMoney(v)
+	case float64:
+		*m = MoneyFromFloat(v)
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into *domain.Money", value)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
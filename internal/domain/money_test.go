@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"50000", 5000000},
+		{"50000.5", 5000050},
+		{"33333.33", 3333333},
+		{"0.07", 7},
+		{"-1500.25", -150025},
+		{" 100 ", 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseMoney(tt.in)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseMoney_Invalid(t *testing.T) {
+	for _, in := range []string{"", "abc", "1.234", "1e3", "1.", ".5", "1/2", "--1", "99999999999999999999"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := ParseMoney(in)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "50000.00", NewMoney(50000).String())
+	assert.Equal(t, "33333.33", Money(3333333).String())
+	assert.Equal(t, "0.05", Money(5).String())
+	assert.Equal(t, "-12.30", Money(-1230).String())
+}
+
+func TestMoney_MulRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		rate  float64
+		want  Money
+	}{
+		{"whole result", NewMoney(50000), 1.25, NewMoney(62500)},
+		// 33333.33 x 1.15 = 38333.3295
+		{"rounded to the nearest sen", Money(3333333), 1.15, Money(3833333)},
+		{"half sen with decimal rate", Money(1), 1.5, Money(2)},
+		{"half sen rounds up", Money(1), 0.5, Money(1)},
+		{"half sen rounds away from zero", Money(-1), 0.5, Money(-1)},
+		{"below half rounds down", Money(3), 0.1, Money(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.MulRate(tt.rate))
+		})
+	}
+}
+
+func TestMoney_Percent(t *testing.T) {
+	tests := []struct {
+		name    string
+		money   Money
+		percent float64
+		want    Money
+	}{
+		{"cancellation fee", NewMoney(100000), 25, NewMoney(25000)},
+		{"rounded once", Money(3333333), 20, Money(666667)},
+		// 10.05 x 5% = 0.5025
+		{"rounded to the nearest sen", Money(1005), 5, Money(50)},
+		// 0.10 x 15% = 0.015
+		{"half sen with fractional result", Money(10), 15, Money(2)},
+		{"half sen", Money(10), 5, Money(1)},
+		{"fractional percent", NewMoney(45000), 12.5, NewMoney(5625)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.money.Percent(tt.percent))
+		})
+	}
+}
+
+func TestMoney_SumIsExact(t *testing.T) {
+	// 0.1 + 0.2 != 0.3 in float64
+	var total Money
+	for _, price := range []string{"0.10", "0.20"} {
+		m, err := ParseMoney(price)
+		require.NoError(t, err)
+		total += m
+	}
+
+	assert.Equal(t, "0.30", total.String())
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Price  Money  `json:"price"`
+		Refund *Money `json:"refund,omitempty"`
+	}{Price: Money(3333333)})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price": 33333.33}`, string(data))
+
+	var decoded struct {
+		A Money `json:"a"`
+		B Money `json:"b"`
+		C Money `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a": 50000, "b": 12.5, "c": "99.99"}`), &decoded))
+	assert.Equal(t, NewMoney(50000), decoded.A)
+	assert.Equal(t, Money(1250), decoded.B)
+	assert.Equal(t, Money(9999), decoded.C)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"a": 0.001}`), &decoded))
+}
+
+func TestMoney_ScanNumeric(t *testing.T) {
+	m := pgtype.NewMap()
+
+	tests := []struct {
+		text string
+		want Money
+	}{
+		{"50000.00", NewMoney(50000)},
+		{"33333.33", Money(3333333)},
+		{"125", NewMoney(125)},
+		// More precision than DECIMAL(10,2), e.g. from an expression: rounded to the nearest sen
+		{"10.005", Money(1001)},
+		{"-10.005", Money(-1001)},
+		{"10.0049", Money(1000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got Money
+			err := m.Scan(pgtype.NumericOID, pgtype.TextFormatCode, []byte(tt.text), &got)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMoney_ScanNumeric_Null(t *testing.T) {
+	m := pgtype.NewMap()
+
+	var money Money
+	assert.Error(t, m.Scan(pgtype.NumericOID, pgtype.TextFormatCode, nil, &money))
+
+	var nullable *Money
+	require.NoError(t, m.Scan(pgtype.NumericOID, pgtype.TextFormatCode, nil, &nullable))
+	assert.Nil(t, nullable)
+}
+
+func TestMoney_NumericRoundTrip(t *testing.T) {
+	m := pgtype.NewMap()
+
+	for _, want := range []Money{0, 5, Money(3333333), NewMoney(50000), Money(-150025)} {
+		buf, err := m.Encode(pgtype.NumericOID, pgtype.BinaryFormatCode, want, nil)
+		require.NoError(t, err)
+
+		var got Money
+		require.NoError(t, m.Scan(pgtype.NumericOID, pgtype.BinaryFormatCode, buf, &got))
+		assert.Equal(t, want, got)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// SeatPriceRule menentukan harga kursi berdasarkan tipe kursi,
+// opsional dibatasi per cinema/showtime dan per hari/jam tayang
+type SeatPriceRule struct {
+	ID         int       `json:"id" db:"id"`
+	CinemaID   *int      `json:"cinema_id,omitempty" db:"cinema_id"`
+	ShowtimeID *int      `json:"showtime_id,omitempty" db:"showtime_id"`
+	SeatType   string    `json:"seat_type" db:"seat_type"`
+	Multiplier *float64  `json:"multiplier,omitempty" db:"multiplier"`
+	Price      *Money    `json:"price,omitempty" db:"price"`
+	DaysOfWeek []int     `json:"days_of_week,omitempty" db:"days_of_week"` // 0 = Minggu
+	StartTime  *string   `json:"start_time,omitempty" db:"start_time"`     // HH:MM
+	EndTime    *string   `json:"end_time,omitempty" db:"end_time"`         // HH:MM
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// SeatPrice adalah rincian harga satu kursi untuk sebuah showtime
+type SeatPrice struct {
+	SeatType   string   `json:"seat_type"`
+	BasePrice  Money    `json:"base_price"` // harga dasar showtime
+	Multiplier *float64 `json:"multiplier,omitempty"`
+	RuleID     *int     `json:"rule_id,omitempty"`
+	Price      Money    `json:"price"`
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Tipe diskon promo
+const (
+	DiscountTypePercent = "percent"
+	DiscountTypeFixed   = "fixed"
+)
+
+// Promotion adalah promo code dengan potongan persen atau nominal tetap
+type Promotion struct {
+	ID              int       `json:"id" db:"id"`
+	Code            string    `json:"code" db:"code"`
+	Description     string    `json:"description" db:"description"`
+	DiscountType    string    `json:"discount_type" db:"discount_type"`
+	DiscountPercent float64   `json:"discount_percent,omitempty" db:"discount_percent"` // untuk tipe percent
+	DiscountAmount  Money     `json:"discount_amount,omitempty" db:"discount_amount"`   // untuk tipe fixed
+	MaxDiscount     *Money    `json:"max_discount,omitempty" db:"max_discount"`
+	ValidFrom       time.Time `json:"valid_from" db:"valid_from"`
+	ValidUntil      time.Time `json:"valid_until" db:"valid_until"`
+	MaxUses         *int      `json:"max_uses,omitempty" db:"max_uses"`
+	MaxUsesPerUser  *int      `json:"max_uses_per_user,omitempty" db:"max_uses_per_user"`
+	MovieIDs        []int     `json:"movie_ids,omitempty" db:"movie_ids"`
+	CinemaIDs       []int     `json:"cinema_ids,omitempty" db:"cinema_ids"`
+	PaymentMethods  []string  `json:"payment_methods,omitempty" db:"payment_methods"`
+	IsActive        bool      `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsValidAt memeriksa promo aktif dan berada di masa berlaku
+func (p *Promotion) IsValidAt(t time.Time) bool {
+	return p.IsActive && !t.Before(p.ValidFrom) && t.Before(p.ValidUntil)
+}
+
+// AppliesTo memeriksa batasan film dan cinema promo untuk showtime
+func (p *Promotion) AppliesTo(showtime *Showtime) bool {
+	return containsInt(p.MovieIDs, showtime.MovieID) && containsInt(p.CinemaIDs, showtime.CinemaID)
+}
+
+// AllowsPaymentMethod memeriksa batasan payment method promo
+func (p *Promotion) AllowsPaymentMethod(code string) bool {
+	if len(p.PaymentMethods) == 0 {
+		return true
+	}
+	for _, allowed := range p.PaymentMethods {
+		if strings.EqualFold(allowed, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Discount menghitung potongan untuk subtotal, tidak pernah melebihi subtotal
+func (p *Promotion) Discount(subtotal Money) Money {
+	if p.DiscountType != DiscountTypePercent {
+		return p.DiscountAmount.Min(subtotal)
+	}
+
+	discount := subtotal.Percent(p.DiscountPercent)
+	if p.MaxDiscount != nil {
+		discount = discount.Min(*p.MaxDiscount)
+	}
+	return discount.Min(subtotal)
+}
+
+// PromotionRedemption adalah satu pemakaian promo oleh sebuah booking
+type PromotionRedemption struct {
+	ID             int        `json:"id" db:"id"`
+	PromotionID    int        `json:"promotion_id" db:"promotion_id"`
+	UserID         int        `json:"user_id" db:"user_id"`
+	BookingID      int        `json:"booking_id" db:"booking_id"`
+	DiscountAmount Money      `json:"discount_amount" db:"discount_amount"`
+	ReleasedAt     *time.Time `json:"released_at,omitempty" db:"released_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	// Relations
+	Promotion *Promotion `json:"promotion,omitempty"`
+}
+
+// containsInt: daftar kosong berarti tidak dibatasi
+func containsInt(list []int, v int) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "fmt"
+
+// SeatLayout adalah denah kursi studio yang diupload admin.
+// Grid (ukuran, lorong, sel kosong) disimpan di screens.layout, kursi di tabel seats.
+type SeatLayout struct {
+	Rows         int               `json:"rows" validate:"required,min=1,max=100"`
+	Columns      int               `json:"columns" validate:"required,min=1,max=100"`
+	Aisles       SeatLayoutAisles  `json:"aisles"`
+	BlockedCells []SeatLayoutCell  `json:"blocked_cells,omitempty" validate:"dive"`
+	Seats        []*SeatLayoutSeat `json:"seats,omitempty" validate:"required,min=1,max=2000,dive"`
+}
+
+// SeatLayoutAisles berisi index baris dan kolom grid yang menjadi lorong
+type SeatLayoutAisles struct {
+	Rows    []int `json:"rows,omitempty" validate:"dive,min=0"`
+	Columns []int `json:"columns,omitempty" validate:"dive,min=0"`
+}
+
+// SeatLayoutCell adalah satu sel grid (0-based)
+type SeatLayoutCell struct {
+	X int `json:"x" validate:"min=0"`
+	Y int `json:"y" validate:"min=0"`
+}
+
+// SeatLayoutSeat adalah satu kursi di denah
+type SeatLayoutSeat struct {
+	Row        string `json:"row" validate:"required,max=2"`
+	Number     int    `json:"number" validate:"required,min=1"`
+	X          int    `json:"x" validate:"min=0"`
+	Y          int    `json:"y" validate:"min=0"`
+	Type       string `json:"type,omitempty" validate:"omitempty,oneof=regular vip premium"`
+	Accessible bool   `json:"accessible"`
+	Blocked    bool   `json:"blocked"`
+	Pair       string `json:"pair,omitempty" validate:"omitempty,max=10"` // label kursi pasangan (couple seat), mis. A2
+}
+
+// Label mengembalikan nama kursi, mis. A1
+func (s *SeatLayoutSeat) Label() string {
+	return fmt.Sprintf("%s%d", s.Row, s.Number)
+}
+
+// Grid mengembalikan layout tanpa daftar kursi, untuk disimpan di screens.layout
+func (l *SeatLayout) Grid() *SeatLayout {
+	grid := *l
+	grid.Seats = nil
+	return &grid
+}
@@ -0,0 +1,13 @@
+package domain
+
+// TicketClaims adalah isi e-ticket yang ditandatangani dan di-encode ke QR code
+type TicketClaims struct {
+	BookingCode string
+	ShowtimeID  int
+	Seats       []string // label kursi, mis. A1
+}
+
+type CheckInRequest struct {
+	Payload    string `json:"payload" validate:"required"`
+	ShowtimeID int    `json:"showtime_id" validate:"required"`
+}
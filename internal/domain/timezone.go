@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata" // data zona waktu ikut di-embed, tidak bergantung pada tzdata di host
+)
+
+// Zona waktu cinema di Indonesia
+const (
+	TimezoneWIB  = "Asia/Jakarta"
+	TimezoneWITA = "Asia/Makassar"
+	TimezoneWIT  = "Asia/Jayapura"
+
+	// DefaultTimezone dipakai untuk cinema tanpa zona dan jadwal lintas cinema
+	DefaultTimezone = TimezoneWIB
+)
+
+// timezones menyimpan *time.Location yang sudah dimuat, per nama zona
+var timezones sync.Map
+
+// LoadTimezone memuat zona waktu IANA, mis. Asia/Makassar. Nama kosong berarti DefaultTimezone.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimezone
+	}
+
+	if loc, ok := timezones.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+
+	timezones.Store(name, loc)
+	return loc, nil
+}
+
+// TimezoneLocation sama seperti LoadTimezone, tapi kembali ke DefaultTimezone jika nama zona tidak dikenal
+func TimezoneLocation(name string) *time.Location {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		loc, _ = LoadTimezone(DefaultTimezone)
+	}
+	return loc
+}
@@ -0,0 +1,84 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+)
+
+// Status transaksi di sisi provider
+const (
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusPending    = "pending"
+	StatusDeclined   = "declined"
+	StatusRefunded   = "refunded"
+)
+
+var (
+	// ErrDeclined dikembalikan ketika provider menolak transaksi
+	ErrDeclined = errors.New("payment declined by provider")
+	// ErrTimeout dikembalikan ketika provider tidak merespon tepat waktu
+	ErrTimeout = errors.New("payment provider timed out")
+	// ErrTransactionNotFound dikembalikan ketika reference tidak dikenal provider
+	ErrTransactionNotFound = errors.New("transaction not found")
+)
+
+// AuthorizeRequest berisi data yang dikirim ke provider saat otorisasi
+type AuthorizeRequest struct {
+	BookingID     int
+	BookingCode   string
+	Amount        domain.Money
+	PaymentMethod string
+	Details       map[string]interface{}
+}
+
+// Result adalah status transaksi yang dilaporkan provider
+type Result struct {
+	Reference string
+	Status    string
+	Amount    domain.Money
+}
+
+// PaymentGateway adalah kontrak untuk setiap payment provider
+type PaymentGateway interface {
+	Authorize(ctx context.Context, req *AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, reference string, amount domain.Money) (*Result, error)
+	Refund(ctx context.Context, reference string, amount domain.Money) (*Result, error)
+	Status(ctx context.Context, reference string) (*Result, error)
+}
+
+// Registry memilih gateway berdasarkan payment_methods.code
+type Registry struct {
+	mu       sync.RWMutex
+	fallback PaymentGateway
+	gateways map[string]PaymentGateway
+}
+
+// NewRegistry membuat registry dengan gateway default untuk method yang tidak didaftarkan
+func NewRegistry(fallback PaymentGateway) *Registry {
+	return &Registry{
+		fallback: fallback,
+		gateways: make(map[string]PaymentGateway),
+	}
+}
+
+// Register mendaftarkan gateway untuk payment method tertentu
+func (r *Registry) Register(methodCode string, gw PaymentGateway) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gateways[strings.ToUpper(methodCode)] = gw
+}
+
+// Get mengambil gateway untuk payment method, atau gateway default
+func (r *Registry) Get(methodCode string) PaymentGateway {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if gw, ok := r.gateways[strings.ToUpper(methodCode)]; ok {
+		return gw
+	}
+	return r.fallback
+}
@@ -0,0 +1,176 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+)
+
+// Mode perilaku simulator
+const (
+	ModeSucceed = "succeed"
+	ModeDecline = "decline"
+	ModeTimeout = "timeout"
+	ModeAsync   = "async"
+)
+
+// Simulator adalah gateway in-process yang deterministik untuk development dan test.
+// Hasil setiap transaksi hanya ditentukan oleh mode, tanpa random dan tanpa network.
+type Simulator struct {
+	name string
+	mode string
+
+	mu           sync.Mutex
+	sequence     int
+	transactions map[string]*Result
+}
+
+// NewSimulator membuat simulator dengan mode tertentu (succeed, decline, timeout, async)
+func NewSimulator(name, mode string) (*Simulator, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	if mode == "" {
+		mode = ModeSucceed
+	}
+
+	switch mode {
+	case ModeSucceed, ModeDecline, ModeTimeout, ModeAsync:
+	default:
+		return nil, fmt.Errorf("unknown simulator mode: %s", mode)
+	}
+
+	return &Simulator{
+		name:         strings.ToUpper(name),
+		mode:         mode,
+		transactions: make(map[string]*Result),
+	}, nil
+}
+
+// Mode mengembalikan mode simulator
+func (s *Simulator) Mode() string {
+	return s.mode
+}
+
+// Authorize mengotorisasi transaksi sesuai mode simulator
+func (s *Simulator) Authorize(ctx context.Context, req *AuthorizeRequest) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, ErrTimeout
+	}
+
+	switch s.mode {
+	case ModeDecline:
+		return nil, ErrDeclined
+	case ModeTimeout:
+		return nil, ErrTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sequence++
+	result := &Result{
+		Reference: fmt.Sprintf("SIM-%s-%d-%d", s.name, req.BookingID, s.sequence),
+		Status:    StatusAuthorized,
+		Amount:    req.Amount,
+	}
+	if s.mode == ModeAsync {
+		result.Status = StatusPending
+	}
+
+	s.transactions[result.Reference] = result
+
+	return copyResult(result), nil
+}
+
+// Capture menyelesaikan transaksi yang sudah diotorisasi atau transaksi async yang sudah dibayar.
+// Capture ulang transaksi yang sudah di-capture tidak mengubah apa-apa.
+func (s *Simulator) Capture(ctx context.Context, reference string, amount domain.Money) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.transactions[reference]
+	if !ok {
+		// Transaksi dari proses sebelumnya (simulator tidak persisten), anggap sudah dibayar
+		tx = &Result{Reference: reference, Status: StatusPending, Amount: amount}
+		s.transactions[reference] = tx
+	}
+
+	switch tx.Status {
+	case StatusCaptured:
+		return copyResult(tx), nil
+	case StatusAuthorized, StatusPending:
+	default:
+		return nil, fmt.Errorf("cannot capture transaction in status %s", tx.Status)
+	}
+
+	tx.Status = StatusCaptured
+	tx.Amount = amount
+
+	return copyResult(tx), nil
+}
+
+// Refund mengembalikan dana transaksi yang sudah di-capture. Refund ulang tidak mengubah apa-apa.
+func (s *Simulator) Refund(ctx context.Context, reference string, amount domain.Money) (*Result, error) {
+	if s.mode == ModeTimeout {
+		return nil, ErrTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.transactions[reference]
+	if !ok {
+		// Transaksi dari proses sebelumnya (simulator tidak persisten), anggap berhasil
+		return &Result{Reference: reference, Status: StatusRefunded, Amount: amount}, nil
+	}
+	if tx.Status == StatusRefunded {
+		return copyResult(tx), nil
+	}
+	if tx.Status != StatusCaptured {
+		return nil, fmt.Errorf("cannot refund transaction in status %s", tx.Status)
+	}
+
+	tx.Status = StatusRefunded
+	tx.Amount = amount
+
+	return copyResult(tx), nil
+}
+
+// Status mengambil status transaksi
+func (s *Simulator) Status(ctx context.Context, reference string) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.transactions[reference]
+	if !ok {
+		return nil, ErrTransactionNotFound
+	}
+
+	return copyResult(tx), nil
+}
+
+func copyResult(r *Result) *Result {
+	c := *r
+	return &c
+}
+
+// NewSimulatorRegistry membuat registry berisi simulator default dan simulator per payment method
+func NewSimulatorRegistry(defaultMode string, methodModes map[string]string) (*Registry, error) {
+	fallback, err := NewSimulator("default", defaultMode)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewRegistry(fallback)
+	for code, mode := range methodModes {
+		sim, err := NewSimulator(code, mode)
+		if err != nil {
+			return nil, fmt.Errorf("payment method %s: %w", code, err)
+		}
+		registry.Register(code, sim)
+	}
+
+	return registry, nil
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulator_Succeed(t *testing.T) {
+	sim, err := NewSimulator("gopay", ModeSucceed)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	auth, err := sim.Authorize(ctx, &AuthorizeRequest{BookingID: 7, Amount: domain.NewMoney(50000)})
+	require.NoError(t, err)
+	assert.Equal(t, "SIM-GOPAY-7-1", auth.Reference)
+	assert.Equal(t, StatusAuthorized, auth.Status)
+
+	captured, err := sim.Capture(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusCaptured, captured.Status)
+
+	refunded, err := sim.Refund(ctx, auth.Reference, domain.NewMoney(25000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+	assert.Equal(t, domain.NewMoney(25000), refunded.Amount)
+
+	status, err := sim.Status(ctx, auth.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRefunded, status.Status)
+}
+
+func TestSimulator_Decline(t *testing.T) {
+	sim, err := NewSimulator("ovo", ModeDecline)
+	require.NoError(t, err)
+
+	result, err := sim.Authorize(context.Background(), &AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(50000)})
+
+	assert.ErrorIs(t, err, ErrDeclined)
+	assert.Nil(t, result)
+}
+
+func TestSimulator_Timeout(t *testing.T) {
+	sim, err := NewSimulator("dana", ModeTimeout)
+	require.NoError(t, err)
+
+	result, err := sim.Authorize(context.Background(), &AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(50000)})
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Nil(t, result)
+}
+
+func TestSimulator_Async(t *testing.T) {
+	sim, err := NewSimulator("bank_transfer", ModeAsync)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	auth, err := sim.Authorize(ctx, &AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(50000)})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, auth.Status)
+
+	status, err := sim.Status(ctx, auth.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, status.Status)
+
+	// Settled once the provider reports success, then refundable
+	captured, err := sim.Capture(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusCaptured, captured.Status)
+
+	refunded, err := sim.Refund(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+}
+
+func TestSimulator_CaptureAndRefundAreIdempotent(t *testing.T) {
+	sim, err := NewSimulator("gopay", ModeAsync)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	auth, err := sim.Authorize(ctx, &AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(50000)})
+	require.NoError(t, err)
+
+	_, err = sim.Capture(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	again, err := sim.Capture(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusCaptured, again.Status)
+
+	_, err = sim.Refund(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	again, err = sim.Refund(ctx, auth.Reference, domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusRefunded, again.Status)
+
+	_, err = sim.Capture(ctx, auth.Reference, domain.NewMoney(50000))
+	assert.Error(t, err)
+}
+
+func TestSimulator_CaptureUnknownReference(t *testing.T) {
+	sim, err := NewSimulator("gopay", ModeAsync)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Transaction authorized before a restart is settled and refundable
+	captured, err := sim.Capture(ctx, "SIM-GOPAY-1-1", domain.NewMoney(50000))
+	require.NoError(t, err)
+	assert.Equal(t, StatusCaptured, captured.Status)
+
+	status, err := sim.Status(ctx, "SIM-GOPAY-1-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCaptured, status.Status)
+}
+
+func TestSimulator_UnknownMode(t *testing.T) {
+	_, err := NewSimulator("gopay", "flaky")
+	assert.Error(t, err)
+}
+
+func TestSimulatorRegistry_PerMethod(t *testing.T) {
+	registry, err := NewSimulatorRegistry(ModeSucceed, map[string]string{"OVO": ModeDecline})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = registry.Get("ovo").Authorize(ctx, &AuthorizeRequest{BookingID: 1})
+	assert.ErrorIs(t, err, ErrDeclined)
+
+	_, err = registry.Get("GOPAY").Authorize(ctx, &AuthorizeRequest{BookingID: 1})
+	assert.NoError(t, err)
+}
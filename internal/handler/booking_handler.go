@@ -2,7 +2,9 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/middleware"
@@ -10,6 +12,7 @@ import (
 	"project-app-bioskop-golang-homework-anas/internal/utils"
 	"project-app-bioskop-golang-homework-anas/pkg/validator"
 
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
@@ -58,7 +61,25 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 			zap.Int("user_id", user.ID),
 			zap.Error(err),
 		)
-		utils.SendBadRequest(w, err.Error(), nil)
+		switch {
+		case errors.Is(err, service.ErrSeatAlreadyTaken),
+			errors.Is(err, service.ErrAmbiguousShowtime),
+			errors.Is(err, service.ErrPromotionExhausted),
+			errors.Is(err, service.ErrPromotionUserLimit):
+			utils.SendConflict(w, err.Error())
+		case errors.Is(err, service.ErrSalesClosed):
+			utils.SendGone(w, err.Error())
+		case errors.Is(err, service.ErrShowtimeNotFound),
+			errors.Is(err, service.ErrSeatNotFound),
+			errors.Is(err, service.ErrInvalidSeatSelection),
+			errors.Is(err, service.ErrInvalidPaymentMethod),
+			errors.Is(err, service.ErrPromotionNotFound),
+			errors.Is(err, service.ErrPromotionNotActive),
+			errors.Is(err, service.ErrPromotionNotApplicable):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			utils.SendInternalServerError(w, "Failed to create booking", nil)
+		}
 		return
 	}
 
@@ -66,6 +87,7 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		zap.Int("booking_id", booking.ID),
 		zap.Int("user_id", user.ID),
 		zap.String("booking_code", booking.BookingCode),
+		zap.Int("seat_count", len(booking.Seats)),
 	)
 
 	utils.SendCreated(w, "Booking created successfully", booking)
@@ -99,3 +121,86 @@ func (h *BookingHandler) GetUserBookings(w http.ResponseWriter, r *http.Request)
 
 	utils.SendSuccess(w, "Bookings retrieved successfully", bookings)
 }
+
+// Get booking detail by booking code (owner only)
+func (h *BookingHandler) GetBookingByCode(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User not found in context")
+		utils.SendUnauthorized(w, "Unauthorized")
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+
+	booking, err := h.bookingService.GetBookingByCode(r.Context(), user.ID, code)
+	if err != nil {
+		h.logger.Error("Failed to get booking",
+			zap.Int("user_id", user.ID),
+			zap.String("booking_code", code),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, service.ErrBookingNotOwned):
+			utils.SendForbidden(w, err.Error())
+		default:
+			utils.SendNotFound(w, "Booking not found")
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Booking retrieved successfully", booking)
+}
+
+// Cancel a booking owned by the authenticated user
+func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User not found in context")
+		utils.SendUnauthorized(w, "Unauthorized")
+		return
+	}
+
+	// Get booking ID from URL parameter
+	bookingIDStr := chi.URLParam(r, "id")
+	bookingID, err := strconv.Atoi(bookingIDStr)
+	if err != nil {
+		h.logger.Error("Invalid booking ID", zap.String("booking_id", bookingIDStr), zap.Error(err))
+		utils.SendBadRequest(w, "Invalid booking ID", err)
+		return
+	}
+
+	// Cancel booking
+	booking, err := h.bookingService.CancelBooking(r.Context(), user.ID, bookingID)
+	if err != nil {
+		h.logger.Error("Failed to cancel booking",
+			zap.Int("user_id", user.ID),
+			zap.Int("booking_id", bookingID),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrBookingNotOwned):
+			utils.SendForbidden(w, err.Error())
+		case errors.Is(err, service.ErrBookingStatusChanged):
+			utils.SendConflict(w, err.Error())
+		case errors.Is(err, service.ErrBookingCancelled),
+			errors.Is(err, service.ErrBookingExpired),
+			errors.Is(err, service.ErrShowtimeStarted):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			utils.SendInternalServerError(w, "Failed to cancel booking", nil)
+		}
+		return
+	}
+
+	h.logger.Info("Booking cancelled successfully",
+		zap.Int("booking_id", booking.ID),
+		zap.Int("user_id", user.ID),
+	)
+
+	utils.SendSuccess(w, "Booking cancelled successfully", booking)
+}
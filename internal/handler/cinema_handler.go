@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/service"
 	"project-app-bioskop-golang-homework-anas/internal/utils"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -80,3 +84,123 @@ func (h *CinemaHandler) GetCinemaByID(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Cinema retrieved successfully", zap.Int("cinema_id", cinemaID))
 	utils.SendSuccess(w, "Cinema retrieved successfully", cinema)
 }
+
+// Create a new cinema (admin)
+func (h *CinemaHandler) CreateCinema(w http.ResponseWriter, r *http.Request) {
+	var req domain.CinemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	cinema, err := h.cinemaService.CreateCinema(r.Context(), &req)
+	if err != nil {
+		utils.SendInternalServerError(w, "Failed to create cinema", err)
+		return
+	}
+
+	utils.SendCreated(w, "Cinema created successfully", cinema)
+}
+
+// Update a cinema (admin)
+func (h *CinemaHandler) UpdateCinema(w http.ResponseWriter, r *http.Request) {
+	cinemaID, err := strconv.Atoi(chi.URLParam(r, "cinemaId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	var req domain.CinemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	cinema, err := h.cinemaService.UpdateCinema(r.Context(), cinemaID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrCinemaNotFound) {
+			utils.SendNotFound(w, "Cinema not found")
+			return
+		}
+		utils.SendInternalServerError(w, "Failed to update cinema", err)
+		return
+	}
+
+	utils.SendSuccess(w, "Cinema updated successfully", cinema)
+}
+
+// Soft delete a cinema without upcoming showtimes (admin)
+func (h *CinemaHandler) DeleteCinema(w http.ResponseWriter, r *http.Request) {
+	cinemaID, err := strconv.Atoi(chi.URLParam(r, "cinemaId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	if err := h.cinemaService.DeleteCinema(r.Context(), cinemaID); err != nil {
+		h.logger.Error("Failed to delete cinema", zap.Int("cinema_id", cinemaID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrCinemaNotFound):
+			utils.SendNotFound(w, "Cinema not found")
+		case errors.Is(err, service.ErrCinemaHasShowtimes):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to delete cinema", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Cinema deleted successfully", nil)
+}
+
+// Add a screen (studio) to a cinema (admin)
+func (h *CinemaHandler) CreateScreen(w http.ResponseWriter, r *http.Request) {
+	cinemaID, err := strconv.Atoi(chi.URLParam(r, "cinemaId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	var req domain.ScreenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	screen, err := h.cinemaService.CreateScreen(r.Context(), cinemaID, &req)
+	if err != nil {
+		h.logger.Error("Failed to create screen", zap.Int("cinema_id", cinemaID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrCinemaNotFound):
+			utils.SendNotFound(w, "Cinema not found")
+		case errors.Is(err, service.ErrScreenExists):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to create screen", err)
+		}
+		return
+	}
+
+	utils.SendCreated(w, "Screen created successfully", screen)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type MovieHandler struct {
+	movieService service.MovieService
+	logger       *zap.Logger
+}
+
+func NewMovieHandler(movieService service.MovieService, logger *zap.Logger) *MovieHandler {
+	return &MovieHandler{
+		movieService: movieService,
+		logger:       logger,
+	}
+}
+
+// Get list of movies with pagination, filterable by genre and rating
+func (h *MovieHandler) GetAllMovies(w http.ResponseWriter, r *http.Request) {
+	// Get pagination parameters from query
+	page := 1
+	limit := 10
+
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	filter := domain.MovieFilter{
+		Genre:  r.URL.Query().Get("genre"),
+		Rating: r.URL.Query().Get("rating"),
+	}
+
+	// Get movies
+	movies, meta, err := h.movieService.GetAllMovies(r.Context(), filter, page, limit)
+	if err != nil {
+		h.logger.Error("Failed to get movies", zap.Error(err))
+		utils.SendInternalServerError(w, "Failed to get movies", err)
+		return
+	}
+
+	h.logger.Info("Movies retrieved successfully",
+		zap.Int("page", page),
+		zap.Int("limit", limit),
+		zap.Int("total", meta.TotalRows),
+	)
+
+	utils.SendPaginated(w, "Movies retrieved successfully", movies, meta)
+}
+
+// Get movie detail with upcoming showtimes across all cinemas
+func (h *MovieHandler) GetMovieByID(w http.ResponseWriter, r *http.Request) {
+	// Get movie ID from URL parameter
+	movieIDStr := chi.URLParam(r, "id")
+	movieID, err := strconv.Atoi(movieIDStr)
+	if err != nil {
+		h.logger.Error("Invalid movie ID", zap.String("movie_id", movieIDStr), zap.Error(err))
+		utils.SendBadRequest(w, "Invalid movie ID", err)
+		return
+	}
+
+	// Get movie
+	movie, err := h.movieService.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		h.logger.Error("Failed to get movie", zap.Int("movie_id", movieID), zap.Error(err))
+		if errors.Is(err, service.ErrMovieNotFound) {
+			utils.SendNotFound(w, "Movie not found")
+			return
+		}
+		utils.SendInternalServerError(w, "Failed to get movie", err)
+		return
+	}
+
+	h.logger.Info("Movie retrieved successfully", zap.Int("movie_id", movieID))
+	utils.SendSuccess(w, "Movie retrieved successfully", movie)
+}
+
+// Create a new movie (admin)
+func (h *MovieHandler) CreateMovie(w http.ResponseWriter, r *http.Request) {
+	var req domain.MovieRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	movie, err := h.movieService.CreateMovie(r.Context(), &req)
+	if err != nil {
+		utils.SendInternalServerError(w, "Failed to create movie", err)
+		return
+	}
+
+	utils.SendCreated(w, "Movie created successfully", movie)
+}
+
+// Update a movie (admin)
+func (h *MovieHandler) UpdateMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid movie ID", err)
+		return
+	}
+
+	var req domain.MovieRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	movie, err := h.movieService.UpdateMovie(r.Context(), movieID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMovieNotFound):
+			utils.SendNotFound(w, "Movie not found")
+		case errors.Is(err, service.ErrShowtimeOverlap):
+			utils.SendConflict(w, "New duration makes upcoming showtimes overlap")
+		default:
+			utils.SendInternalServerError(w, "Failed to update movie", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Movie updated successfully", movie)
+}
+
+// Soft delete a movie without upcoming showtimes (admin)
+func (h *MovieHandler) DeleteMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid movie ID", err)
+		return
+	}
+
+	if err := h.movieService.DeleteMovie(r.Context(), movieID); err != nil {
+		h.logger.Error("Failed to delete movie", zap.Int("movie_id", movieID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrMovieNotFound):
+			utils.SendNotFound(w, "Movie not found")
+		case errors.Is(err, service.ErrMovieHasShowtimes):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to delete movie", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Movie deleted successfully", nil)
+}
@@ -2,16 +2,23 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/middleware"
 	"project-app-bioskop-golang-homework-anas/internal/service"
 	"project-app-bioskop-golang-homework-anas/internal/utils"
 	"project-app-bioskop-golang-homework-anas/pkg/validator"
 
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 )
 
+// maxWebhookBodySize membatasi ukuran payload webhook dari provider
+const maxWebhookBodySize = 64 << 10
+
 type PaymentHandler struct {
 	paymentService service.PaymentService
 	logger         *zap.Logger
@@ -26,6 +33,14 @@ func NewPaymentHandler(paymentService service.PaymentService, logger *zap.Logger
 
 // Process payment for an existing booking
 func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User not found in context")
+		utils.SendUnauthorized(w, "Unauthorized")
+		return
+	}
+
 	var req domain.PaymentRequest
 
 	// Decode request body
@@ -43,21 +58,97 @@ func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Process payment
-	payment, err := h.paymentService.ProcessPayment(r.Context(), &req)
+	payment, err := h.paymentService.ProcessPayment(r.Context(), user.ID, &req)
 	if err != nil {
 		h.logger.Error("Failed to process payment",
+			zap.Int("user_id", user.ID),
 			zap.Int("booking_id", req.BookingID),
 			zap.Error(err),
 		)
-		utils.SendBadRequest(w, err.Error(), nil)
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrBookingNotOwned):
+			utils.SendForbidden(w, err.Error())
+		case errors.Is(err, service.ErrDuplicatePayment),
+			errors.Is(err, service.ErrBookingNotPending),
+			errors.Is(err, service.ErrPromotionExhausted),
+			errors.Is(err, service.ErrPromotionUserLimit),
+			errors.Is(err, service.ErrPromotionAlreadyApplied):
+			utils.SendConflict(w, err.Error())
+		case errors.Is(err, service.ErrPaymentDeclined):
+			utils.SendError(w, http.StatusPaymentRequired, err.Error(), nil)
+		case errors.Is(err, service.ErrPaymentTimeout):
+			utils.SendError(w, http.StatusGatewayTimeout, err.Error(), nil)
+		case errors.Is(err, service.ErrBookingAlreadyPaid),
+			errors.Is(err, service.ErrBookingCancelled),
+			errors.Is(err, service.ErrBookingExpired),
+			errors.Is(err, service.ErrPaymentInProgress),
+			errors.Is(err, service.ErrInvalidPaymentMethod),
+			errors.Is(err, service.ErrPromotionNotFound),
+			errors.Is(err, service.ErrPromotionNotActive),
+			errors.Is(err, service.ErrPromotionNotApplicable):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			utils.SendInternalServerError(w, "Failed to process payment", nil)
+		}
+		return
+	}
+
+	// Async provider, final status arrives later
+	if payment.Status == "pending" {
+		h.logger.Info("Payment awaiting provider confirmation",
+			zap.Int("payment_id", payment.ID),
+			zap.Int("booking_id", req.BookingID),
+		)
+		utils.SendJSON(w, http.StatusAccepted, utils.Response{
+			Success: true,
+			Message: "Payment is being processed",
+			Data:    payment,
+		})
 		return
 	}
 
 	h.logger.Info("Payment processed successfully",
 		zap.Int("payment_id", payment.ID),
 		zap.Int("booking_id", req.BookingID),
-		zap.Float64("amount", payment.Amount),
+		zap.Stringer("amount", payment.Amount),
 	)
 
 	utils.SendSuccess(w, "Payment processed successfully", payment)
 }
+
+// Receive asynchronous payment status from a payment provider
+func (h *PaymentHandler) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+
+	// Signature is computed over the raw body, so read it before decoding
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		h.logger.Error("Failed to read webhook body", zap.String("provider", provider), zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	err = h.paymentService.HandleWebhook(r.Context(), provider, payload, r.Header.Get("X-Signature"))
+	if err != nil {
+		h.logger.Error("Failed to handle payment webhook",
+			zap.String("provider", provider),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, service.ErrInvalidSignature):
+			utils.SendUnauthorized(w, err.Error())
+		case errors.Is(err, service.ErrPaymentNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidWebhookPayload):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			// Transient failure, the provider retries the webhook
+			utils.SendInternalServerError(w, "Failed to process webhook", nil)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Webhook processed successfully", nil)
+}
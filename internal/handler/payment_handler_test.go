@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/middleware"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func init() {
+	validator.InitValidator()
+}
+
+type MockPaymentService struct {
+	mock.Mock
+}
+
+func (m *MockPaymentService) ProcessPayment(ctx context.Context, userID int, req *domain.PaymentRequest) (*domain.Payment, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
+func (m *MockPaymentService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	args := m.Called(ctx, provider, payload, signature)
+	return args.Error(0)
+}
+
+func newPaymentRequest(user *domain.User) *http.Request {
+	body := `{"booking_id": 1, "payment_method": "CREDIT_CARD"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/pay", strings.NewReader(body))
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+	}
+	return req
+}
+
+func TestPaymentHandler_ProcessPayment_Success(t *testing.T) {
+	mockPaymentService := new(MockPaymentService)
+	h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+	mockPaymentService.On("ProcessPayment", mock.Anything, 1, mock.AnythingOfType("*domain.PaymentRequest")).
+		Return(&domain.Payment{ID: 1, BookingID: 1, Amount: domain.NewMoney(50000), Status: "success"}, nil)
+
+	rec := httptest.NewRecorder()
+	h.ProcessPayment(rec, newPaymentRequest(&domain.User{ID: 1}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockPaymentService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_ProcessPayment_NotOwner(t *testing.T) {
+	mockPaymentService := new(MockPaymentService)
+	h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+	mockPaymentService.On("ProcessPayment", mock.Anything, 2, mock.AnythingOfType("*domain.PaymentRequest")).
+		Return(nil, service.ErrBookingNotOwned)
+
+	rec := httptest.NewRecorder()
+	h.ProcessPayment(rec, newPaymentRequest(&domain.User{ID: 2}))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), service.ErrBookingNotOwned.Error())
+	mockPaymentService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_ProcessPayment_PromoLookupFails(t *testing.T) {
+	mockPaymentService := new(MockPaymentService)
+	h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+	mockPaymentService.On("ProcessPayment", mock.Anything, 1, mock.AnythingOfType("*domain.PaymentRequest")).
+		Return(nil, fmt.Errorf("%w: %w", service.ErrPromotionLookupFailed, errors.New("connection refused")))
+
+	rec := httptest.NewRecorder()
+	h.ProcessPayment(rec, newPaymentRequest(&domain.User{ID: 1}))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "connection refused")
+	mockPaymentService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_ProcessPayment_Unauthenticated(t *testing.T) {
+	mockPaymentService := new(MockPaymentService)
+	h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+	rec := httptest.NewRecorder()
+	h.ProcessPayment(rec, newPaymentRequest(nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockPaymentService.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentHandler_PaymentWebhook_InvalidSignature(t *testing.T) {
+	mockPaymentService := new(MockPaymentService)
+	h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+	body := `{"reference": "SIM-GOPAY-1-1", "status": "success"}`
+	mockPaymentService.On("HandleWebhook", mock.Anything, "gopay", []byte(body), "bad").Return(service.ErrInvalidSignature)
+
+	r := chi.NewRouter()
+	r.Post("/api/payments/webhook/{provider}", h.PaymentWebhook)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook/gopay", strings.NewReader(body))
+	req.Header.Set("X-Signature", "bad")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	mockPaymentService.AssertExpectations(t)
+}
+
+func TestPaymentHandler_PaymentWebhook_ErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"not found", service.ErrPaymentNotFound, http.StatusNotFound},
+		{"invalid payload", fmt.Errorf("%w: reference is required", service.ErrInvalidWebhookPayload), http.StatusBadRequest},
+		// Transient failures must be retried by the provider
+		{"database error", fmt.Errorf("failed to update payment: %w", errors.New("connection refused")), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPaymentService := new(MockPaymentService)
+			h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+
+			body := `{"reference": "SIM-GOPAY-1-1", "status": "success"}`
+			mockPaymentService.On("HandleWebhook", mock.Anything, "gopay", []byte(body), "sig").Return(tt.err)
+
+			r := chi.NewRouter()
+			r.Post("/api/payments/webhook/{provider}", h.PaymentWebhook)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/payments/webhook/gopay", strings.NewReader(body))
+			req.Header.Set("X-Signature", "sig")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.NotContains(t, rec.Body.String(), "connection refused")
+		})
+	}
+}
+
+type MockIdempotencyService struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyService) Begin(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error) {
+	args := m.Called(ctx, userID, key, requestHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyKey, statusCode int, body []byte) error {
+	args := m.Called(ctx, record, statusCode, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyService) Release(ctx context.Context, record *domain.IdempotencyKey) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func TestPaymentHandler_ProcessPayment_IdempotencyKeyOnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		status   int
+		released bool
+	}{
+		// Client errors are final and replayed on retry
+		{"booking expired", service.ErrBookingExpired, http.StatusBadRequest, false},
+		// Transient failures must not be cached, the retry runs the payment again
+		{"database error", fmt.Errorf("failed to process payment: %w", errors.New("connection refused")), http.StatusInternalServerError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPaymentService := new(MockPaymentService)
+			mockIdempotencyService := new(MockIdempotencyService)
+			h := NewPaymentHandler(mockPaymentService, zap.NewNop())
+			idempotent := middleware.NewIdempotencyMiddleware(mockIdempotencyService, zap.NewNop()).Idempotent(http.HandlerFunc(h.ProcessPayment))
+
+			record := &domain.IdempotencyKey{ID: 1, UserID: 1, Key: "pay-1"}
+			mockIdempotencyService.On("Begin", mock.Anything, 1, "pay-1", mock.Anything).Return(record, nil)
+			mockPaymentService.On("ProcessPayment", mock.Anything, 1, mock.AnythingOfType("*domain.PaymentRequest")).Return(nil, tt.err)
+			if tt.released {
+				mockIdempotencyService.On("Release", mock.Anything, record).Return(nil)
+			} else {
+				mockIdempotencyService.On("Complete", mock.Anything, record, tt.status, mock.Anything).Return(nil)
+			}
+
+			req := newPaymentRequest(&domain.User{ID: 1})
+			req.Header.Set(middleware.IdempotencyKeyHeader, "pay-1")
+			rec := httptest.NewRecorder()
+			idempotent.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			mockIdempotencyService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/service"
 	"project-app-bioskop-golang-homework-anas/internal/utils"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -23,7 +27,7 @@ func NewSeatHandler(seatService service.SeatService, logger *zap.Logger) *SeatHa
 	}
 }
 
-// Get seat availability for a specific cinema, date, and time
+// Get seat availability for a showtime (showtime_id, or legacy date and time)
 func (h *SeatHandler) GetSeatAvailability(w http.ResponseWriter, r *http.Request) {
 	// Get cinema ID from URL parameter
 	cinemaIDStr := chi.URLParam(r, "cinemaId")
@@ -34,31 +38,52 @@ func (h *SeatHandler) GetSeatAvailability(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get date and time from query parameters
+	// Get showtime_id, or legacy date and time, from query parameters
+	showtimeID := 0
+	if showtimeIDStr := r.URL.Query().Get("showtime_id"); showtimeIDStr != "" {
+		showtimeID, err = strconv.Atoi(showtimeIDStr)
+		if err != nil || showtimeID < 1 {
+			h.logger.Error("Invalid showtime ID", zap.String("showtime_id", showtimeIDStr))
+			utils.SendBadRequest(w, "Invalid showtime ID", err)
+			return
+		}
+	}
+
 	date := r.URL.Query().Get("date")
 	time := r.URL.Query().Get("time")
 
-	if date == "" || time == "" {
-		h.logger.Error("Missing date or time parameter")
-		utils.SendBadRequest(w, "Date and time parameters are required", nil)
+	if showtimeID == 0 && (date == "" || time == "") {
+		h.logger.Error("Missing showtime_id or date and time parameters")
+		utils.SendBadRequest(w, "showtime_id or date and time parameters are required", nil)
 		return
 	}
 
 	// Get seat availability
-	seats, showtime, err := h.seatService.GetSeatAvailability(r.Context(), cinemaID, date, time)
+	seats, showtime, err := h.seatService.GetSeatAvailability(r.Context(), cinemaID, showtimeID, date, time)
 	if err != nil {
 		h.logger.Error("Failed to get seat availability",
 			zap.Int("cinema_id", cinemaID),
+			zap.Int("showtime_id", showtimeID),
 			zap.String("date", date),
 			zap.String("time", time),
 			zap.Error(err),
 		)
-		utils.SendNotFound(w, err.Error())
+		switch {
+		case errors.Is(err, service.ErrAmbiguousShowtime):
+			utils.SendConflict(w, err.Error())
+		case errors.Is(err, service.ErrSalesClosed):
+			utils.SendGone(w, err.Error())
+		case errors.Is(err, service.ErrSeatPricingFailed):
+			utils.SendInternalServerError(w, "Failed to get seat availability", nil)
+		default:
+			utils.SendNotFound(w, err.Error())
+		}
 		return
 	}
 
 	h.logger.Info("Seat availability retrieved successfully",
 		zap.Int("cinema_id", cinemaID),
+		zap.Int("showtime_id", showtime.ID),
 		zap.String("date", date),
 		zap.String("time", time),
 		zap.Int("total_seats", len(seats)),
@@ -67,8 +92,177 @@ func (h *SeatHandler) GetSeatAvailability(w http.ResponseWriter, r *http.Request
 	// Create response with showtime info and seats
 	response := map[string]interface{}{
 		"showtime": showtime,
+		"screen":   showtime.Screen,
 		"seats":    seats,
 	}
 
 	utils.SendSuccess(w, "Seat availability retrieved successfully", response)
 }
+
+// Import seat map layout for a screen (staff only)
+func (h *SeatHandler) ImportSeatLayout(w http.ResponseWriter, r *http.Request) {
+	cinemaID, err := strconv.Atoi(chi.URLParam(r, "cinemaId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	screenID, err := strconv.Atoi(chi.URLParam(r, "screenId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid screen ID", err)
+		return
+	}
+
+	var layout domain.SeatLayout
+	if err := json.NewDecoder(r.Body).Decode(&layout); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&layout); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	seats, err := h.seatService.ImportLayout(r.Context(), cinemaID, screenID, &layout)
+	if err != nil {
+		h.logger.Error("Failed to import seat layout",
+			zap.Int("cinema_id", cinemaID),
+			zap.Int("screen_id", screenID),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, service.ErrScreenNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidSeatLayout):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			utils.SendInternalServerError(w, "Failed to import seat layout", err)
+		}
+		return
+	}
+
+	response := map[string]interface{}{
+		"screen_id": screenID,
+		"layout":    layout.Grid(),
+		"seats":     seats,
+	}
+
+	utils.SendSuccess(w, "Seat layout imported successfully", response)
+}
+
+// Add a single seat to a screen (admin)
+func (h *SeatHandler) CreateSeat(w http.ResponseWriter, r *http.Request) {
+	cinemaID, err := strconv.Atoi(chi.URLParam(r, "cinemaId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	screenID, err := strconv.Atoi(chi.URLParam(r, "screenId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid screen ID", err)
+		return
+	}
+
+	var req domain.SeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	seat, err := h.seatService.CreateSeat(r.Context(), cinemaID, screenID, &req)
+	if err != nil {
+		h.logger.Error("Failed to create seat", zap.Int("screen_id", screenID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrSeatNotFound),
+			errors.Is(err, service.ErrScreenNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidSeatLayout):
+			utils.SendBadRequest(w, err.Error(), nil)
+		case errors.Is(err, service.ErrSeatExists),
+			errors.Is(err, service.ErrSeatPositionTaken):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to save seat", err)
+		}
+		return
+	}
+
+	utils.SendCreated(w, "Seat created successfully", seat)
+}
+
+// Update seat type, position and status (admin)
+func (h *SeatHandler) UpdateSeat(w http.ResponseWriter, r *http.Request) {
+	seatID, err := strconv.Atoi(chi.URLParam(r, "seatId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid seat ID", err)
+		return
+	}
+
+	var req domain.SeatUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	seat, err := h.seatService.UpdateSeat(r.Context(), seatID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update seat", zap.Int("seat_id", seatID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrSeatNotFound),
+			errors.Is(err, service.ErrScreenNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidSeatLayout):
+			utils.SendBadRequest(w, err.Error(), nil)
+		case errors.Is(err, service.ErrSeatExists),
+			errors.Is(err, service.ErrSeatPositionTaken):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to save seat", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Seat updated successfully", seat)
+}
+
+// Soft delete a seat without bookings for upcoming showtimes (admin)
+func (h *SeatHandler) DeleteSeat(w http.ResponseWriter, r *http.Request) {
+	seatID, err := strconv.Atoi(chi.URLParam(r, "seatId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid seat ID", err)
+		return
+	}
+
+	if err := h.seatService.DeleteSeat(r.Context(), seatID); err != nil {
+		h.logger.Error("Failed to delete seat", zap.Int("seat_id", seatID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrSeatNotFound):
+			utils.SendNotFound(w, "Seat not found")
+		case errors.Is(err, service.ErrSeatHasBookings):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to delete seat", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Seat deleted successfully", nil)
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type ShowtimeHandler struct {
+	showtimeService service.ShowtimeService
+	logger          *zap.Logger
+}
+
+func NewShowtimeHandler(showtimeService service.ShowtimeService, logger *zap.Logger) *ShowtimeHandler {
+	return &ShowtimeHandler{
+		showtimeService: showtimeService,
+		logger:          logger,
+	}
+}
+
+// Get showtimes of a cinema on a date with remaining seats
+func (h *ShowtimeHandler) GetCinemaShowtimes(w http.ResponseWriter, r *http.Request) {
+	// Get cinema ID from URL parameter
+	cinemaIDStr := chi.URLParam(r, "cinemaId")
+	cinemaID, err := strconv.Atoi(cinemaIDStr)
+	if err != nil {
+		h.logger.Error("Invalid cinema ID", zap.String("cinema_id", cinemaIDStr), zap.Error(err))
+		utils.SendBadRequest(w, "Invalid cinema ID", err)
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+
+	showtimes, err := h.showtimeService.GetCinemaShowtimes(r.Context(), cinemaID, date)
+	if err != nil {
+		h.logger.Error("Failed to get cinema showtimes",
+			zap.Int("cinema_id", cinemaID),
+			zap.String("date", date),
+			zap.Error(err),
+		)
+		h.sendError(w, err)
+		return
+	}
+
+	h.logger.Info("Cinema showtimes retrieved successfully",
+		zap.Int("cinema_id", cinemaID),
+		zap.String("date", date),
+		zap.Int("total", len(showtimes)),
+	)
+
+	utils.SendSuccess(w, "Showtimes retrieved successfully", showtimes)
+}
+
+// Get showtimes of a movie across all cinemas within a date range with remaining seats
+func (h *ShowtimeHandler) GetMovieShowtimes(w http.ResponseWriter, r *http.Request) {
+	// Get movie ID from URL parameter
+	movieIDStr := chi.URLParam(r, "id")
+	movieID, err := strconv.Atoi(movieIDStr)
+	if err != nil {
+		h.logger.Error("Invalid movie ID", zap.String("movie_id", movieIDStr), zap.Error(err))
+		utils.SendBadRequest(w, "Invalid movie ID", err)
+		return
+	}
+
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+
+	showtimes, err := h.showtimeService.GetMovieShowtimes(r.Context(), movieID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get movie showtimes",
+			zap.Int("movie_id", movieID),
+			zap.String("from", from),
+			zap.String("to", to),
+			zap.Error(err),
+		)
+		h.sendError(w, err)
+		return
+	}
+
+	h.logger.Info("Movie showtimes retrieved successfully",
+		zap.Int("movie_id", movieID),
+		zap.Int("total", len(showtimes)),
+	)
+
+	utils.SendSuccess(w, "Showtimes retrieved successfully", showtimes)
+}
+
+func (h *ShowtimeHandler) sendError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidScheduleRange):
+		utils.SendBadRequest(w, err.Error(), nil)
+	case errors.Is(err, service.ErrCinemaNotFound):
+		utils.SendNotFound(w, "Cinema not found")
+	case errors.Is(err, service.ErrMovieNotFound):
+		utils.SendNotFound(w, "Movie not found")
+	default:
+		utils.SendInternalServerError(w, "Failed to get showtimes", err)
+	}
+}
+
+// Schedule a movie on a screen (admin)
+func (h *ShowtimeHandler) CreateShowtime(w http.ResponseWriter, r *http.Request) {
+	var req domain.ShowtimeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	showtime, err := h.showtimeService.CreateShowtime(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to create showtime", zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrShowtimeNotFound),
+			errors.Is(err, service.ErrScreenNotFound),
+			errors.Is(err, service.ErrCinemaNotFound),
+			errors.Is(err, service.ErrMovieNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidShowtime):
+			utils.SendBadRequest(w, err.Error(), nil)
+		case errors.Is(err, service.ErrShowtimeExists),
+			errors.Is(err, service.ErrShowtimeOverlap),
+			errors.Is(err, service.ErrShowtimeHasBookings):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to save showtime", err)
+		}
+		return
+	}
+
+	utils.SendCreated(w, "Showtime created successfully", showtime)
+}
+
+// Update a showtime (admin)
+func (h *ShowtimeHandler) UpdateShowtime(w http.ResponseWriter, r *http.Request) {
+	showtimeID, err := strconv.Atoi(chi.URLParam(r, "showtimeId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid showtime ID", err)
+		return
+	}
+
+	var req domain.ShowtimeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	showtime, err := h.showtimeService.UpdateShowtime(r.Context(), showtimeID, &req)
+	if err != nil {
+		h.logger.Error("Failed to update showtime", zap.Int("showtime_id", showtimeID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrShowtimeNotFound),
+			errors.Is(err, service.ErrScreenNotFound),
+			errors.Is(err, service.ErrCinemaNotFound),
+			errors.Is(err, service.ErrMovieNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidShowtime):
+			utils.SendBadRequest(w, err.Error(), nil)
+		case errors.Is(err, service.ErrShowtimeExists),
+			errors.Is(err, service.ErrShowtimeOverlap),
+			errors.Is(err, service.ErrShowtimeHasBookings):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to save showtime", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Showtime updated successfully", showtime)
+}
+
+// Soft delete a showtime without active bookings (admin)
+func (h *ShowtimeHandler) DeleteShowtime(w http.ResponseWriter, r *http.Request) {
+	showtimeID, err := strconv.Atoi(chi.URLParam(r, "showtimeId"))
+	if err != nil {
+		utils.SendBadRequest(w, "Invalid showtime ID", err)
+		return
+	}
+
+	if err := h.showtimeService.DeleteShowtime(r.Context(), showtimeID); err != nil {
+		h.logger.Error("Failed to delete showtime", zap.Int("showtime_id", showtimeID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrShowtimeNotFound):
+			utils.SendNotFound(w, "Showtime not found")
+		case errors.Is(err, service.ErrShowtimeHasBookings):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendInternalServerError(w, "Failed to delete showtime", err)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Showtime deleted successfully", nil)
+}
+
+// Generate recurring showtimes from a weekly pattern (admin)
+func (h *ShowtimeHandler) GenerateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req domain.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	result, err := h.showtimeService.GenerateSchedule(r.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to generate schedule", zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrScreenNotFound),
+			errors.Is(err, service.ErrCinemaNotFound),
+			errors.Is(err, service.ErrMovieNotFound):
+			utils.SendNotFound(w, err.Error())
+		case errors.Is(err, service.ErrInvalidScheduleRange),
+			errors.Is(err, service.ErrInvalidShowtime):
+			utils.SendBadRequest(w, err.Error(), nil)
+		case result != nil:
+			// Stopped halfway: tell the admin which showtimes already exist
+			utils.SendJSON(w, http.StatusInternalServerError, utils.Response{
+				Success: false,
+				Message: fmt.Sprintf("Schedule generation stopped: %d created, %d skipped", len(result.Created), len(result.Skipped)),
+				Data:    result,
+				Error:   err.Error(),
+			})
+		default:
+			utils.SendInternalServerError(w, "Failed to generate schedule", err)
+		}
+		return
+	}
+
+	message := fmt.Sprintf("Schedule generated: %d created, %d skipped", len(result.Created), len(result.Skipped))
+	utils.SendCreated(w, message, result)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/middleware"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+	"project-app-bioskop-golang-homework-anas/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type TicketHandler struct {
+	ticketService service.TicketService
+	logger        *zap.Logger
+}
+
+func NewTicketHandler(ticketService service.TicketService, logger *zap.Logger) *TicketHandler {
+	return &TicketHandler{
+		ticketService: ticketService,
+		logger:        logger,
+	}
+}
+
+// Get e-ticket QR code (PNG) for a confirmed booking
+func (h *TicketHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	user, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		h.logger.Error("User not found in context")
+		utils.SendUnauthorized(w, "Unauthorized")
+		return
+	}
+
+	code := chi.URLParam(r, "code")
+
+	png, err := h.ticketService.GetTicketQRCode(r.Context(), user.ID, code)
+	if err != nil {
+		h.logger.Error("Failed to get ticket",
+			zap.Int("user_id", user.ID),
+			zap.String("booking_code", code),
+			zap.Error(err),
+		)
+		switch {
+		case errors.Is(err, service.ErrBookingNotFound):
+			utils.SendNotFound(w, "Booking not found")
+		case errors.Is(err, service.ErrBookingNotOwned):
+			utils.SendForbidden(w, err.Error())
+		case errors.Is(err, service.ErrTicketNotAvailable):
+			utils.SendBadRequest(w, err.Error(), nil)
+		default:
+			utils.SendError(w, http.StatusInternalServerError, "Failed to generate ticket", nil)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// Check in a scanned ticket at the door (staff only)
+func (h *TicketHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	var req domain.CheckInRequest
+
+	// Decode request body
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Failed to decode request", zap.Error(err))
+		utils.SendBadRequest(w, "Invalid request body", err)
+		return
+	}
+
+	// Validate request
+	if err := validator.ValidateStruct(&req); err != nil {
+		h.logger.Error("Validation failed", zap.Error(err))
+		utils.SendBadRequest(w, "Validation failed", err)
+		return
+	}
+
+	booking, err := h.ticketService.CheckIn(r.Context(), &req)
+	if err != nil {
+		h.logger.Warn("Check-in rejected", zap.Int("showtime_id", req.ShowtimeID), zap.Error(err))
+		switch {
+		case errors.Is(err, service.ErrTicketAlreadyUsed):
+			utils.SendConflict(w, err.Error())
+		default:
+			utils.SendBadRequest(w, err.Error(), nil)
+		}
+		return
+	}
+
+	utils.SendSuccess(w, "Check-in successful", booking)
+}
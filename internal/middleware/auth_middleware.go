@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -59,6 +60,40 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// RequireRole membatasi endpoint untuk user dengan salah satu role yang diberikan.
+// Harus dipasang setelah RequireAuth. Role yang tidak dikenal membuat panic saat route
+// didaftarkan, supaya salah ketik tidak diam-diam mengunci endpoint.
+func (m *AuthMiddleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	for _, role := range roles {
+		if !domain.IsValidRole(role) {
+			panic(fmt.Sprintf("RequireRole: unknown role %q", role))
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				utils.SendUnauthorized(w, "Authorization token required")
+				return
+			}
+
+			if !user.HasRole(roles...) {
+				m.logger.Warn("Rejected request for role",
+					zap.Int("user_id", user.ID),
+					zap.String("role", user.Role),
+					zap.Strings("required_roles", roles),
+					zap.String("path", r.URL.Path),
+				)
+				utils.SendForbidden(w, "You do not have access to this resource")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // GetUserFromContext mengambil user dari context
 func GetUserFromContext(ctx context.Context) (*domain.User, bool) {
 	user, ok := ctx.Value(UserContextKey).(*domain.User)
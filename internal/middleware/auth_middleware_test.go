@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestAuthMiddleware_RequireRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *domain.User
+		wantStatus int
+	}{
+		{name: "staff allowed", user: &domain.User{ID: 1, Role: domain.RoleStaff}, wantStatus: http.StatusOK},
+		{name: "admin allowed", user: &domain.User{ID: 2, Role: domain.RoleAdmin}, wantStatus: http.StatusOK},
+		{name: "customer forbidden", user: &domain.User{ID: 3, Role: domain.RoleCustomer}, wantStatus: http.StatusForbidden},
+		{name: "not authenticated", user: nil, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			h := NewAuthMiddleware(nil, zap.NewNop()).RequireRole(domain.RoleStaff, domain.RoleAdmin)(next)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/checkin", nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), UserContextKey, tt.user))
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestAuthMiddleware_RequireRole_UnknownRole(t *testing.T) {
+	assert.Panics(t, func() {
+		NewAuthMiddleware(nil, zap.NewNop()).RequireRole(domain.RoleAdmin, "superadmin")
+	})
+}
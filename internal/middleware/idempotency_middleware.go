@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+	idempotencyStoreTimeout   = 5 * time.Second
+)
+
+type IdempotencyMiddleware struct {
+	idempotencyService service.IdempotencyService
+	logger             *zap.Logger
+}
+
+func NewIdempotencyMiddleware(idempotencyService service.IdempotencyService, logger *zap.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		idempotencyService: idempotencyService,
+		logger:             logger,
+	}
+}
+
+// recordingWriter meneruskan response ke client sambil menyimpan salinannya
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Idempotent mengembalikan response awal untuk request yang di-retry dengan
+// Idempotency-Key dan body yang sama. Harus dipasang setelah RequireAuth.
+func (m *IdempotencyMiddleware) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			utils.SendBadRequest(w, "Idempotency-Key is too long", nil)
+			return
+		}
+
+		user, ok := GetUserFromContext(r.Context())
+		if !ok {
+			utils.SendUnauthorized(w, "Unauthorized")
+			return
+		}
+
+		// Read body so it can be hashed and handed to the handler again. Oversized
+		// bodies are rejected instead of truncated, otherwise two different bodies
+		// sharing the same first megabyte would hash the same.
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				utils.SendError(w, http.StatusRequestEntityTooLarge, "Request body is too large", nil)
+				return
+			}
+			utils.SendBadRequest(w, "Invalid request body", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := m.idempotencyService.Begin(r.Context(), user.ID, key, requestHash(r, body))
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+				utils.SendConflict(w, err.Error())
+			case errors.Is(err, service.ErrIdempotencyKeyMismatch):
+				utils.SendError(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			default:
+				utils.SendError(w, http.StatusInternalServerError, "Failed to process Idempotency-Key", nil)
+			}
+			return
+		}
+
+		// Retry of a finished request: replay the stored response
+		if record.IsCompleted() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set(IdempotentReplayedHeader, "true")
+			w.WriteHeader(record.StatusCode)
+			w.Write(record.ResponseBody)
+			return
+		}
+
+		// A panicking handler must not leave the key stuck "in progress" forever
+		defer func() {
+			if p := recover(); p != nil {
+				m.release(r, record)
+				panic(p)
+			}
+		}()
+
+		rw := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		// Server errors are not final, let the client retry with the same key
+		if rw.statusCode >= http.StatusInternalServerError {
+			m.release(r, record)
+			return
+		}
+
+		ctx, cancel := detachedContext(r)
+		defer cancel()
+		if err := m.idempotencyService.Complete(ctx, record, rw.statusCode, rw.body.Bytes()); err != nil {
+			m.logger.Error("Failed to save idempotent response",
+				zap.Int("idempotency_key_id", record.ID),
+				zap.Error(err),
+			)
+			m.release(r, record)
+		}
+	})
+}
+
+// release melepas key supaya retry berikutnya dieksekusi ulang
+func (m *IdempotencyMiddleware) release(r *http.Request, record *domain.IdempotencyKey) {
+	ctx, cancel := detachedContext(r)
+	defer cancel()
+
+	if err := m.idempotencyService.Release(ctx, record); err != nil {
+		m.logger.Error("Failed to release Idempotency-Key",
+			zap.Int("idempotency_key_id", record.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// detachedContext tetap hidup walaupun client sudah memutus koneksi,
+// supaya status key tetap tersimpan setelah handler selesai
+func detachedContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyStoreTimeout)
+}
+
+// requestHash membedakan request dengan key yang sama tapi isi berbeda
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// memoryIdempotencyService menyimpan key di memory untuk test
+type memoryIdempotencyService struct {
+	mu          sync.Mutex
+	records     map[string]*domain.IdempotencyKey
+	completeErr error
+}
+
+func newMemoryIdempotencyService() *memoryIdempotencyService {
+	return &memoryIdempotencyService{records: make(map[string]*domain.IdempotencyKey)}
+}
+
+func (s *memoryIdempotencyService) Begin(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok {
+		if existing.RequestHash != requestHash {
+			return nil, service.ErrIdempotencyKeyMismatch
+		}
+		if !existing.IsCompleted() {
+			return nil, service.ErrIdempotencyKeyInProgress
+		}
+		return existing, nil
+	}
+
+	record := &domain.IdempotencyKey{ID: len(s.records) + 1, UserID: userID, Key: key, RequestHash: requestHash}
+	s.records[key] = record
+	return record, nil
+}
+
+func (s *memoryIdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyKey, statusCode int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+
+	now := time.Now()
+	record.StatusCode = statusCode
+	record.ResponseBody = append([]byte(nil), body...)
+	record.CompletedAt = &now
+	return nil
+}
+
+func (s *memoryIdempotencyService) Release(ctx context.Context, record *domain.IdempotencyKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, record.Key)
+	return nil
+}
+
+func newIdempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/booking", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	return req.WithContext(context.WithValue(req.Context(), UserContextKey, &domain.User{ID: 1}))
+}
+
+func TestIdempotencyMiddleware_ReplaysResponse(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success":true,"data":{"id":1}}`))
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, newIdempotentRequest("retry-123", `{"seat_ids":[1]}`))
+
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, newIdempotentRequest("retry-123", `{"seat_ids":[1]}`))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyMiddleware_DifferentBody(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("retry-123", `{"seat_ids":[1]}`))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newIdempotentRequest("retry-123", `{"seat_ids":[2]}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestIdempotencyMiddleware_ServerErrorAllowsRetry(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("retry-123", `{}`))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newIdempotentRequest("retry-123", `{}`))
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestIdempotencyMiddleware_NoHeader(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/booking", strings.NewReader(`{}`))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddleware_BodyTooLarge(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newIdempotentRequest("retry-123", strings.Repeat("a", maxIdempotentRequestBytes+1)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotencyMiddleware_PanicReleasesKey(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	m := NewIdempotencyMiddleware(newMemoryIdempotencyService(), zap.NewNop())
+	h := m.Idempotent(next)
+
+	assert.Panics(t, func() {
+		h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("retry-123", `{}`))
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newIdempotentRequest("retry-123", `{}`))
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestIdempotencyMiddleware_CompleteFailsReleasesKey(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	store := newMemoryIdempotencyService()
+	store.completeErr = errors.New("database is down")
+	m := NewIdempotencyMiddleware(store, zap.NewNop())
+	h := m.Idempotent(next)
+
+	h.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("retry-123", `{}`))
+
+	assert.Empty(t, store.records)
+}
@@ -9,14 +9,25 @@ import (
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrSeatAlreadyTaken dikembalikan ketika kursi sudah dipesan untuk showtime yang sama
+var ErrSeatAlreadyTaken = errors.New("seat is already booked for this showtime")
+
+// ErrBookingStatusChanged dikembalikan ketika status booking berubah sejak dibaca
+// (misalnya sudah dibatalkan atau di-expire oleh request lain)
+var ErrBookingStatusChanged = errors.New("booking status has changed, please reload and try again")
+
 type BookingRepository interface {
-	Create(ctx context.Context, booking *domain.Booking) error
+	Reserve(ctx context.Context, booking *domain.Booking, now time.Time) error
 	GetByID(ctx context.Context, id int) (*domain.Booking, error)
+	GetByCode(ctx context.Context, code string) (*domain.Booking, error)
 	GetByUserID(ctx context.Context, userID int) ([]*domain.Booking, error)
-	Update(ctx context.Context, booking *domain.Booking) error
-	CheckSeatBooked(ctx context.Context, showtimeID, seatID int) (bool, error)
+	UpdateStatusIfPending(ctx context.Context, booking *domain.Booking, now time.Time) (bool, error)
+	ExpireOverdue(ctx context.Context, now time.Time) (int, error)
+	Cancel(ctx context.Context, booking *domain.Booking, now time.Time, refund func() error) error
+	MarkCheckedIn(ctx context.Context, bookingID int, at time.Time) (bool, error)
 }
 
 type bookingRepository struct {
@@ -27,23 +38,75 @@ func NewBookingRepository(db PgxPool) BookingRepository {
 	return &bookingRepository{db: db}
 }
 
-func (r *bookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
+// Reserve menyimpan booking beserta kursinya dalam satu transaksi, dengan waktu dibuat now.
+// Reservasi untuk showtime yang sama diserialisasi dengan advisory lock,
+// sehingga dua request bersamaan tidak bisa mengambil kursi yang sama.
+func (r *bookingRepository) Reserve(ctx context.Context, booking *domain.Booking, now time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock per showtime, otomatis dilepas saat commit/rollback
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('showtime_seats'), $1)", booking.ShowtimeID); err != nil {
+		return fmt.Errorf("failed to lock showtime: %w", err)
+	}
+
+	seatIDs := make([]int, 0, len(booking.Seats))
+	for _, seat := range booking.Seats {
+		seatIDs = append(seatIDs, seat.SeatID)
+	}
+
+	takenQuery := `
+		SELECT bs.seat_id
+		FROM booking_seats bs
+		WHERE bs.showtime_id = $1
+		  AND bs.seat_id = ANY($2)
+		  AND bs.released_at IS NULL
+	`
+
+	rows, err := tx.Query(ctx, takenQuery, booking.ShowtimeID, seatIDs)
+	if err != nil {
+		return fmt.Errorf("failed to check seat availability: %w", err)
+	}
+
+	var taken []int
+	for rows.Next() {
+		var seatID int
+		if err := rows.Scan(&seatID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan booked seat: %w", err)
+		}
+		taken = append(taken, seatID)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating booked seats: %w", err)
+	}
+
+	if len(taken) > 0 {
+		return fmt.Errorf("%w (seat_id: %v)", ErrSeatAlreadyTaken, taken)
+	}
+
 	query := `
-		INSERT INTO bookings (user_id, showtime_id, seat_id, booking_code, status, total_price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO bookings (user_id, showtime_id, booking_code, status, total_price, discount_amount, promo_code, expires_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id, created_at, updated_at
 	`
 
-	now := time.Now()
-	err := r.db.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		booking.UserID,
 		booking.ShowtimeID,
-		booking.SeatID,
 		booking.BookingCode,
 		booking.Status,
 		booking.TotalPrice,
+		booking.DiscountAmount,
+		booking.PromoCode,
+		booking.ExpiresAt,
 		now,
 		now,
 	).Scan(&booking.ID, &booking.CreatedAt, &booking.UpdatedAt)
@@ -52,43 +115,100 @@ func (r *bookingRepository) Create(ctx context.Context, booking *domain.Booking)
 		return fmt.Errorf("failed to create booking: %w", err)
 	}
 
+	seatQuery := `
+		INSERT INTO booking_seats (booking_id, showtime_id, seat_id, price, base_price, price_multiplier, price_rule_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	for _, seat := range booking.Seats {
+		seat.BookingID = booking.ID
+		seat.ShowtimeID = booking.ShowtimeID
+
+		var basePrice *domain.Money
+		var multiplier *float64
+		var ruleID *int
+		if seat.PriceBreakdown != nil {
+			basePrice = &seat.PriceBreakdown.BasePrice
+			multiplier = seat.PriceBreakdown.Multiplier
+			ruleID = seat.PriceBreakdown.RuleID
+		}
+
+		err = tx.QueryRow(ctx, seatQuery, seat.BookingID, seat.ShowtimeID, seat.SeatID, seat.Price, basePrice, multiplier, ruleID, now).
+			Scan(&seat.ID, &seat.CreatedAt)
+		if err != nil {
+			if isSeatConflict(err) {
+				return fmt.Errorf("%w (seat_id: %d)", ErrSeatAlreadyTaken, seat.SeatID)
+			}
+			return fmt.Errorf("failed to create booking seat: %w", err)
+		}
+	}
+
+	// Promo usage is recorded in the same transaction, so a capped code cannot be over-used
+	if redemption := booking.Redemption; redemption != nil {
+		redemption.UserID = booking.UserID
+		redemption.BookingID = booking.ID
+		if err := redeemPromotion(ctx, tx, redemption); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		if isSeatConflict(err) {
+			return ErrSeatAlreadyTaken
+		}
+		return fmt.Errorf("failed to commit booking: %w", err)
+	}
+
 	return nil
 }
 
 func (r *bookingRepository) GetByID(ctx context.Context, id int) (*domain.Booking, error) {
+	return r.getOne(ctx, "b.id = $1", id)
+}
+
+// GetByCode mengambil booking berdasarkan booking code (BK...)
+func (r *bookingRepository) GetByCode(ctx context.Context, code string) (*domain.Booking, error) {
+	return r.getOne(ctx, "b.booking_code = $1", code)
+}
+
+// getOne mengambil satu booking lengkap dengan showtime, cinema, movie, seats dan payment
+func (r *bookingRepository) getOne(ctx context.Context, condition string, arg interface{}) (*domain.Booking, error) {
 	query := `
 		SELECT
-			b.id, b.user_id, b.showtime_id, b.seat_id, b.booking_code, b.status, b.total_price, b.created_at, b.updated_at,
-			s.id, s.cinema_id, s.movie_id, s.show_date, s.show_time, s.price, s.created_at,
-			st.id, st.cinema_id, st.seat_row, st.seat_number, st.seat_type, st.created_at,
-			c.id, c.name, c.location, c.description, c.created_at,
+			b.id, b.user_id, b.showtime_id, b.booking_code, b.status, b.total_price, b.discount_amount, b.promo_code, b.expires_at, b.checked_in_at, b.created_at, b.updated_at,
+			s.id, s.cinema_id, s.screen_id, s.movie_id, s.show_date, s.show_time, s.ends_at, s.price, s.created_at,
+			c.id, c.name, c.location, c.description, c.timezone, c.created_at,
+			sc.id, sc.cinema_id, sc.name, sc.created_at,
 			m.id, m.title, m.description, m.duration, m.genre, m.poster_url, m.rating, m.created_at,
-			p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.created_at,
+			p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.refund_amount, p.refunded_at, p.provider_reference, p.created_at,
 			pm.id, pm.name, pm.code, pm.is_active, pm.created_at
 		FROM bookings b
 		JOIN showtimes s ON b.showtime_id = s.id
-		JOIN seats st ON b.seat_id = st.id
 		JOIN cinemas c ON s.cinema_id = c.id
+		JOIN screens sc ON s.screen_id = sc.id
 		JOIN movies m ON s.movie_id = m.id
 		LEFT JOIN payments p ON b.id = p.booking_id
 		LEFT JOIN payment_methods pm ON p.payment_method_id = pm.id
-		WHERE b.id = $1
-	`
+		WHERE ` + condition
 
 	var booking domain.Booking
 	var showtime domain.Showtime
-	var seat domain.Seat
 	var cinema domain.Cinema
+	var screen domain.Screen
 	var movie domain.Movie
 
 	// Payment fields nullable (LEFT JOIN)
 	var paymentID *int
 	var paymentBookingID *int
 	var paymentMethodID *int
-	var paymentAmount *float64
+	var paymentAmount *domain.Money
 	var paymentStatus *string
 	var paymentDetails *domain.PaymentDetails
 	var paymentPaidAt *time.Time
+	var paymentRefundAmount *domain.Money
+	var paymentRefundedAt *time.Time
+	var paymentProviderReference *string
 	var paymentCreatedAt *time.Time
 	var pmID *int
 	var pmName *string
@@ -96,34 +216,38 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int) (*domain.Bookin
 	var pmIsActive *bool
 	var pmCreatedAt *time.Time
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	err := r.db.QueryRow(ctx, query, arg).Scan(
 		&booking.ID,
 		&booking.UserID,
 		&booking.ShowtimeID,
-		&booking.SeatID,
 		&booking.BookingCode,
 		&booking.Status,
 		&booking.TotalPrice,
+		&booking.DiscountAmount,
+		&booking.PromoCode,
+		&booking.ExpiresAt,
+		&booking.CheckedInAt,
 		&booking.CreatedAt,
 		&booking.UpdatedAt,
 		&showtime.ID,
 		&showtime.CinemaID,
+		&showtime.ScreenID,
 		&showtime.MovieID,
 		&showtime.ShowDate,
 		&showtime.ShowTime,
+		&showtime.EndsAt,
 		&showtime.Price,
 		&showtime.CreatedAt,
-		&seat.ID,
-		&seat.CinemaID,
-		&seat.SeatRow,
-		&seat.SeatNumber,
-		&seat.SeatType,
-		&seat.CreatedAt,
 		&cinema.ID,
 		&cinema.Name,
 		&cinema.Location,
 		&cinema.Description,
+		&cinema.Timezone,
 		&cinema.CreatedAt,
+		&screen.ID,
+		&screen.CinemaID,
+		&screen.Name,
+		&screen.CreatedAt,
 		&movie.ID,
 		&movie.Title,
 		&movie.Description,
@@ -140,6 +264,9 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int) (*domain.Bookin
 		&paymentStatus,
 		&paymentDetails,
 		&paymentPaidAt,
+		&paymentRefundAmount,
+		&paymentRefundedAt,
+		&paymentProviderReference,
 		&paymentCreatedAt,
 		&pmID,
 		&pmName,
@@ -155,21 +282,26 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int) (*domain.Bookin
 		return nil, fmt.Errorf("failed to get booking: %w", err)
 	}
 
+	showtime.Timezone = cinema.Timezone
+	showtime.EndsAt = wallClockIn(showtime.EndsAt, showtime.TimeLocation())
 	showtime.Cinema = &cinema
+	showtime.Screen = &screen
 	showtime.Movie = &movie
 	booking.Showtime = &showtime
-	booking.Seat = &seat
 
 	// Build Payment object if exists
 	if paymentID != nil {
 		payment := &domain.Payment{
-			ID:              *paymentID,
-			BookingID:       *paymentBookingID,
-			PaymentMethodID: *paymentMethodID,
-			Amount:          *paymentAmount,
-			Status:          *paymentStatus,
-			PaidAt:          paymentPaidAt,
-			CreatedAt:       *paymentCreatedAt,
+			ID:                *paymentID,
+			BookingID:         *paymentBookingID,
+			PaymentMethodID:   *paymentMethodID,
+			Amount:            *paymentAmount,
+			Status:            *paymentStatus,
+			PaidAt:            paymentPaidAt,
+			RefundAmount:      paymentRefundAmount,
+			RefundedAt:        paymentRefundedAt,
+			ProviderReference: paymentProviderReference,
+			CreatedAt:         *paymentCreatedAt,
 		}
 
 		if paymentDetails != nil {
@@ -190,23 +322,27 @@ func (r *bookingRepository) GetByID(ctx context.Context, id int) (*domain.Bookin
 		booking.Payment = payment
 	}
 
+	if err := r.attachSeats(ctx, []*domain.Booking{&booking}); err != nil {
+		return nil, err
+	}
+
 	return &booking, nil
 }
 
 func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.Booking, error) {
 	query := `
 		SELECT
-			b.id, b.user_id, b.showtime_id, b.seat_id, b.booking_code, b.status, b.total_price, b.created_at, b.updated_at,
-			s.id, s.cinema_id, s.movie_id, s.show_date, s.show_time, s.price, s.created_at,
-			st.id, st.cinema_id, st.seat_row, st.seat_number, st.seat_type, st.created_at,
-			c.id, c.name, c.location, c.description, c.created_at,
+			b.id, b.user_id, b.showtime_id, b.booking_code, b.status, b.total_price, b.discount_amount, b.promo_code, b.expires_at, b.checked_in_at, b.created_at, b.updated_at,
+			s.id, s.cinema_id, s.screen_id, s.movie_id, s.show_date, s.show_time, s.ends_at, s.price, s.created_at,
+			c.id, c.name, c.location, c.description, c.timezone, c.created_at,
+			sc.id, sc.cinema_id, sc.name, sc.created_at,
 			m.id, m.title, m.description, m.duration, m.genre, m.poster_url, m.rating, m.created_at,
-			p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.created_at,
+			p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.refund_amount, p.refunded_at, p.provider_reference, p.created_at,
 			pm.id, pm.name, pm.code, pm.is_active, pm.created_at
 		FROM bookings b
 		JOIN showtimes s ON b.showtime_id = s.id
-		JOIN seats st ON b.seat_id = st.id
 		JOIN cinemas c ON s.cinema_id = c.id
+		JOIN screens sc ON s.screen_id = sc.id
 		JOIN movies m ON s.movie_id = m.id
 		LEFT JOIN payments p ON b.id = p.booking_id
 		LEFT JOIN payment_methods pm ON p.payment_method_id = pm.id
@@ -224,18 +360,21 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*dom
 	for rows.Next() {
 		var booking domain.Booking
 		var showtime domain.Showtime
-		var seat domain.Seat
 		var cinema domain.Cinema
+		var screen domain.Screen
 		var movie domain.Movie
 
 		// Payment fields nullable
 		var paymentID *int
 		var paymentBookingID *int
 		var paymentMethodID *int
-		var paymentAmount *float64
+		var paymentAmount *domain.Money
 		var paymentStatus *string
 		var paymentDetails *domain.PaymentDetails
 		var paymentPaidAt *time.Time
+		var paymentRefundAmount *domain.Money
+		var paymentRefundedAt *time.Time
+		var paymentProviderReference *string
 		var paymentCreatedAt *time.Time
 		var pmID *int
 		var pmName *string
@@ -247,30 +386,34 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*dom
 			&booking.ID,
 			&booking.UserID,
 			&booking.ShowtimeID,
-			&booking.SeatID,
 			&booking.BookingCode,
 			&booking.Status,
 			&booking.TotalPrice,
+			&booking.DiscountAmount,
+			&booking.PromoCode,
+			&booking.ExpiresAt,
+			&booking.CheckedInAt,
 			&booking.CreatedAt,
 			&booking.UpdatedAt,
 			&showtime.ID,
 			&showtime.CinemaID,
+			&showtime.ScreenID,
 			&showtime.MovieID,
 			&showtime.ShowDate,
 			&showtime.ShowTime,
+			&showtime.EndsAt,
 			&showtime.Price,
 			&showtime.CreatedAt,
-			&seat.ID,
-			&seat.CinemaID,
-			&seat.SeatRow,
-			&seat.SeatNumber,
-			&seat.SeatType,
-			&seat.CreatedAt,
 			&cinema.ID,
 			&cinema.Name,
 			&cinema.Location,
 			&cinema.Description,
+			&cinema.Timezone,
 			&cinema.CreatedAt,
+			&screen.ID,
+			&screen.CinemaID,
+			&screen.Name,
+			&screen.CreatedAt,
 			&movie.ID,
 			&movie.Title,
 			&movie.Description,
@@ -287,6 +430,9 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*dom
 			&paymentStatus,
 			&paymentDetails,
 			&paymentPaidAt,
+			&paymentRefundAmount,
+			&paymentRefundedAt,
+			&paymentProviderReference,
 			&paymentCreatedAt,
 			&pmID,
 			&pmName,
@@ -299,21 +445,26 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*dom
 			return nil, fmt.Errorf("failed to scan booking: %w", err)
 		}
 
+		showtime.Timezone = cinema.Timezone
+		showtime.EndsAt = wallClockIn(showtime.EndsAt, showtime.TimeLocation())
 		showtime.Cinema = &cinema
+		showtime.Screen = &screen
 		showtime.Movie = &movie
 		booking.Showtime = &showtime
-		booking.Seat = &seat
 
 		// Build Payment object if exists
 		if paymentID != nil {
 			payment := &domain.Payment{
-				ID:              *paymentID,
-				BookingID:       *paymentBookingID,
-				PaymentMethodID: *paymentMethodID,
-				Amount:          *paymentAmount,
-				Status:          *paymentStatus,
-				PaidAt:          paymentPaidAt,
-				CreatedAt:       *paymentCreatedAt,
+				ID:                *paymentID,
+				BookingID:         *paymentBookingID,
+				PaymentMethodID:   *paymentMethodID,
+				Amount:            *paymentAmount,
+				Status:            *paymentStatus,
+				PaidAt:            paymentPaidAt,
+				RefundAmount:      paymentRefundAmount,
+				RefundedAt:        paymentRefundedAt,
+				ProviderReference: paymentProviderReference,
+				CreatedAt:         *paymentCreatedAt,
 			}
 
 			if paymentDetails != nil {
@@ -337,39 +488,257 @@ func (r *bookingRepository) GetByUserID(ctx context.Context, userID int) ([]*dom
 		bookings = append(bookings, &booking)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookings: %w", err)
+	}
+
+	if err := r.attachSeats(ctx, bookings); err != nil {
+		return nil, err
+	}
+
 	return bookings, nil
 }
 
-func (r *bookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
+// UpdateStatusIfPending mengubah status booking hanya jika masih pending. Untuk status cancelled/expired,
+// kursi dan kuota promonya ikut dilepas dalam statement yang sama. Mengembalikan false jika booking
+// sudah tidak pending (mis. sudah di-expire job atau dikonfirmasi webhook lebih dulu).
+func (r *bookingRepository) UpdateStatusIfPending(ctx context.Context, booking *domain.Booking, now time.Time) (bool, error) {
 	query := `
-		UPDATE bookings
-		SET status = $1, updated_at = $2
-		WHERE id = $3
+		WITH updated AS (
+			UPDATE bookings
+			SET status = $1, updated_at = $2
+			WHERE id = $3 AND status = 'pending'
+			RETURNING id, status
+		), released_promo AS (
+			UPDATE promotion_redemptions pr
+			SET released_at = $2
+			FROM updated u
+			WHERE pr.booking_id = u.id
+			  AND u.status IN ('cancelled', 'expired')
+			  AND pr.released_at IS NULL
+		), released_seats AS (
+			UPDATE booking_seats bs
+			SET released_at = $2
+			FROM updated u
+			WHERE bs.booking_id = u.id
+			  AND u.status IN ('cancelled', 'expired')
+			  AND bs.released_at IS NULL
+		)
+		SELECT COUNT(*) FROM updated
 	`
 
-	_, err := r.db.Exec(ctx, query, booking.Status, time.Now(), booking.ID)
+	var updated int
+	if err := r.db.QueryRow(ctx, query, booking.Status, now, booking.ID).Scan(&updated); err != nil {
+		return false, fmt.Errorf("failed to update booking: %w", err)
+	}
+
+	return updated == 1, nil
+}
+
+// Cancel membatalkan booking, melepas kursi dan promonya, dan mencatat refund
+// pada payment (jika booking.Payment berstatus refunded) dalam satu transaksi.
+// Booking hanya dibatalkan jika statusnya masih sama dengan booking.Status;
+// refund (boleh nil) baru dijalankan setelah pembatalan menang, dan jika gagal
+// seluruh pembatalan di-rollback.
+func (r *bookingRepository) Cancel(ctx context.Context, booking *domain.Booking, now time.Time, refund func() error) error {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to update booking: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
+
+	// The status guard also row-locks the booking, so concurrent cancels serialize
+	// and only the first one gets past this point.
+	query := `
+		UPDATE bookings
+		SET status = 'cancelled', updated_at = $1
+		WHERE id = $2 AND status = $3 AND status IN ('pending', 'confirmed')
+	`
+	result, err := tx.Exec(ctx, query, now, booking.ID, booking.Status)
+	if err != nil {
+		return fmt.Errorf("failed to cancel booking: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrBookingStatusChanged
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE booking_seats SET released_at = $1 WHERE booking_id = $2 AND released_at IS NULL", now, booking.ID); err != nil {
+		return fmt.Errorf("failed to release booking seats: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE promotion_redemptions SET released_at = $1 WHERE booking_id = $2 AND released_at IS NULL", now, booking.ID); err != nil {
+		return fmt.Errorf("failed to release promotion: %w", err)
+	}
+
+	if refund != nil {
+		if err := refund(); err != nil {
+			return err
+		}
+	}
+
+	if payment := booking.Payment; payment != nil && payment.Status == "refunded" {
+		query := `
+			UPDATE payments
+			SET status = 'refunded', refund_amount = $1, refunded_at = $2
+			WHERE id = $3
+		`
+		if _, err := tx.Exec(ctx, query, payment.RefundAmount, payment.RefundedAt, payment.ID); err != nil {
+			return fmt.Errorf("failed to refund payment: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit cancellation: %w", err)
+	}
+
+	booking.Status = "cancelled"
+	booking.UpdatedAt = now
 
 	return nil
 }
 
-func (r *bookingRepository) CheckSeatBooked(ctx context.Context, showtimeID, seatID int) (bool, error) {
+// ExpireOverdue mengubah booking pending yang melewati batas bayar menjadi expired
+// dan melepas kursi serta kuota promonya. Booking yang pembayarannya masih diproses provider
+// (payment pending) tidak di-expire; webhook provider yang menentukan hasil akhirnya.
+// Mengembalikan jumlah booking yang di-expire.
+func (r *bookingRepository) ExpireOverdue(ctx context.Context, now time.Time) (int, error) {
+	query := `
+		WITH expired AS (
+			UPDATE bookings b
+			SET status = 'expired', updated_at = $1
+			WHERE b.status = 'pending'
+			  AND b.expires_at IS NOT NULL
+			  AND b.expires_at <= $1
+			  AND NOT EXISTS (
+				SELECT 1 FROM payments p
+				WHERE p.booking_id = b.id AND p.status = 'pending'
+			  )
+			RETURNING b.id
+		), released AS (
+			UPDATE booking_seats bs
+			SET released_at = $1
+			FROM expired e
+			WHERE bs.booking_id = e.id
+			  AND bs.released_at IS NULL
+			RETURNING bs.id
+		), released_promo AS (
+			UPDATE promotion_redemptions pr
+			SET released_at = $1
+			FROM expired e
+			WHERE pr.booking_id = e.id
+			  AND pr.released_at IS NULL
+		)
+		SELECT COUNT(*) FROM expired
+	`
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, now).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to expire bookings: %w", err)
+	}
+
+	return count, nil
+}
+
+// attachSeats mengisi daftar kursi untuk setiap booking
+func (r *bookingRepository) attachSeats(ctx context.Context, bookings []*domain.Booking) error {
+	if len(bookings) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(bookings))
+	byID := make(map[int]*domain.Booking, len(bookings))
+	for _, booking := range bookings {
+		ids = append(ids, booking.ID)
+		byID[booking.ID] = booking
+	}
+
 	query := `
-		SELECT EXISTS(
-			SELECT 1 FROM bookings
-			WHERE showtime_id = $1
-			  AND seat_id = $2
-			  AND status IN ('pending', 'confirmed')
+		SELECT
+			bs.id, bs.booking_id, bs.showtime_id, bs.seat_id, bs.price, bs.released_at, bs.created_at,
+			bs.base_price, bs.price_multiplier, bs.price_rule_id,
+			st.id, st.cinema_id, st.screen_id, st.seat_row, st.seat_number, st.seat_type, st.created_at
+		FROM booking_seats bs
+		JOIN seats st ON bs.seat_id = st.id
+		WHERE bs.booking_id = ANY($1)
+		ORDER BY bs.booking_id, st.seat_row, st.seat_number
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get booking seats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bookingSeat domain.BookingSeat
+		var seat domain.Seat
+		var basePrice *domain.Money
+		var multiplier *float64
+		var ruleID *int
+
+		err := rows.Scan(
+			&bookingSeat.ID,
+			&bookingSeat.BookingID,
+			&bookingSeat.ShowtimeID,
+			&bookingSeat.SeatID,
+			&bookingSeat.Price,
+			&bookingSeat.ReleasedAt,
+			&bookingSeat.CreatedAt,
+			&basePrice,
+			&multiplier,
+			&ruleID,
+			&seat.ID,
+			&seat.CinemaID,
+			&seat.ScreenID,
+			&seat.SeatRow,
+			&seat.SeatNumber,
+			&seat.SeatType,
+			&seat.CreatedAt,
 		)
+		if err != nil {
+			return fmt.Errorf("failed to scan booking seat: %w", err)
+		}
+
+		bookingSeat.Seat = &seat
+		if basePrice != nil {
+			bookingSeat.PriceBreakdown = &domain.SeatPrice{
+				SeatType:   seat.SeatType,
+				BasePrice:  *basePrice,
+				Multiplier: multiplier,
+				RuleID:     ruleID,
+				Price:      bookingSeat.Price,
+			}
+		}
+		if booking, ok := byID[bookingSeat.BookingID]; ok {
+			booking.Seats = append(booking.Seats, &bookingSeat)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating booking seats: %w", err)
+	}
+
+	return nil
+}
+
+// isSeatConflict mengecek apakah error berasal dari unique constraint kursi
+func isSeatConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.TableName == "booking_seats"
+}
+
+// MarkCheckedIn mencatat waktu check-in. Mengembalikan false jika booking sudah check-in sebelumnya.
+func (r *bookingRepository) MarkCheckedIn(ctx context.Context, bookingID int, at time.Time) (bool, error) {
+	query := `
+		UPDATE bookings
+		SET checked_in_at = $1, updated_at = $1
+		WHERE id = $2 AND checked_in_at IS NULL
 	`
 
-	var exists bool
-	err := r.db.QueryRow(ctx, query, showtimeID, seatID).Scan(&exists)
+	result, err := r.db.Exec(ctx, query, at, bookingID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check seat booking: %w", err)
+		return false, fmt.Errorf("failed to check in booking: %w", err)
 	}
 
-	return exists, nil
+	return result.RowsAffected() == 1, nil
 }
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bookingFixture struct {
+	pool       *pgxpool.Pool
+	suffix     int64
+	userID     int
+	showtimeID int
+	seatID     int
+}
+
+// newBookingFixture membuat data minimal (user, cinema, movie, showtime, seat) di database test.
+// Test yang memakainya butuh PostgreSQL sungguhan dengan semua migration sudah dijalankan:
+// TEST_DATABASE_DSN="postgres://..." go test ./internal/repository/ -run Integration
+func newBookingFixture(t *testing.T) *bookingFixture {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set, skipping integration test")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+
+	f := &bookingFixture{pool: pool, suffix: time.Now().UnixNano()}
+
+	var cinemaID, screenID, movieID int
+	require.NoError(t, pool.QueryRow(ctx,
+		"INSERT INTO users (username, email, password_hash) VALUES ($1, $2, 'x') RETURNING id",
+		fmt.Sprintf("race_%d", f.suffix), fmt.Sprintf("race_%d@example.com", f.suffix),
+	).Scan(&f.userID))
+	require.NoError(t, pool.QueryRow(ctx,
+		"INSERT INTO cinemas (name, location) VALUES ($1, 'Test') RETURNING id",
+		fmt.Sprintf("Race Cinema %d", f.suffix),
+	).Scan(&cinemaID))
+	require.NoError(t, pool.QueryRow(ctx,
+		"INSERT INTO screens (cinema_id, name) VALUES ($1, 'Studio 1') RETURNING id",
+		cinemaID,
+	).Scan(&screenID))
+	require.NoError(t, pool.QueryRow(ctx,
+		"INSERT INTO movies (title, duration) VALUES ($1, 120) RETURNING id",
+		fmt.Sprintf("Race Movie %d", f.suffix),
+	).Scan(&movieID))
+	require.NoError(t, pool.QueryRow(ctx,
+		`INSERT INTO showtimes (cinema_id, screen_id, movie_id, show_date, show_time, ends_at, cleaning_buffer_minutes, price)
+		 VALUES ($1, $2, $3, CURRENT_DATE + 1, '19:00', CURRENT_DATE + 1 + TIME '21:00', 15, 50000) RETURNING id`,
+		cinemaID, screenID, movieID,
+	).Scan(&f.showtimeID))
+	require.NoError(t, pool.QueryRow(ctx,
+		"INSERT INTO seats (cinema_id, screen_id, seat_row, seat_number) VALUES ($1, $2, 'A', 1) RETURNING id",
+		cinemaID, screenID,
+	).Scan(&f.seatID))
+
+	t.Cleanup(func() {
+		pool.Exec(ctx, "DELETE FROM cinemas WHERE id = $1", cinemaID)
+		pool.Exec(ctx, "DELETE FROM movies WHERE id = $1", movieID)
+		pool.Exec(ctx, "DELETE FROM users WHERE id = $1", f.userID)
+		pool.Close()
+	})
+
+	return f
+}
+
+func (f *bookingFixture) newBooking(i int) *domain.Booking {
+	return &domain.Booking{
+		UserID:      f.userID,
+		ShowtimeID:  f.showtimeID,
+		BookingCode: fmt.Sprintf("BKR%d%02d", f.suffix%1000000000, i),
+		Status:      "pending",
+		TotalPrice:  domain.NewMoney(50000),
+		Seats:       []*domain.BookingSeat{{SeatID: f.seatID, Price: domain.NewMoney(50000)}},
+	}
+}
+
+func TestIntegrationBookingRepository_Reserve_ConcurrentSameSeat(t *testing.T) {
+	f := newBookingFixture(t)
+	ctx := context.Background()
+
+	repo := NewBookingRepository(f.pool)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var winners, conflicts int
+	var unexpected []error
+
+	start := make(chan struct{})
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			err := repo.Reserve(ctx, f.newBooking(i), time.Now())
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				winners++
+			case errors.Is(err, ErrSeatAlreadyTaken):
+				conflicts++
+			default:
+				unexpected = append(unexpected, err)
+			}
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	assert.Empty(t, unexpected)
+	assert.Equal(t, 1, winners)
+	assert.Equal(t, attempts-1, conflicts)
+}
+
+func TestIntegrationBookingRepository_Reserve_AfterCancel(t *testing.T) {
+	f := newBookingFixture(t)
+	ctx := context.Background()
+	repo := NewBookingRepository(f.pool)
+
+	first := f.newBooking(1)
+	require.NoError(t, repo.Reserve(ctx, first, time.Now()))
+
+	assert.ErrorIs(t, repo.Reserve(ctx, f.newBooking(2), time.Now()), ErrSeatAlreadyTaken)
+
+	first.Status = "cancelled"
+	updated, err := repo.UpdateStatusIfPending(ctx, first, time.Now())
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	var booked bool
+	require.NoError(t, f.pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM booking_seats WHERE showtime_id = $1 AND seat_id = $2 AND released_at IS NULL)",
+		f.showtimeID, f.seatID,
+	).Scan(&booked))
+	assert.False(t, booked)
+
+	assert.NoError(t, repo.Reserve(ctx, f.newBooking(3), time.Now()))
+}
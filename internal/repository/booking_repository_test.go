@@ -8,12 +8,13 @@ import (
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestBookingRepository_Create(t *testing.T) {
+func TestBookingRepository_Reserve(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
@@ -23,75 +24,244 @@ func TestBookingRepository_Create(t *testing.T) {
 	booking := &domain.Booking{
 		UserID:      1,
 		ShowtimeID:  1,
-		SeatID:      10,
 		BookingCode: "BK123456",
 		Status:      "pending",
-		TotalPrice:  50000,
+		TotalPrice:  domain.NewMoney(112500),
+		Seats: []*domain.BookingSeat{
+			{SeatID: 10, Price: domain.NewMoney(62500), PriceBreakdown: &domain.SeatPrice{SeatType: "premium", BasePrice: domain.NewMoney(50000), Multiplier: ptr(1.25), RuleID: ptr(2), Price: domain.NewMoney(62500)}},
+			{SeatID: 11, Price: domain.NewMoney(50000)},
+		},
 	}
 
 	now := time.Now()
 	rows := pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
 		AddRow(1, now, now)
 
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, nil)
 	mock.ExpectQuery("INSERT INTO bookings").
 		WithArgs(
 			booking.UserID,
 			booking.ShowtimeID,
-			booking.SeatID,
 			booking.BookingCode,
 			booking.Status,
 			booking.TotalPrice,
-			pgxmock.AnyArg(),
-			pgxmock.AnyArg(),
+			booking.DiscountAmount,
+			booking.PromoCode,
+			booking.ExpiresAt,
+			now,
+			now,
 		).
 		WillReturnRows(rows)
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 10, domain.NewMoney(62500), ptr(domain.NewMoney(50000)), ptr(1.25), ptr(2), now).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(100, now))
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 11, domain.NewMoney(50000), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(101, now))
+	mock.ExpectCommit()
 
-	err = repo.Create(context.Background(), booking)
+	err = repo.Reserve(context.Background(), booking, now)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, booking.ID)
+	assert.Equal(t, 1, booking.Seats[0].BookingID)
+	assert.Equal(t, 101, booking.Seats[1].ID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestBookingRepository_CheckSeatBooked_True(t *testing.T) {
+func TestBookingRepository_Reserve_SeatInsertFails(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
 
 	repo := NewBookingRepository(mock)
 
-	rows := pgxmock.NewRows([]string{"exists"}).AddRow(true)
-	mock.ExpectQuery("SELECT EXISTS").
-		WithArgs(1, 10).
-		WillReturnRows(rows)
+	booking := &domain.Booking{
+		UserID:      1,
+		ShowtimeID:  1,
+		BookingCode: "BK123456",
+		Status:      "pending",
+		TotalPrice:  domain.NewMoney(100000),
+		Seats: []*domain.BookingSeat{
+			{SeatID: 10, Price: domain.NewMoney(50000)},
+			{SeatID: 11, Price: domain.NewMoney(50000)},
+		},
+	}
+
+	now := time.Now()
 
-	isBooked, err := repo.CheckSeatBooked(context.Background(), 1, 10)
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, nil)
+	mock.ExpectQuery("INSERT INTO bookings").
+		WithArgs(1, 1, "BK123456", "pending", domain.NewMoney(100000), domain.NewMoney(0), (*string)(nil), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 10, domain.NewMoney(50000), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(100, now))
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 11, domain.NewMoney(50000), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
 
-	assert.NoError(t, err)
-	assert.True(t, isBooked)
+	err = repo.Reserve(context.Background(), booking, now)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create booking seat")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestBookingRepository_CheckSeatBooked_False(t *testing.T) {
+// expectSeatLock mengatur ekspektasi advisory lock dan pengecekan kursi di awal Reserve
+func expectSeatLock(mock pgxmock.PgxPoolIface, booking *domain.Booking, takenSeatIDs []int) {
+	seatIDs := make([]int, 0, len(booking.Seats))
+	for _, seat := range booking.Seats {
+		seatIDs = append(seatIDs, seat.SeatID)
+	}
+
+	taken := pgxmock.NewRows([]string{"seat_id"})
+	for _, seatID := range takenSeatIDs {
+		taken.AddRow(seatID)
+	}
+
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WithArgs(booking.ShowtimeID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectQuery("SELECT bs.seat_id FROM booking_seats bs").
+		WithArgs(booking.ShowtimeID, seatIDs).
+		WillReturnRows(taken)
+}
+
+func TestBookingRepository_Reserve_SeatTaken(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
 
 	repo := NewBookingRepository(mock)
 
-	rows := pgxmock.NewRows([]string{"exists"}).AddRow(false)
-	mock.ExpectQuery("SELECT EXISTS").
-		WithArgs(1, 10).
-		WillReturnRows(rows)
+	booking := &domain.Booking{
+		UserID:      1,
+		ShowtimeID:  1,
+		BookingCode: "BK123456",
+		Status:      "pending",
+		TotalPrice:  domain.NewMoney(100000),
+		Seats: []*domain.BookingSeat{
+			{SeatID: 10, Price: domain.NewMoney(50000)},
+			{SeatID: 11, Price: domain.NewMoney(50000)},
+		},
+	}
+
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, []int{11})
+	mock.ExpectRollback()
+
+	err = repo.Reserve(context.Background(), booking, time.Now())
+
+	assert.ErrorIs(t, err, ErrSeatAlreadyTaken)
+	assert.Contains(t, err.Error(), "11")
+	assert.Equal(t, 0, booking.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Reserve_PromotionExhausted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+
+	code := "HEMAT20"
+	booking := &domain.Booking{
+		UserID:         5,
+		ShowtimeID:     1,
+		BookingCode:    "BK123456",
+		Status:         "pending",
+		TotalPrice:     domain.NewMoney(40000),
+		DiscountAmount: domain.NewMoney(10000),
+		PromoCode:      &code,
+		Redemption:     testRedemption(),
+		Seats: []*domain.BookingSeat{
+			{SeatID: 10, Price: domain.NewMoney(50000)},
+		},
+	}
+
+	now := time.Now()
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, nil)
+	mock.ExpectQuery("INSERT INTO bookings").
+		WithArgs(5, 1, "BK123456", "pending", domain.NewMoney(40000), domain.NewMoney(10000), &code, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 10, domain.NewMoney(50000), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(100, now))
+	expectRedemptionUsage(mock, ptr(100), nil, 100, 0)
+	mock.ExpectRollback()
+
+	err = repo.Reserve(context.Background(), booking, now)
+
+	assert.ErrorIs(t, err, ErrPromotionExhausted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Reserve_UniqueViolation(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+
+	booking := &domain.Booking{
+		UserID:      1,
+		ShowtimeID:  1,
+		BookingCode: "BK123456",
+		Status:      "pending",
+		TotalPrice:  domain.NewMoney(50000),
+		Seats:       []*domain.BookingSeat{{SeatID: 10, Price: domain.NewMoney(50000)}},
+	}
+
+	now := time.Now()
+	conflict := &pgconn.PgError{Code: "23505", TableName: "booking_seats"}
+
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, nil)
+	mock.ExpectQuery("INSERT INTO bookings").
+		WithArgs(1, 1, "BK123456", "pending", domain.NewMoney(50000), domain.NewMoney(0), (*string)(nil), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(1, now, now))
+	mock.ExpectQuery("INSERT INTO booking_seats").
+		WithArgs(1, 1, 10, domain.NewMoney(50000), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnError(conflict)
+	mock.ExpectRollback()
+
+	err = repo.Reserve(context.Background(), booking, now)
+
+	assert.ErrorIs(t, err, ErrSeatAlreadyTaken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_UpdateStatusIfPending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
 
-	isBooked, err := repo.CheckSeatBooked(context.Background(), 1, 10)
+	repo := NewBookingRepository(mock)
+
+	booking := &domain.Booking{
+		ID:     1,
+		Status: "confirmed",
+	}
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE bookings (.+) WHERE id = \$3 AND status = 'pending'`).
+		WithArgs(booking.Status, now, booking.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+	updated, err := repo.UpdateStatusIfPending(context.Background(), booking, now)
 
 	assert.NoError(t, err)
-	assert.False(t, isBooked)
+	assert.True(t, updated)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestBookingRepository_Update(t *testing.T) {
+func TestBookingRepository_UpdateStatusIfPending_NotPending(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
@@ -103,13 +273,208 @@ func TestBookingRepository_Update(t *testing.T) {
 		Status: "confirmed",
 	}
 
+	now := time.Now()
+
+	// Booking already expired by the background job
+	mock.ExpectQuery("UPDATE bookings").
+		WithArgs(booking.Status, now, booking.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+
+	updated, err := repo.UpdateStatusIfPending(context.Background(), booking, now)
+
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_UpdateStatusIfPending_CancelledReleasesSeats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+
+	booking := &domain.Booking{
+		ID:     1,
+		Status: "cancelled",
+	}
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE bookings (.+) UPDATE booking_seats bs\s+SET released_at`).
+		WithArgs(booking.Status, now, booking.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+	updated, err := repo.UpdateStatusIfPending(context.Background(), booking, now)
+
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_ExpireOverdue(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE bookings b SET status = 'expired'(.+)AND NOT EXISTS \( SELECT 1 FROM payments p WHERE p.booking_id = b.id AND p.status = 'pending' \)(.+)UPDATE booking_seats bs SET released_at`).
+		WithArgs(now).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := repo.ExpireOverdue(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_ExpireOverdue_Error(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	mock.ExpectQuery("UPDATE bookings").
+		WithArgs(now).
+		WillReturnError(assert.AnError)
+
+	count, err := repo.ExpireOverdue(context.Background(), now)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Cancel_WithRefund(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+
+	now := time.Now()
+	refundAmount := domain.NewMoney(37500)
+	booking := &domain.Booking{
+		ID:      1,
+		Status:  "confirmed",
+		Payment: &domain.Payment{ID: 5, Status: "success"},
+	}
+	refund := func() error {
+		booking.Payment.Status = "refunded"
+		booking.Payment.RefundAmount = &refundAmount
+		booking.Payment.RefundedAt = &now
+		return nil
+	}
+
+	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE bookings").
-		WithArgs(booking.Status, pgxmock.AnyArg(), booking.ID).
+		WithArgs(now, 1, "confirmed").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE booking_seats SET released_at").
+		WithArgs(now, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec("UPDATE promotion_redemptions SET released_at").
+		WithArgs(now, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("UPDATE payments SET status = 'refunded'").
+		WithArgs(&refundAmount, &now, 5).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
 
-	err = repo.Update(context.Background(), booking)
+	err = repo.Cancel(context.Background(), booking, now, refund)
 
 	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", booking.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Cancel_ReleaseFails(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	booking := &domain.Booking{ID: 1, Status: "pending"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE bookings").
+		WithArgs(pgxmock.AnyArg(), 1, "pending").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE booking_seats SET released_at").
+		WithArgs(pgxmock.AnyArg(), 1).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err = repo.Cancel(context.Background(), booking, now, func() error {
+		t.Fatal("refund must not run when the cancellation fails")
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, "pending", booking.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Cancel_StatusChanged(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	booking := &domain.Booking{ID: 1, Status: "confirmed", Payment: &domain.Payment{ID: 5, Status: "success"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE bookings").
+		WithArgs(pgxmock.AnyArg(), 1, "confirmed").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	err = repo.Cancel(context.Background(), booking, now, func() error {
+		t.Fatal("refund must not run when another request already cancelled the booking")
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrBookingStatusChanged)
+	assert.Equal(t, "confirmed", booking.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_Cancel_RefundFailsRollsBack(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	booking := &domain.Booking{ID: 1, Status: "confirmed", Payment: &domain.Payment{ID: 5, Status: "success"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE bookings").
+		WithArgs(pgxmock.AnyArg(), 1, "confirmed").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE booking_seats SET released_at").
+		WithArgs(pgxmock.AnyArg(), 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectExec("UPDATE promotion_redemptions SET released_at").
+		WithArgs(pgxmock.AnyArg(), 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectRollback()
+
+	err = repo.Cancel(context.Background(), booking, now, func() error {
+		return assert.AnError
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, "confirmed", booking.Status)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -122,30 +487,97 @@ func TestBookingRepository_GetByID(t *testing.T) {
 	now := time.Now()
 
 	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "showtime_id", "seat_id", "booking_code", "status", "total_price", "created_at", "updated_at",
-		"showtime_id", "cinema_id", "movie_id", "show_date", "show_time", "price", "showtime_created_at",
-		"seat_id", "cinema_id", "seat_row", "seat_number", "seat_type", "seat_created_at",
-		"cinema_id", "name", "location", "description", "cinema_created_at",
+		"id", "user_id", "showtime_id", "booking_code", "status", "total_price", "discount_amount", "promo_code", "expires_at", "checked_in_at", "created_at", "updated_at",
+		"showtime_id", "cinema_id", "screen_id", "movie_id", "show_date", "show_time", "ends_at", "price", "showtime_created_at",
+		"cinema_id", "name", "location", "description", "timezone", "cinema_created_at",
+		"screen_id", "screen_cinema_id", "screen_name", "screen_created_at",
 		"movie_id", "title", "description", "duration", "genre", "poster_url", "rating", "movie_created_at",
-		"payment_id", "booking_id", "payment_method_id", "amount", "payment_status", "payment_details", "paid_at", "payment_created_at",
+		"payment_id", "booking_id", "payment_method_id", "amount", "payment_status", "payment_details", "paid_at", "refund_amount", "refunded_at", "provider_reference", "payment_created_at",
 		"pm_id", "pm_name", "code", "is_active", "pm_created_at",
 	}).AddRow(
-		1, 1, 10, 20, "BK123", "pending", 50000.0, now, now, // Booking
-		10, 1, 5, now, now, 50000.0, now, // Showtime
-		20, 1, "A", 1, "regular", now, // Seat
-		1, "CGV Grand Indonesia", "Jakarta", "Premium", now, // Cinema
+		1, 1, 10, "BK123", "pending", domain.NewMoney(50000), domain.Money(0), nil, &now, nil, now, now, // Booking
+		10, 1, 3, 5, now, now, now, domain.NewMoney(50000), now, // Showtime
+		1, "CGV Grand Indonesia", "Jakarta", "Premium", domain.TimezoneWIB, now, // Cinema
+		3, 1, "Studio 1", now, // Screen
 		5, "Avengers", "Action", 120, "Action", "url", "PG-13", now, // Movie
-		nil, nil, nil, nil, nil, nil, nil, nil, // Payment (Ganti AnyArg jadi nil)
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, // Payment (Ganti AnyArg jadi nil)
 		nil, nil, nil, nil, nil, // Payment Method (Ganti AnyArg jadi nil)
 	)
 
+	seatRows := pgxmock.NewRows([]string{
+		"id", "booking_id", "showtime_id", "seat_id", "price", "released_at", "created_at",
+		"base_price", "price_multiplier", "price_rule_id",
+		"seat_id", "cinema_id", "screen_id", "seat_row", "seat_number", "seat_type", "seat_created_at",
+	}).
+		AddRow(100, 1, 10, 20, domain.NewMoney(25000), nil, now, ptr(domain.NewMoney(20000)), ptr(1.25), ptr(2), 20, 1, 3, "A", 1, "premium", now).
+		AddRow(101, 1, 10, 21, domain.NewMoney(25000), nil, now, nil, nil, nil, 21, 1, 3, "A", 2, "regular", now)
+
 	mock.ExpectQuery("SELECT (.+) FROM bookings b").WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM booking_seats bs").WithArgs([]int{1}).WillReturnRows(seatRows)
 
 	booking, err := repo.GetByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, booking)
 	assert.Equal(t, 1, booking.ID)
+	assert.Len(t, booking.Seats, 2)
+	assert.Equal(t, "A", booking.Seats[1].Seat.SeatRow)
+	assert.Equal(t, 2, booking.Seats[1].Seat.SeatNumber)
+	assert.Equal(t, domain.NewMoney(20000), booking.Seats[0].PriceBreakdown.BasePrice)
+	assert.Equal(t, "premium", booking.Seats[0].PriceBreakdown.SeatType)
+	assert.Nil(t, booking.Seats[1].PriceBreakdown)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_GetByCode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	rows := pgxmock.NewRows([]string{
+		"id", "user_id", "showtime_id", "booking_code", "status", "total_price", "discount_amount", "promo_code", "expires_at", "checked_in_at", "created_at", "updated_at",
+		"showtime_id", "cinema_id", "screen_id", "movie_id", "show_date", "show_time", "ends_at", "price", "showtime_created_at",
+		"cinema_id", "name", "location", "description", "timezone", "cinema_created_at",
+		"screen_id", "screen_cinema_id", "screen_name", "screen_created_at",
+		"movie_id", "title", "description", "duration", "genre", "poster_url", "rating", "movie_created_at",
+		"payment_id", "booking_id", "payment_method_id", "amount", "payment_status", "payment_details", "paid_at", "refund_amount", "refunded_at", "provider_reference", "payment_created_at",
+		"pm_id", "pm_name", "code", "is_active", "pm_created_at",
+	}).AddRow(
+		1, 1, 10, "BK123", "pending", domain.NewMoney(50000), domain.Money(0), nil, &now, nil, now, now, // Booking
+		10, 1, 3, 5, now, now, now, domain.NewMoney(50000), now, // Showtime
+		1, "CGV Grand Indonesia", "Jakarta", "Premium", domain.TimezoneWIB, now, // Cinema
+		3, 1, "Studio 1", now, // Screen
+		5, "Avengers", "Action", 120, "Action", "url", "PG-13", now, // Movie
+		ptr(50), ptr(1), ptr(1), ptr(domain.NewMoney(50000)), ptr("success"), &domain.PaymentDetails{}, &now, nil, nil, nil, &now, // Payment
+		ptr(1), ptr("Credit Card"), ptr("CREDIT_CARD"), ptr(true), &now, // Payment Method
+	)
+
+	seatRows := pgxmock.NewRows([]string{
+		"id", "booking_id", "showtime_id", "seat_id", "price", "released_at", "created_at",
+		"base_price", "price_multiplier", "price_rule_id",
+		"seat_id", "cinema_id", "screen_id", "seat_row", "seat_number", "seat_type", "seat_created_at",
+	}).
+		AddRow(100, 1, 10, 20, domain.NewMoney(25000), nil, now, nil, nil, nil, 20, 1, 3, "A", 1, "regular", now).
+		AddRow(101, 1, 10, 21, domain.NewMoney(25000), nil, now, nil, nil, nil, 21, 1, 3, "A", 2, "regular", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM bookings b (.+) WHERE b.booking_code = \\$1").WithArgs("BK123").WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM booking_seats bs").WithArgs([]int{1}).WillReturnRows(seatRows)
+
+	booking, err := repo.GetByCode(context.Background(), "BK123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BK123", booking.BookingCode)
+	assert.Equal(t, "CGV Grand Indonesia", booking.Showtime.Cinema.Name)
+	assert.Equal(t, "Studio 1", booking.Showtime.Screen.Name)
+	assert.Equal(t, "Avengers", booking.Showtime.Movie.Title)
+	assert.Len(t, booking.Seats, 2)
+	assert.Equal(t, "success", booking.Payment.Status)
+	assert.Equal(t, "CREDIT_CARD", booking.Payment.PaymentMethod.Code)
+	assert.Equal(t, "A", booking.Seats[1].Seat.SeatRow)
+	assert.Equal(t, 2, booking.Seats[1].Seat.SeatNumber)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -239,12 +671,12 @@ func TestBookingRepository_GetByUserID_Empty(t *testing.T) {
 	repo := NewBookingRepository(mock)
 
 	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "showtime_id", "seat_id", "booking_code", "status", "total_price", "created_at", "updated_at",
-		"showtime_id", "cinema_id", "movie_id", "show_date", "show_time", "price", "showtime_created_at",
-		"seat_id", "cinema_id", "seat_row", "seat_number", "seat_type", "seat_created_at",
-		"cinema_id", "name", "location", "description", "cinema_created_at",
+		"id", "user_id", "showtime_id", "booking_code", "status", "total_price", "discount_amount", "promo_code", "expires_at", "checked_in_at", "created_at", "updated_at",
+		"showtime_id", "cinema_id", "screen_id", "movie_id", "show_date", "show_time", "ends_at", "price", "showtime_created_at",
+		"cinema_id", "name", "location", "description", "timezone", "cinema_created_at",
+		"screen_id", "screen_cinema_id", "screen_name", "screen_created_at",
 		"movie_id", "title", "description", "duration", "genre", "poster_url", "rating", "movie_created_at",
-		"payment_id", "booking_id", "payment_method_id", "amount", "payment_status", "payment_details", "paid_at", "payment_created_at",
+		"payment_id", "booking_id", "payment_method_id", "amount", "payment_status", "payment_details", "paid_at", "refund_amount", "refunded_at", "provider_reference", "payment_created_at",
 		"pm_id", "pm_name", "code", "is_active", "pm_created_at",
 	})
 
@@ -259,7 +691,7 @@ func TestBookingRepository_GetByUserID_Empty(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestBookingRepository_Create_Error(t *testing.T) {
+func TestBookingRepository_Reserve_Error(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
 	defer mock.Close()
@@ -269,27 +701,75 @@ func TestBookingRepository_Create_Error(t *testing.T) {
 	booking := &domain.Booking{
 		UserID:      1,
 		ShowtimeID:  1,
-		SeatID:      10,
 		BookingCode: "BK123456",
 		Status:      "pending",
-		TotalPrice:  50000,
+		TotalPrice:  domain.NewMoney(50000),
+		Seats:       []*domain.BookingSeat{{SeatID: 10, Price: domain.NewMoney(50000)}},
 	}
+	now := time.Now()
 
+	mock.ExpectBegin()
+	expectSeatLock(mock, booking, nil)
 	mock.ExpectQuery("INSERT INTO bookings").
 		WithArgs(
 			booking.UserID,
 			booking.ShowtimeID,
-			booking.SeatID,
 			booking.BookingCode,
 			booking.Status,
 			booking.TotalPrice,
-			pgxmock.AnyArg(),
-			pgxmock.AnyArg(),
+			booking.DiscountAmount,
+			booking.PromoCode,
+			booking.ExpiresAt,
+			now,
+			now,
 		).
 		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
 
-	err = repo.Create(context.Background(), booking)
+	err = repo.Reserve(context.Background(), booking, now)
 
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestBookingRepository_MarkCheckedIn(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE bookings SET checked_in_at = \\$1, updated_at = \\$1 WHERE id = \\$2 AND checked_in_at IS NULL").
+		WithArgs(now, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	checkedIn, err := repo.MarkCheckedIn(context.Background(), 1, now)
+
+	assert.NoError(t, err)
+	assert.True(t, checkedIn)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookingRepository_MarkCheckedIn_AlreadyUsed(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewBookingRepository(mock)
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE bookings").
+		WithArgs(now, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	checkedIn, err := repo.MarkCheckedIn(context.Background(), 1, now)
+
+	assert.NoError(t, err)
+	assert.False(t, checkedIn)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,15 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
 	"github.com/jackc/pgx/v5"
 )
 
+// ErrCinemaHasShowtimes dikembalikan ketika cinema yang akan dihapus masih punya jadwal tayang mendatang
+var ErrCinemaHasShowtimes = errors.New("cinema still has upcoming showtimes")
+
 type CinemaRepository interface {
 	GetAll(ctx context.Context, limit, offset int) ([]*domain.Cinema, int, error)
 	GetByID(ctx context.Context, id int) (*domain.Cinema, error)
+	Create(ctx context.Context, cinema *domain.Cinema) error
+	Update(ctx context.Context, cinema *domain.Cinema) error
+	Delete(ctx context.Context, id int) error
 }
 
 type cinemaRepository struct {
@@ -26,15 +33,16 @@ func NewCinemaRepository(db PgxPool) CinemaRepository {
 func (r *cinemaRepository) GetAll(ctx context.Context, limit, offset int) ([]*domain.Cinema, int, error) {
 	// Count total
 	var total int
-	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM cinemas").Scan(&total)
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM cinemas WHERE deleted_at IS NULL").Scan(&total)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count cinemas: %w", err)
 	}
 
 	// Get cinemas with pagination
 	query := `
-		SELECT id, name, location, description, created_at
+		SELECT id, name, location, description, timezone, created_at
 		FROM cinemas
+		WHERE deleted_at IS NULL
 		ORDER BY id ASC
 		LIMIT $1 OFFSET $2
 	`
@@ -53,6 +61,7 @@ func (r *cinemaRepository) GetAll(ctx context.Context, limit, offset int) ([]*do
 			&cinema.Name,
 			&cinema.Location,
 			&cinema.Description,
+			&cinema.Timezone,
 			&cinema.CreatedAt,
 		)
 		if err != nil {
@@ -70,9 +79,9 @@ func (r *cinemaRepository) GetAll(ctx context.Context, limit, offset int) ([]*do
 
 func (r *cinemaRepository) GetByID(ctx context.Context, id int) (*domain.Cinema, error) {
 	query := `
-		SELECT id, name, location, description, created_at
+		SELECT id, name, location, description, timezone, created_at
 		FROM cinemas
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var cinema domain.Cinema
@@ -81,6 +90,7 @@ func (r *cinemaRepository) GetByID(ctx context.Context, id int) (*domain.Cinema,
 		&cinema.Name,
 		&cinema.Location,
 		&cinema.Description,
+		&cinema.Timezone,
 		&cinema.CreatedAt,
 	)
 
@@ -93,3 +103,66 @@ func (r *cinemaRepository) GetByID(ctx context.Context, id int) (*domain.Cinema,
 
 	return &cinema, nil
 }
+
+func (r *cinemaRepository) Create(ctx context.Context, cinema *domain.Cinema) error {
+	query := `
+		INSERT INTO cinemas (name, location, description, timezone, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, cinema.Name, cinema.Location, cinema.Description, cinema.Timezone, time.Now()).
+		Scan(&cinema.ID, &cinema.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cinema: %w", err)
+	}
+
+	return nil
+}
+
+func (r *cinemaRepository) Update(ctx context.Context, cinema *domain.Cinema) error {
+	query := `
+		UPDATE cinemas
+		SET name = $2, location = $3, description = $4, timezone = $5
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, cinema.ID, cinema.Name, cinema.Location, cinema.Description, cinema.Timezone).
+		Scan(&cinema.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("cinema %w", ErrNotFound)
+		}
+		return fmt.Errorf("failed to update cinema: %w", err)
+	}
+
+	return nil
+}
+
+// Delete menandai cinema sebagai terhapus (soft delete), ditolak selama masih ada jadwal tayang mendatang
+func (r *cinemaRepository) Delete(ctx context.Context, id int) error {
+	query := `
+		UPDATE cinemas
+		SET deleted_at = $2
+		WHERE id = $1
+		  AND deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM showtimes s
+			WHERE s.cinema_id = cinemas.id
+			  AND s.deleted_at IS NULL
+			  AND (s.show_date + s.show_time) AT TIME ZONE cinemas.timezone >= NOW()
+		  )
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete cinema: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return softDeleteMiss(ctx, r.db, "cinemas", "cinema", id, ErrCinemaHasShowtimes)
+	}
+
+	return nil
+}
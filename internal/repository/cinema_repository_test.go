@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
@@ -22,9 +25,9 @@ func TestCinemaRepository_GetAll(t *testing.T) {
 	mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "created_at"}).
-		AddRow(1, "CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", now).
-		AddRow(2, "XXI Plaza Senayan", "Jakarta Selatan", "Modern cinema", now)
+	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "timezone", "created_at"}).
+		AddRow(1, "CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", domain.TimezoneWIB, now).
+		AddRow(2, "XXI Plaza Senayan", "Jakarta Selatan", "Modern cinema", domain.TimezoneWIB, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM cinemas").
 		WithArgs(10, 0).
@@ -49,7 +52,7 @@ func TestCinemaRepository_GetAll_Empty(t *testing.T) {
 	countRows := pgxmock.NewRows([]string{"count"}).AddRow(0)
 	mock.ExpectQuery("SELECT COUNT").WillReturnRows(countRows)
 
-	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "created_at"})
+	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "timezone", "created_at"})
 	mock.ExpectQuery("SELECT (.+) FROM cinemas").
 		WithArgs(10, 0).
 		WillReturnRows(rows)
@@ -70,8 +73,8 @@ func TestCinemaRepository_GetByID(t *testing.T) {
 	repo := NewCinemaRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "created_at"}).
-		AddRow(1, "CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", now)
+	rows := pgxmock.NewRows([]string{"id", "name", "location", "description", "timezone", "created_at"}).
+		AddRow(1, "CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", domain.TimezoneWIB, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM cinemas WHERE id").
 		WithArgs(1).
@@ -103,3 +106,98 @@ func TestCinemaRepository_GetByID_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "cinema not found")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestCinemaRepository_Create(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCinemaRepository(mock)
+
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO cinemas").
+		WithArgs("CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", domain.TimezoneWITA, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+	cinema := &domain.Cinema{Name: "CGV Grand Indonesia", Location: "Jakarta Pusat", Description: "Premium cinema", Timezone: domain.TimezoneWITA}
+	err = repo.Create(context.Background(), cinema)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, cinema.ID)
+	assert.Equal(t, now, cinema.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCinemaRepository_Update_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCinemaRepository(mock)
+
+	mock.ExpectQuery("UPDATE cinemas").
+		WithArgs(999, "CGV", "Jakarta", "", domain.TimezoneWIB).
+		WillReturnError(pgx.ErrNoRows)
+
+	err = repo.Update(context.Background(), &domain.Cinema{ID: 999, Name: "CGV", Location: "Jakarta", Timezone: domain.TimezoneWIB})
+
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCinemaRepository_Delete(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCinemaRepository(mock)
+
+	mock.ExpectExec("UPDATE cinemas\\s+SET deleted_at").
+		WithArgs(1, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.Delete(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCinemaRepository_Delete_HasShowtimes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCinemaRepository(mock)
+
+	mock.ExpectExec("UPDATE cinemas\\s+SET deleted_at").
+		WithArgs(1, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM cinemas").
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err = repo.Delete(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrCinemaHasShowtimes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCinemaRepository_Delete_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewCinemaRepository(mock)
+
+	mock.ExpectExec("UPDATE cinemas\\s+SET deleted_at").
+		WithArgs(999, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM cinemas").
+		WithArgs(999).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+
+	err = repo.Delete(context.Background(), 999)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
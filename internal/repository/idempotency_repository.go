@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type IdempotencyRepository interface {
+	Create(ctx context.Context, key *domain.IdempotencyKey) (bool, error)
+	GetByKey(ctx context.Context, userID int, key string) (*domain.IdempotencyKey, error)
+	Complete(ctx context.Context, key *domain.IdempotencyKey) error
+	Delete(ctx context.Context, id int) error
+	DeleteExpired(ctx context.Context) error
+}
+
+type idempotencyRepository struct {
+	db PgxPool
+}
+
+func NewIdempotencyRepository(db PgxPool) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Create menyimpan key baru. Mengembalikan false jika key masih dipakai request lain;
+// key yang sudah expired (belum sempat dibersihkan) ditimpa.
+func (r *idempotencyRepository) Create(ctx context.Context, key *domain.IdempotencyKey) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = 0,
+		    response_body = NULL,
+		    completed_at = NULL,
+		    expires_at = EXCLUDED.expires_at,
+		    created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+		RETURNING id, created_at
+	`
+
+	now := time.Now()
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		key.UserID,
+		key.Key,
+		key.RequestHash,
+		key.ExpiresAt,
+		now,
+	).Scan(&key.ID, &key.CreatedAt)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *idempotencyRepository) GetByKey(ctx context.Context, userID int, key string) (*domain.IdempotencyKey, error) {
+	query := `
+		SELECT id, user_id, idempotency_key, request_hash, status_code, response_body, completed_at, expires_at, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`
+
+	var record domain.IdempotencyKey
+	err := r.db.QueryRow(ctx, query, userID, key).Scan(
+		&record.ID,
+		&record.UserID,
+		&record.Key,
+		&record.RequestHash,
+		&record.StatusCode,
+		&record.ResponseBody,
+		&record.CompletedAt,
+		&record.ExpiresAt,
+		&record.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("idempotency key not found")
+		}
+		return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	return &record, nil
+}
+
+// Complete menyimpan response akhir untuk key
+func (r *idempotencyRepository) Complete(ctx context.Context, key *domain.IdempotencyKey) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_body = $2, completed_at = $3
+		WHERE id = $4
+	`
+
+	now := time.Now()
+	_, err := r.db.Exec(ctx, query, key.StatusCode, key.ResponseBody, now, key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	key.CompletedAt = &now
+
+	return nil
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM idempotency_keys WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) error {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`
+
+	_, err := r.db.Exec(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository_Create(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewIdempotencyRepository(mock)
+
+	now := time.Now()
+	key := &domain.IdempotencyKey{
+		UserID:      1,
+		Key:         "retry-123",
+		RequestHash: "abc",
+		ExpiresAt:   now.Add(24 * time.Hour),
+	}
+
+	mock.ExpectQuery("INSERT INTO idempotency_keys (.+) ON CONFLICT").
+		WithArgs(key.UserID, key.Key, key.RequestHash, key.ExpiresAt, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+	created, err := repo.Create(context.Background(), key)
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 7, key.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Create_KeyTaken(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewIdempotencyRepository(mock)
+
+	key := &domain.IdempotencyKey{
+		UserID:      1,
+		Key:         "retry-123",
+		RequestHash: "abc",
+		ExpiresAt:   time.Now().Add(24 * time.Hour),
+	}
+
+	mock.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs(key.UserID, key.Key, key.RequestHash, key.ExpiresAt, pgxmock.AnyArg()).
+		WillReturnError(pgx.ErrNoRows)
+
+	created, err := repo.Create(context.Background(), key)
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_GetByKey(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewIdempotencyRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "user_id", "idempotency_key", "request_hash", "status_code", "response_body", "completed_at", "expires_at", "created_at",
+	}).AddRow(7, 1, "retry-123", "abc", 201, []byte(`{"success":true}`), &now, now.Add(24*time.Hour), now)
+
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys").
+		WithArgs(1, "retry-123").
+		WillReturnRows(rows)
+
+	record, err := repo.GetByKey(context.Background(), 1, "retry-123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 201, record.StatusCode)
+	assert.True(t, record.IsCompleted())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_Complete(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewIdempotencyRepository(mock)
+
+	key := &domain.IdempotencyKey{ID: 7, StatusCode: 201, ResponseBody: []byte(`{}`)}
+
+	mock.ExpectExec("UPDATE idempotency_keys").
+		WithArgs(key.StatusCode, key.ResponseBody, pgxmock.AnyArg(), key.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.Complete(context.Background(), key)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, key.CompletedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewIdempotencyRepository(mock)
+
+	mock.ExpectExec("DELETE FROM idempotency_keys WHERE expires_at").
+		WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+	err = repo.DeleteExpired(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrMovieHasShowtimes dikembalikan ketika film yang akan dihapus masih punya jadwal tayang mendatang
+var ErrMovieHasShowtimes = errors.New("movie still has upcoming showtimes")
+
+type MovieRepository interface {
+	GetAll(ctx context.Context, filter domain.MovieFilter, limit, offset int) ([]*domain.Movie, int, error)
+	GetByID(ctx context.Context, id int) (*domain.Movie, error)
+	Create(ctx context.Context, movie *domain.Movie) error
+	Update(ctx context.Context, movie *domain.Movie) error
+	Delete(ctx context.Context, id int) error
+}
+
+type movieRepository struct {
+	db PgxPool
+}
+
+func NewMovieRepository(db PgxPool) MovieRepository {
+	return &movieRepository{db: db}
+}
+
+func (r *movieRepository) GetAll(ctx context.Context, filter domain.MovieFilter, limit, offset int) ([]*domain.Movie, int, error) {
+	// Build WHERE clause from optional filters
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if filter.Genre != "" {
+		// Genre can hold several values, e.g. "Action, Sci-Fi"
+		args = append(args, filter.Genre)
+		conditions = append(conditions, fmt.Sprintf("genre ILIKE '%%' || $%d || '%%'", len(args)))
+	}
+	if filter.Rating != "" {
+		args = append(args, filter.Rating)
+		conditions = append(conditions, fmt.Sprintf("UPPER(rating) = UPPER($%d)", len(args)))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	// Count total
+	var total int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM movies "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count movies: %w", err)
+	}
+
+	// Get movies with pagination
+	query := fmt.Sprintf(`
+		SELECT id, title, description, duration, genre, poster_url, rating, created_at
+		FROM movies
+		%s
+		ORDER BY id ASC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get movies: %w", err)
+	}
+	defer rows.Close()
+
+	var movies []*domain.Movie
+	for rows.Next() {
+		var movie domain.Movie
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.Description,
+			&movie.Duration,
+			&movie.Genre,
+			&movie.PosterURL,
+			&movie.Rating,
+			&movie.CreatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan movie: %w", err)
+		}
+		movies = append(movies, &movie)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating movies: %w", err)
+	}
+
+	return movies, total, nil
+}
+
+func (r *movieRepository) GetByID(ctx context.Context, id int) (*domain.Movie, error) {
+	query := `
+		SELECT id, title, description, duration, genre, poster_url, rating, created_at
+		FROM movies
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	var movie domain.Movie
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&movie.ID,
+		&movie.Title,
+		&movie.Description,
+		&movie.Duration,
+		&movie.Genre,
+		&movie.PosterURL,
+		&movie.Rating,
+		&movie.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("movie not found")
+		}
+		return nil, fmt.Errorf("failed to get movie: %w", err)
+	}
+
+	return &movie, nil
+}
+
+func (r *movieRepository) Create(ctx context.Context, movie *domain.Movie) error {
+	query := `
+		INSERT INTO movies (title, description, duration, genre, poster_url, rating, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		movie.Title,
+		movie.Description,
+		movie.Duration,
+		movie.Genre,
+		movie.PosterURL,
+		movie.Rating,
+		time.Now(),
+	).Scan(&movie.ID, &movie.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create movie: %w", err)
+	}
+
+	return nil
+}
+
+// Update mengubah film; jam selesai showtime mendatang ikut dihitung ulang dari durasi baru
+func (r *movieRepository) Update(ctx context.Context, movie *domain.Movie) error {
+	query := `
+		WITH updated AS (
+			UPDATE movies
+			SET title = $2, description = $3, duration = $4, genre = $5, poster_url = $6, rating = $7
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id, duration, created_at
+		), rescheduled AS (
+			UPDATE showtimes s
+			SET ends_at = s.show_date + s.show_time + u.duration * INTERVAL '1 minute'
+			FROM updated u, cinemas c
+			WHERE s.movie_id = u.id
+			  AND c.id = s.cinema_id
+			  AND s.deleted_at IS NULL
+			  AND (s.show_date + s.show_time) AT TIME ZONE c.timezone >= NOW()
+		)
+		SELECT created_at FROM updated
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		movie.ID,
+		movie.Title,
+		movie.Description,
+		movie.Duration,
+		movie.Genre,
+		movie.PosterURL,
+		movie.Rating,
+	).Scan(&movie.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("movie %w", ErrNotFound)
+		}
+		if isExclusionViolation(err, "showtimes_screen_no_overlap") {
+			return ErrShowtimeOverlap
+		}
+		return fmt.Errorf("failed to update movie: %w", err)
+	}
+
+	return nil
+}
+
+// Delete menandai film sebagai terhapus (soft delete), ditolak selama masih ada jadwal tayang mendatang
+func (r *movieRepository) Delete(ctx context.Context, id int) error {
+	query := `
+		UPDATE movies
+		SET deleted_at = $2
+		WHERE id = $1
+		  AND deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM showtimes s
+			JOIN cinemas c ON c.id = s.cinema_id
+			WHERE s.movie_id = movies.id
+			  AND s.deleted_at IS NULL
+			  AND (s.show_date + s.show_time) AT TIME ZONE c.timezone >= NOW()
+		  )
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete movie: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return softDeleteMiss(ctx, r.db, "movies", "movie", id, ErrMovieHasShowtimes)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var movieColumns = []string{"id", "title", "description", "duration", "genre", "poster_url", "rating", "created_at"}
+
+func TestMovieRepository_GetAll(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewMovieRepository(mock)
+
+	countRows := pgxmock.NewRows([]string{"count"}).AddRow(2)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM movies WHERE deleted_at IS NULL$").WillReturnRows(countRows)
+
+	now := time.Now()
+	rows := pgxmock.NewRows(movieColumns).
+		AddRow(1, "Avengers: Endgame", "Final battle", 181, "Action, Sci-Fi", "https://example.com/1.jpg", "PG-13", now).
+		AddRow(2, "Spider-Man", "Multiverse", 148, "Action, Adventure", "https://example.com/2.jpg", "PG-13", now)
+
+	mock.ExpectQuery("SELECT (.+) FROM movies\\s+WHERE deleted_at IS NULL\\s+ORDER BY id ASC\\s+LIMIT \\$1 OFFSET \\$2").
+		WithArgs(10, 0).
+		WillReturnRows(rows)
+
+	movies, total, err := repo.GetAll(context.Background(), domain.MovieFilter{}, 10, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, movies, 2)
+	assert.Equal(t, "Avengers: Endgame", movies[0].Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMovieRepository_GetAll_WithFilter(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewMovieRepository(mock)
+
+	countRows := pgxmock.NewRows([]string{"count"}).AddRow(1)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM movies WHERE deleted_at IS NULL AND genre ILIKE (.+)\\$1(.+) AND UPPER\\(rating\\) = UPPER\\(\\$2\\)").
+		WithArgs("action", "pg-13").
+		WillReturnRows(countRows)
+
+	rows := pgxmock.NewRows(movieColumns).
+		AddRow(1, "Avengers: Endgame", "Final battle", 181, "Action, Sci-Fi", "https://example.com/1.jpg", "PG-13", time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM movies\\s+WHERE (.+) LIMIT \\$3 OFFSET \\$4").
+		WithArgs("action", "pg-13", 5, 5).
+		WillReturnRows(rows)
+
+	movies, total, err := repo.GetAll(context.Background(), domain.MovieFilter{Genre: "action", Rating: "pg-13"}, 5, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, movies, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMovieRepository_GetByID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewMovieRepository(mock)
+
+	rows := pgxmock.NewRows(movieColumns).
+		AddRow(1, "Avengers: Endgame", "Final battle", 181, "Action, Sci-Fi", "https://example.com/1.jpg", "PG-13", time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM movies WHERE id").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	movie, err := repo.GetByID(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 181, movie.Duration)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMovieRepository_GetByID_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewMovieRepository(mock)
+
+	mock.ExpectQuery("SELECT (.+) FROM movies WHERE id").
+		WithArgs(999).
+		WillReturnError(pgx.ErrNoRows)
+
+	movie, err := repo.GetByID(context.Background(), 999)
+
+	assert.Error(t, err)
+	assert.Nil(t, movie)
+	assert.Contains(t, err.Error(), "movie not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
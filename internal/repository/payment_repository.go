@@ -2,16 +2,24 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// ErrDuplicatePayment dikembalikan ketika booking sudah punya payment
+var ErrDuplicatePayment = errors.New("payment already exists for this booking")
+
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *domain.Payment) error
 	GetByBookingID(ctx context.Context, bookingID int) (*domain.Payment, error)
+	GetByProviderReference(ctx context.Context, reference string) (*domain.Payment, error)
 	Update(ctx context.Context, payment *domain.Payment) error
+	UpdateStatusIfPending(ctx context.Context, payment *domain.Payment) (bool, error)
 }
 
 type paymentRepository struct {
@@ -24,8 +32,8 @@ func NewPaymentRepository(db PgxPool) PaymentRepository {
 
 func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment) error {
 	query := `
-		INSERT INTO payments (booking_id, payment_method_id, amount, status, payment_details, paid_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO payments (booking_id, payment_method_id, amount, status, payment_details, provider_reference, paid_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at
 	`
 
@@ -38,11 +46,16 @@ func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment)
 		payment.Amount,
 		payment.Status,
 		payment.PaymentDetails,
+		payment.ProviderReference,
 		payment.PaidAt,
 		now,
 	).Scan(&payment.ID, &payment.CreatedAt)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "payments_booking_id_key" {
+			return ErrDuplicatePayment
+		}
 		return fmt.Errorf("failed to create payment: %w", err)
 	}
 
@@ -50,18 +63,26 @@ func (r *paymentRepository) Create(ctx context.Context, payment *domain.Payment)
 }
 
 func (r *paymentRepository) GetByBookingID(ctx context.Context, bookingID int) (*domain.Payment, error) {
+	return r.getOne(ctx, "p.booking_id = $1", bookingID)
+}
+
+// GetByProviderReference mengambil payment berdasarkan reference transaksi dari provider
+func (r *paymentRepository) GetByProviderReference(ctx context.Context, reference string) (*domain.Payment, error) {
+	return r.getOne(ctx, "p.provider_reference = $1", reference)
+}
+
+func (r *paymentRepository) getOne(ctx context.Context, condition string, arg interface{}) (*domain.Payment, error) {
 	query := `
-		SELECT p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.created_at,
+		SELECT p.id, p.booking_id, p.payment_method_id, p.amount, p.status, p.payment_details, p.paid_at, p.refund_amount, p.refunded_at, p.provider_reference, p.created_at,
 		       pm.id, pm.name, pm.code, pm.is_active, pm.created_at
 		FROM payments p
 		JOIN payment_methods pm ON p.payment_method_id = pm.id
-		WHERE p.booking_id = $1
-	`
+		WHERE ` + condition
 
 	var payment domain.Payment
 	var paymentMethod domain.PaymentMethod
 
-	err := r.db.QueryRow(ctx, query, bookingID).Scan(
+	err := r.db.QueryRow(ctx, query, arg).Scan(
 		&payment.ID,
 		&payment.BookingID,
 		&payment.PaymentMethodID,
@@ -69,6 +90,9 @@ func (r *paymentRepository) GetByBookingID(ctx context.Context, bookingID int) (
 		&payment.Status,
 		&payment.PaymentDetails,
 		&payment.PaidAt,
+		&payment.RefundAmount,
+		&payment.RefundedAt,
+		&payment.ProviderReference,
 		&payment.CreatedAt,
 		&paymentMethod.ID,
 		&paymentMethod.Name,
@@ -89,14 +113,31 @@ func (r *paymentRepository) GetByBookingID(ctx context.Context, bookingID int) (
 func (r *paymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
 	query := `
 		UPDATE payments
-		SET status = $1, paid_at = $2
-		WHERE id = $3
+		SET status = $1, paid_at = $2, refund_amount = $3, refunded_at = $4
+		WHERE id = $5
 	`
 
-	_, err := r.db.Exec(ctx, query, payment.Status, payment.PaidAt, payment.ID)
+	_, err := r.db.Exec(ctx, query, payment.Status, payment.PaidAt, payment.RefundAmount, payment.RefundedAt, payment.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update payment: %w", err)
 	}
 
 	return nil
 }
+
+// UpdateStatusIfPending mengubah status payment hanya jika masih pending.
+// Mengembalikan false jika payment sudah diproses sebelumnya (mis. webhook dikirim ulang).
+func (r *paymentRepository) UpdateStatusIfPending(ctx context.Context, payment *domain.Payment) (bool, error) {
+	query := `
+		UPDATE payments
+		SET status = $1, paid_at = $2
+		WHERE id = $3 AND status = 'pending'
+	`
+
+	result, err := r.db.Exec(ctx, query, payment.Status, payment.PaidAt, payment.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
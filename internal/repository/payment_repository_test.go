@@ -7,6 +7,7 @@ import (
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,7 +24,7 @@ func TestPaymentRepository_Create(t *testing.T) {
 	payment := &domain.Payment{
 		BookingID:       1,
 		PaymentMethodID: 1,
-		Amount:          50000,
+		Amount:          domain.NewMoney(50000),
 		Status:          "completed",
 		PaymentDetails:  domain.PaymentDetails{"card_type": "Visa"},
 		PaidAt:          &now,
@@ -39,6 +40,7 @@ func TestPaymentRepository_Create(t *testing.T) {
 			payment.Amount,
 			payment.Status,
 			pgxmock.AnyArg(),
+			payment.ProviderReference,
 			payment.PaidAt,
 			pgxmock.AnyArg(),
 		).
@@ -60,10 +62,10 @@ func TestPaymentRepository_GetByBookingID(t *testing.T) {
 
 	now := time.Now()
 	rows := pgxmock.NewRows([]string{
-		"id", "booking_id", "payment_method_id", "amount", "status", "payment_details", "paid_at", "created_at",
+		"id", "booking_id", "payment_method_id", "amount", "status", "payment_details", "paid_at", "refund_amount", "refunded_at", "provider_reference", "created_at",
 		"pm_id", "name", "code", "is_active", "pm_created_at",
 	}).AddRow(
-		100, 1, 1, 50000.0, "completed", []byte(`{"card_type":"Visa"}`), &now, now,
+		100, 1, 1, domain.NewMoney(50000), "completed", []byte(`{"card_type":"Visa"}`), &now, nil, nil, nil, now,
 		1, "Credit Card", "CREDIT_CARD", true, now,
 	)
 
@@ -97,7 +99,7 @@ func TestPaymentRepository_Update(t *testing.T) {
 	}
 
 	mock.ExpectExec("UPDATE payments").
-		WithArgs(payment.Status, payment.PaidAt, payment.ID).
+		WithArgs(payment.Status, payment.PaidAt, payment.RefundAmount, payment.RefundedAt, payment.ID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	err = repo.Update(context.Background(), payment)
@@ -117,7 +119,7 @@ func TestPaymentRepository_Create_Error(t *testing.T) {
 	payment := &domain.Payment{
 		BookingID:       1,
 		PaymentMethodID: 1,
-		Amount:          50000,
+		Amount:          domain.NewMoney(50000),
 		Status:          "completed",
 		PaidAt:          &now,
 	}
@@ -129,6 +131,7 @@ func TestPaymentRepository_Create_Error(t *testing.T) {
 			payment.Amount,
 			payment.Status,
 			pgxmock.AnyArg(),
+			payment.ProviderReference,
 			payment.PaidAt,
 			pgxmock.AnyArg(),
 		).
@@ -139,3 +142,97 @@ func TestPaymentRepository_Create_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPaymentRepository_GetByProviderReference(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPaymentRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "booking_id", "payment_method_id", "amount", "status", "payment_details", "paid_at", "refund_amount", "refunded_at", "provider_reference", "created_at",
+		"pm_id", "name", "code", "is_active", "pm_created_at",
+	}).AddRow(
+		100, 1, 3, domain.NewMoney(50000), "pending", []byte(`{}`), nil, nil, nil, nil, now,
+		3, "GoPay", "GOPAY", true, now,
+	)
+
+	mock.ExpectQuery("SELECT (.+) FROM payments p JOIN payment_methods pm ON p.payment_method_id = pm.id WHERE p.provider_reference = \\$1").
+		WithArgs("SIM-GOPAY-1-1").
+		WillReturnRows(rows)
+
+	payment, err := repo.GetByProviderReference(context.Background(), "SIM-GOPAY-1-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 100, payment.ID)
+	assert.Equal(t, "GOPAY", payment.PaymentMethod.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_UpdateStatusIfPending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPaymentRepository(mock)
+
+	now := time.Now()
+	payment := &domain.Payment{ID: 100, Status: "success", PaidAt: &now}
+
+	mock.ExpectExec("UPDATE payments SET status = \\$1, paid_at = \\$2 WHERE id = \\$3 AND status = 'pending'").
+		WithArgs(payment.Status, payment.PaidAt, payment.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	updated, err := repo.UpdateStatusIfPending(context.Background(), payment)
+
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_UpdateStatusIfPending_AlreadyProcessed(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPaymentRepository(mock)
+
+	now := time.Now()
+	payment := &domain.Payment{ID: 100, Status: "success", PaidAt: &now}
+
+	mock.ExpectExec("UPDATE payments").
+		WithArgs(payment.Status, payment.PaidAt, payment.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	updated, err := repo.UpdateStatusIfPending(context.Background(), payment)
+
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPaymentRepository_Create_Duplicate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPaymentRepository(mock)
+
+	payment := &domain.Payment{
+		BookingID:       1,
+		PaymentMethodID: 1,
+		Amount:          domain.NewMoney(50000),
+		Status:          "success",
+	}
+
+	mock.ExpectQuery("INSERT INTO payments").
+		WithArgs(payment.BookingID, payment.PaymentMethodID, payment.Amount, payment.Status, pgxmock.AnyArg(), payment.ProviderReference, payment.PaidAt, pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "payments_booking_id_key"})
+
+	err = repo.Create(context.Background(), payment)
+
+	assert.ErrorIs(t, err, ErrDuplicatePayment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
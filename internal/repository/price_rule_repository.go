@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+)
+
+type PriceRuleRepository interface {
+	GetForShowtime(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatPriceRule, error)
+}
+
+type priceRuleRepository struct {
+	db PgxPool
+}
+
+func NewPriceRuleRepository(db PgxPool) PriceRuleRepository {
+	return &priceRuleRepository{db: db}
+}
+
+// GetForShowtime mengambil semua rule yang bisa berlaku untuk showtime:
+// rule global, rule cinema, dan rule khusus showtime tersebut
+func (r *priceRuleRepository) GetForShowtime(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatPriceRule, error) {
+	query := `
+		SELECT
+			id, cinema_id, showtime_id, seat_type, multiplier, price, days_of_week,
+			to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), created_at
+		FROM seat_price_rules
+		WHERE (cinema_id IS NULL OR cinema_id = $1)
+		  AND (showtime_id IS NULL OR showtime_id = $2)
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(ctx, query, cinemaID, showtimeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.SeatPriceRule
+	for rows.Next() {
+		var rule domain.SeatPriceRule
+		err := rows.Scan(
+			&rule.ID,
+			&rule.CinemaID,
+			&rule.ShowtimeID,
+			&rule.SeatType,
+			&rule.Multiplier,
+			&rule.Price,
+			&rule.DaysOfWeek,
+			&rule.StartTime,
+			&rule.EndTime,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price rules: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceRuleRepository_GetForShowtime(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPriceRuleRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "cinema_id", "showtime_id", "seat_type", "multiplier", "price", "days_of_week", "start_time", "end_time", "created_at",
+	}).
+		AddRow(1, nil, nil, "vip", ptr(1.5), nil, nil, nil, nil, now).
+		AddRow(2, ptr(1), nil, "vip", nil, ptr(domain.NewMoney(90000)), []int{0, 6}, ptr("18:00"), ptr("23:00"), now)
+
+	mock.ExpectQuery("SELECT (.+) FROM seat_price_rules WHERE \\(cinema_id IS NULL OR cinema_id = \\$1\\) AND \\(showtime_id IS NULL OR showtime_id = \\$2\\)").
+		WithArgs(1, 7).
+		WillReturnRows(rows)
+
+	rules, err := repo.GetForShowtime(context.Background(), 1, 7)
+
+	assert.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Nil(t, rules[0].CinemaID)
+	assert.Equal(t, 1.5, *rules[0].Multiplier)
+	assert.Equal(t, 1, *rules[1].CinemaID)
+	assert.Equal(t, domain.NewMoney(90000), *rules[1].Price)
+	assert.Equal(t, []int{0, 6}, rules[1].DaysOfWeek)
+	assert.Equal(t, "18:00", *rules[1].StartTime)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPriceRuleRepository_GetForShowtime_Error(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPriceRuleRepository(mock)
+
+	mock.ExpectQuery("SELECT (.+) FROM seat_price_rules").
+		WithArgs(1, 7).
+		WillReturnError(assert.AnError)
+
+	rules, err := repo.GetForShowtime(context.Background(), 1, 7)
+
+	assert.Error(t, err)
+	assert.Nil(t, rules)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
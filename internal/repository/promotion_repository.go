@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrPromotionExhausted dikembalikan ketika kuota total promo sudah habis
+	ErrPromotionExhausted = errors.New("promo code has reached its usage limit")
+	// ErrPromotionUserLimit dikembalikan ketika user sudah mencapai batas pemakaian promo
+	ErrPromotionUserLimit = errors.New("promo code usage limit per user has been reached")
+	// ErrPromotionAlreadyApplied dikembalikan ketika booking sudah memakai promo
+	ErrPromotionAlreadyApplied = errors.New("booking already has a promo code")
+)
+
+type PromotionRepository interface {
+	GetByCode(ctx context.Context, code string) (*domain.Promotion, error)
+	ApplyToBooking(ctx context.Context, booking *domain.Booking, redemption *domain.PromotionRedemption) error
+	RemoveFromBooking(ctx context.Context, booking *domain.Booking) error
+}
+
+type promotionRepository struct {
+	db PgxPool
+}
+
+func NewPromotionRepository(db PgxPool) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+// GetByCode mengambil promo berdasarkan code (tidak case sensitive)
+func (r *promotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	query := `
+		SELECT
+			id, code, COALESCE(description, ''), discount_type,
+			COALESCE(discount_percent, 0), COALESCE(discount_amount, 0), max_discount,
+			valid_from, valid_until, max_uses, max_uses_per_user,
+			movie_ids, cinema_ids, payment_methods, is_active, created_at
+		FROM promotions
+		WHERE UPPER(code) = UPPER($1)
+	`
+
+	var promo domain.Promotion
+	err := r.db.QueryRow(ctx, query, code).Scan(
+		&promo.ID,
+		&promo.Code,
+		&promo.Description,
+		&promo.DiscountType,
+		&promo.DiscountPercent,
+		&promo.DiscountAmount,
+		&promo.MaxDiscount,
+		&promo.ValidFrom,
+		&promo.ValidUntil,
+		&promo.MaxUses,
+		&promo.MaxUsesPerUser,
+		&promo.MovieIDs,
+		&promo.CinemaIDs,
+		&promo.PaymentMethods,
+		&promo.IsActive,
+		&promo.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("promotion %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// ApplyToBooking memotong total booking pending yang belum memakai promo
+// dan mencatat redemption dalam satu transaksi
+func (r *promotionRepository) ApplyToBooking(ctx context.Context, booking *domain.Booking, redemption *domain.PromotionRedemption) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE bookings
+		SET total_price = total_price - $2, discount_amount = $2, promo_code = $3, updated_at = $4
+		WHERE id = $1
+		  AND status = 'pending'
+		  AND promo_code IS NULL
+		RETURNING total_price, updated_at
+	`
+
+	err = tx.QueryRow(ctx, query, booking.ID, redemption.DiscountAmount, redemption.Promotion.Code, time.Now()).
+		Scan(&booking.TotalPrice, &booking.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrPromotionAlreadyApplied
+		}
+		return fmt.Errorf("failed to apply promotion: %w", err)
+	}
+
+	redemption.BookingID = booking.ID
+	if err := redeemPromotion(ctx, tx, redemption); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit promotion: %w", err)
+	}
+
+	code := redemption.Promotion.Code
+	booking.DiscountAmount = redemption.DiscountAmount
+	booking.PromoCode = &code
+
+	return nil
+}
+
+// RemoveFromBooking membatalkan promo yang dipasang ApplyToBooking: total booking pending dikembalikan
+// dan redemption dihapus dalam satu transaksi, sehingga kuotanya bisa dipakai lagi
+func (r *promotionRepository) RemoveFromBooking(ctx context.Context, booking *domain.Booking) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE bookings
+		SET total_price = total_price + discount_amount, discount_amount = 0, promo_code = NULL, updated_at = $2
+		WHERE id = $1
+		  AND status = 'pending'
+		  AND promo_code IS NOT NULL
+		RETURNING total_price, updated_at
+	`
+
+	err = tx.QueryRow(ctx, query, booking.ID, time.Now()).Scan(&booking.TotalPrice, &booking.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// No promo anymore, or the booking is no longer pending and its redemption was released with it
+			return nil
+		}
+		return fmt.Errorf("failed to remove promotion: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM promotion_redemptions WHERE booking_id = $1", booking.ID); err != nil {
+		return fmt.Errorf("failed to delete promotion redemption: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit promotion: %w", err)
+	}
+
+	booking.DiscountAmount = 0
+	booking.PromoCode = nil
+	booking.Redemption = nil
+
+	return nil
+}
+
+// redeemPromotion mencatat pemakaian promo di dalam transaksi tx.
+// Baris promo dikunci dulu supaya redemption bersamaan untuk code yang sama
+// antri, sehingga kuota total dan per user tidak bisa terlampaui.
+func redeemPromotion(ctx context.Context, tx pgx.Tx, redemption *domain.PromotionRedemption) error {
+	var maxUses, maxUsesPerUser *int
+	err := tx.QueryRow(ctx, "SELECT max_uses, max_uses_per_user FROM promotions WHERE id = $1 FOR UPDATE", redemption.PromotionID).
+		Scan(&maxUses, &maxUsesPerUser)
+	if err != nil {
+		return fmt.Errorf("failed to lock promotion: %w", err)
+	}
+
+	usageQuery := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2)
+		FROM promotion_redemptions
+		WHERE promotion_id = $1
+		  AND released_at IS NULL
+	`
+
+	var used, usedByUser int
+	if err := tx.QueryRow(ctx, usageQuery, redemption.PromotionID, redemption.UserID).Scan(&used, &usedByUser); err != nil {
+		return fmt.Errorf("failed to count promotion usage: %w", err)
+	}
+
+	if maxUses != nil && used >= *maxUses {
+		return ErrPromotionExhausted
+	}
+	if maxUsesPerUser != nil && usedByUser >= *maxUsesPerUser {
+		return ErrPromotionUserLimit
+	}
+
+	insertQuery := `
+		INSERT INTO promotion_redemptions (promotion_id, user_id, booking_id, discount_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRow(ctx, insertQuery, redemption.PromotionID, redemption.UserID, redemption.BookingID, redemption.DiscountAmount, time.Now()).
+		Scan(&redemption.ID, &redemption.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record promotion redemption: %w", err)
+	}
+
+	return nil
+}
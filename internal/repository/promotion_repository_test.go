@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromotionRepository_GetByCode(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows([]string{
+		"id", "code", "description", "discount_type", "discount_percent", "discount_amount", "max_discount",
+		"valid_from", "valid_until", "max_uses", "max_uses_per_user",
+		"movie_ids", "cinema_ids", "payment_methods", "is_active", "created_at",
+	}).AddRow(
+		1, "HEMAT20", "Diskon 20%", "percent", 20.0, domain.Money(0), ptr(domain.NewMoney(25000)),
+		now, now.Add(24*time.Hour), ptr(100), ptr(1),
+		[]int{3}, nil, []string{"gopay"}, true, now,
+	)
+
+	mock.ExpectQuery("SELECT (.+) FROM promotions WHERE UPPER\\(code\\) = UPPER\\(\\$1\\)").
+		WithArgs("hemat20").
+		WillReturnRows(rows)
+
+	promo, err := repo.GetByCode(context.Background(), "hemat20")
+
+	assert.NoError(t, err)
+	require.NotNil(t, promo)
+	assert.Equal(t, "HEMAT20", promo.Code)
+	assert.Equal(t, 20.0, promo.DiscountPercent)
+	assert.Equal(t, domain.NewMoney(25000), *promo.MaxDiscount)
+	assert.Equal(t, 1, *promo.MaxUsesPerUser)
+	assert.Equal(t, []int{3}, promo.MovieIDs)
+	assert.Nil(t, promo.CinemaIDs)
+	assert.Equal(t, []string{"gopay"}, promo.PaymentMethods)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_GetByCode_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	mock.ExpectQuery("SELECT (.+) FROM promotions").
+		WithArgs("NOPE").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+
+	promo, err := repo.GetByCode(context.Background(), "NOPE")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, promo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testRedemption() *domain.PromotionRedemption {
+	return &domain.PromotionRedemption{
+		PromotionID:    1,
+		UserID:         5,
+		DiscountAmount: domain.NewMoney(10000),
+		Promotion:      &domain.Promotion{ID: 1, Code: "HEMAT20"},
+	}
+}
+
+// expectRedemptionUsage mengatur expectation lock promo dan hitung pemakaian
+func expectRedemptionUsage(mock pgxmock.PgxPoolIface, maxUses, maxUsesPerUser *int, used, usedByUser int) {
+	mock.ExpectQuery("SELECT max_uses, max_uses_per_user FROM promotions WHERE id = \\$1 FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"max_uses", "max_uses_per_user"}).AddRow(maxUses, maxUsesPerUser))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COUNT\\(\\*\\) FILTER \\(WHERE user_id = \\$2\\) FROM promotion_redemptions").
+		WithArgs(1, 5).
+		WillReturnRows(pgxmock.NewRows([]string{"count", "count"}).AddRow(used, usedByUser))
+}
+
+func TestPromotionRepository_ApplyToBooking(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	now := time.Now()
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(50000)}
+	redemption := testRedemption()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price = total_price - \\$2").
+		WithArgs(9, domain.NewMoney(10000), "HEMAT20", pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"total_price", "updated_at"}).AddRow(domain.NewMoney(40000), now))
+	expectRedemptionUsage(mock, ptr(100), ptr(1), 99, 0)
+	mock.ExpectQuery("INSERT INTO promotion_redemptions").
+		WithArgs(1, 5, 9, domain.NewMoney(10000), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+	mock.ExpectCommit()
+
+	err = repo.ApplyToBooking(context.Background(), booking, redemption)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(40000), booking.TotalPrice)
+	assert.Equal(t, domain.NewMoney(10000), booking.DiscountAmount)
+	assert.Equal(t, "HEMAT20", *booking.PromoCode)
+	assert.Equal(t, 3, redemption.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_ApplyToBooking_Exhausted(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	now := time.Now()
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(50000)}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price").
+		WithArgs(9, domain.NewMoney(10000), "HEMAT20", pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"total_price", "updated_at"}).AddRow(domain.NewMoney(40000), now))
+	expectRedemptionUsage(mock, ptr(100), nil, 100, 0)
+	mock.ExpectRollback()
+
+	err = repo.ApplyToBooking(context.Background(), booking, testRedemption())
+
+	assert.ErrorIs(t, err, ErrPromotionExhausted)
+	// Nothing is applied when the transaction rolls back
+	assert.Nil(t, booking.PromoCode)
+	assert.Equal(t, domain.Money(0), booking.DiscountAmount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_ApplyToBooking_UserLimit(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	now := time.Now()
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(50000)}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price").
+		WithArgs(9, domain.NewMoney(10000), "HEMAT20", pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"total_price", "updated_at"}).AddRow(domain.NewMoney(40000), now))
+	expectRedemptionUsage(mock, nil, ptr(1), 10, 1)
+	mock.ExpectRollback()
+
+	err = repo.ApplyToBooking(context.Background(), booking, testRedemption())
+
+	assert.ErrorIs(t, err, ErrPromotionUserLimit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_ApplyToBooking_AlreadyApplied(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price").
+		WithArgs(9, domain.NewMoney(10000), "HEMAT20", pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"total_price", "updated_at"}))
+	mock.ExpectRollback()
+
+	err = repo.ApplyToBooking(context.Background(), &domain.Booking{ID: 9, UserID: 5}, testRedemption())
+
+	assert.ErrorIs(t, err, ErrPromotionAlreadyApplied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_RemoveFromBooking(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	now := time.Now()
+	code := "HEMAT20"
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(40000), DiscountAmount: domain.NewMoney(10000), PromoCode: &code}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price = total_price \\+ discount_amount").
+		WithArgs(9, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"total_price", "updated_at"}).AddRow(domain.NewMoney(50000), now))
+	mock.ExpectExec("DELETE FROM promotion_redemptions WHERE booking_id = \\$1").
+		WithArgs(9).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectCommit()
+
+	err = repo.RemoveFromBooking(context.Background(), booking)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50000), booking.TotalPrice)
+	assert.Equal(t, domain.Money(0), booking.DiscountAmount)
+	assert.Nil(t, booking.PromoCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromotionRepository_RemoveFromBooking_NotPending(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewPromotionRepository(mock)
+
+	code := "HEMAT20"
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(40000), DiscountAmount: domain.NewMoney(10000), PromoCode: &code}
+
+	// Expired meanwhile: the expiry already released the redemption
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE bookings SET total_price = total_price \\+ discount_amount").
+		WithArgs(9, pgxmock.AnyArg()).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = repo.RemoveFromBooking(context.Background(), booking)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HEMAT20", *booking.PromoCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
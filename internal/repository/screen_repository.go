@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+)
+
+// ErrScreenExists dikembalikan ketika nama studio sudah dipakai di cinema yang sama
+var ErrScreenExists = errors.New("screen with this name already exists in the cinema")
+
+type ScreenRepository interface {
+	GetByID(ctx context.Context, id int) (*domain.Screen, error)
+	Create(ctx context.Context, screen *domain.Screen) error
+	ImportLayout(ctx context.Context, screen *domain.Screen, layout *domain.SeatLayout) ([]*domain.Seat, error)
+}
+
+type screenRepository struct {
+	db PgxPool
+}
+
+func NewScreenRepository(db PgxPool) ScreenRepository {
+	return &screenRepository{db: db}
+}
+
+// GetByID mengambil studio beserta grid denah kursinya
+func (r *screenRepository) GetByID(ctx context.Context, id int) (*domain.Screen, error) {
+	query := `
+		SELECT id, cinema_id, name, layout, created_at
+		FROM screens
+		WHERE id = $1
+	`
+
+	var screen domain.Screen
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&screen.ID,
+		&screen.CinemaID,
+		&screen.Name,
+		&screen.Layout,
+		&screen.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("screen not found")
+	}
+
+	return &screen, nil
+}
+
+// Create menambah studio baru (tanpa denah kursi) ke cinema
+func (r *screenRepository) Create(ctx context.Context, screen *domain.Screen) error {
+	query := `
+		INSERT INTO screens (cinema_id, name, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, screen.CinemaID, screen.Name, time.Now()).Scan(&screen.ID, &screen.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err, "screens_cinema_id_name_key") {
+			return ErrScreenExists
+		}
+		return fmt.Errorf("failed to create screen: %w", err)
+	}
+
+	return nil
+}
+
+// ImportLayout mengganti denah kursi studio dalam satu transaksi.
+// Kursi dicocokkan berdasarkan baris dan nomor, jadi booking lama tetap menunjuk kursi yang sama.
+// Kursi yang tidak ada lagi di denah dihapus, atau diblokir jika sudah pernah dibooking.
+func (r *screenRepository) ImportLayout(ctx context.Context, screen *domain.Screen, layout *domain.SeatLayout) ([]*domain.Seat, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE screens SET layout = $2 WHERE id = $1", screen.ID, layout.Grid()); err != nil {
+		return nil, fmt.Errorf("failed to save screen layout: %w", err)
+	}
+
+	upsertQuery := `
+		INSERT INTO seats (cinema_id, screen_id, seat_row, seat_number, seat_type, grid_x, grid_y, is_accessible, is_blocked, pair_seat_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULL)
+		ON CONFLICT (screen_id, seat_row, seat_number) DO UPDATE SET
+			seat_type = EXCLUDED.seat_type,
+			grid_x = EXCLUDED.grid_x,
+			grid_y = EXCLUDED.grid_y,
+			is_accessible = EXCLUDED.is_accessible,
+			is_blocked = EXCLUDED.is_blocked,
+			pair_seat_id = NULL,
+			deleted_at = NULL
+		RETURNING id, created_at
+	`
+
+	seats := make([]*domain.Seat, 0, len(layout.Seats))
+	seatIDs := make([]int, 0, len(layout.Seats))
+	byLabel := make(map[string]*domain.Seat, len(layout.Seats))
+	for _, ls := range layout.Seats {
+		x, y := ls.X, ls.Y
+		seat := &domain.Seat{
+			CinemaID:     screen.CinemaID,
+			ScreenID:     screen.ID,
+			SeatRow:      ls.Row,
+			SeatNumber:   ls.Number,
+			SeatType:     ls.Type,
+			GridX:        &x,
+			GridY:        &y,
+			IsAccessible: ls.Accessible,
+			IsBlocked:    ls.Blocked,
+		}
+
+		err := tx.QueryRow(
+			ctx,
+			upsertQuery,
+			seat.CinemaID,
+			seat.ScreenID,
+			seat.SeatRow,
+			seat.SeatNumber,
+			seat.SeatType,
+			x,
+			y,
+			seat.IsAccessible,
+			seat.IsBlocked,
+		).Scan(&seat.ID, &seat.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save seat %s: %w", ls.Label(), err)
+		}
+
+		seats = append(seats, seat)
+		seatIDs = append(seatIDs, seat.ID)
+		byLabel[ls.Label()] = seat
+	}
+
+	// Link couple seats once every seat has an ID
+	for _, ls := range layout.Seats {
+		if ls.Pair == "" {
+			continue
+		}
+		seat, pair := byLabel[ls.Label()], byLabel[ls.Pair]
+		if pair == nil {
+			return nil, fmt.Errorf("pair seat %s not found in layout", ls.Pair)
+		}
+		if _, err := tx.Exec(ctx, "UPDATE seats SET pair_seat_id = $2 WHERE id = $1", seat.ID, pair.ID); err != nil {
+			return nil, fmt.Errorf("failed to pair seat %s: %w", ls.Label(), err)
+		}
+		pairID := pair.ID
+		seat.PairSeatID = &pairID
+	}
+
+	// Seats dropped from the map: delete if never booked, otherwise keep them for history but stop selling
+	deleteQuery := `
+		DELETE FROM seats
+		WHERE screen_id = $1
+		  AND id <> ALL($2)
+		  AND NOT EXISTS (SELECT 1 FROM booking_seats bs WHERE bs.seat_id = seats.id)
+	`
+	if _, err := tx.Exec(ctx, deleteQuery, screen.ID, seatIDs); err != nil {
+		return nil, fmt.Errorf("failed to remove old seats: %w", err)
+	}
+
+	retireQuery := `
+		UPDATE seats
+		SET is_blocked = TRUE, grid_x = NULL, grid_y = NULL, pair_seat_id = NULL
+		WHERE screen_id = $1
+		  AND id <> ALL($2)
+	`
+	if _, err := tx.Exec(ctx, retireQuery, screen.ID, seatIDs); err != nil {
+		return nil, fmt.Errorf("failed to block old seats: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return seats, nil
+}
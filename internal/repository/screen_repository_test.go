@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSeatLayout() *domain.SeatLayout {
+	return &domain.SeatLayout{
+		Rows:    2,
+		Columns: 4,
+		Aisles:  domain.SeatLayoutAisles{Columns: []int{1}},
+		Seats: []*domain.SeatLayoutSeat{
+			{Row: "A", Number: 1, X: 0, Y: 0, Type: "regular", Accessible: true},
+			{Row: "B", Number: 1, X: 2, Y: 1, Type: "premium", Pair: "B2"},
+			{Row: "B", Number: 2, X: 3, Y: 1, Type: "premium", Pair: "B1"},
+		},
+	}
+}
+
+func TestScreenRepository_GetByID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewScreenRepository(mock)
+
+	now := time.Now()
+	layout := testSeatLayout().Grid()
+	rows := pgxmock.NewRows([]string{"id", "cinema_id", "name", "layout", "created_at"}).
+		AddRow(2, 1, "Studio 2", layout, now)
+
+	mock.ExpectQuery("SELECT (.+) FROM screens WHERE id").
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	screen, err := repo.GetByID(context.Background(), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, screen.CinemaID)
+	assert.Equal(t, "Studio 2", screen.Name)
+	require.NotNil(t, screen.Layout)
+	assert.Equal(t, 4, screen.Layout.Columns)
+	assert.Nil(t, screen.Layout.Seats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScreenRepository_GetByID_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewScreenRepository(mock)
+
+	mock.ExpectQuery("SELECT (.+) FROM screens WHERE id").
+		WithArgs(999).
+		WillReturnError(pgx.ErrNoRows)
+
+	screen, err := repo.GetByID(context.Background(), 999)
+
+	assert.Error(t, err)
+	assert.Nil(t, screen)
+	assert.Contains(t, err.Error(), "screen not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScreenRepository_ImportLayout(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewScreenRepository(mock)
+
+	now := time.Now()
+	screen := &domain.Screen{ID: 2, CinemaID: 1, Name: "Studio 2"}
+	layout := testSeatLayout()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE screens SET layout").
+		WithArgs(2, layout.Grid()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	for i, ls := range layout.Seats {
+		mock.ExpectQuery("INSERT INTO seats (.+) ON CONFLICT \\(screen_id, seat_row, seat_number\\) DO UPDATE").
+			WithArgs(1, 2, ls.Row, ls.Number, ls.Type, ls.X, ls.Y, ls.Accessible, ls.Blocked).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(10+i, now))
+	}
+	mock.ExpectExec("UPDATE seats SET pair_seat_id").
+		WithArgs(11, 12).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE seats SET pair_seat_id").
+		WithArgs(12, 11).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("DELETE FROM seats WHERE screen_id = \\$1 AND id <> ALL\\(\\$2\\)").
+		WithArgs(2, []int{10, 11, 12}).
+		WillReturnResult(pgxmock.NewResult("DELETE", 4))
+	mock.ExpectExec("UPDATE seats SET is_blocked = TRUE").
+		WithArgs(2, []int{10, 11, 12}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectCommit()
+
+	seats, err := repo.ImportLayout(context.Background(), screen, layout)
+
+	assert.NoError(t, err)
+	require.Len(t, seats, 3)
+	assert.Equal(t, 10, seats[0].ID)
+	assert.Equal(t, 2, seats[0].ScreenID)
+	assert.True(t, seats[0].IsAccessible)
+	assert.Nil(t, seats[0].PairSeatID)
+	assert.Equal(t, 3, *seats[2].GridX)
+	assert.Equal(t, 12, *seats[1].PairSeatID)
+	assert.Equal(t, 11, *seats[2].PairSeatID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScreenRepository_ImportLayout_SeatError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewScreenRepository(mock)
+
+	screen := &domain.Screen{ID: 2, CinemaID: 1}
+	layout := testSeatLayout()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE screens SET layout").
+		WithArgs(2, layout.Grid()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery("INSERT INTO seats").
+		WithArgs(1, 2, "A", 1, "regular", 0, 0, true, false).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	seats, err := repo.ImportLayout(context.Background(), screen, layout)
+
+	assert.Error(t, err)
+	assert.Nil(t, seats)
+	assert.Contains(t, err.Error(), "A1")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,17 +2,38 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrSeatExists dikembalikan ketika baris dan nomor kursi sudah dipakai di studio yang sama
+	ErrSeatExists = errors.New("seat already exists on this screen")
+	// ErrSeatHasBookings dikembalikan ketika kursi yang akan dihapus masih dibooking untuk showtime mendatang
+	ErrSeatHasBookings = errors.New("seat still has active bookings for upcoming showtimes")
+	// ErrSeatPositionTaken dikembalikan ketika sel grid sudah ditempati kursi lain di studio yang sama
+	ErrSeatPositionTaken = errors.New("grid position is already taken by another seat")
 )
 
 type SeatRepository interface {
 	GetByCinemaID(ctx context.Context, cinemaID int) ([]*domain.Seat, error)
+	GetByScreenID(ctx context.Context, screenID int) ([]*domain.Seat, error)
 	GetByID(ctx context.Context, id int) (*domain.Seat, error)
-	GetAvailableSeats(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatAvailability, error)
+	GetAvailableSeats(ctx context.Context, screenID, showtimeID int) ([]*domain.SeatAvailability, error)
+	Create(ctx context.Context, seat *domain.Seat) error
+	Update(ctx context.Context, seat *domain.Seat) error
+	Delete(ctx context.Context, id int) error
 }
 
+// seatColumns adalah kolom seats termasuk posisi di denah kursi
+const seatColumns = `id, cinema_id, screen_id, seat_row, seat_number, seat_type,
+		grid_x, grid_y, is_accessible, is_blocked, pair_seat_id, created_at`
+
 type seatRepository struct {
 	db PgxPool
 }
@@ -21,15 +42,32 @@ func NewSeatRepository(db PgxPool) SeatRepository {
 	return &seatRepository{db: db}
 }
 
+// GetByCinemaID mengambil kursi dari semua studio di cinema
 func (r *seatRepository) GetByCinemaID(ctx context.Context, cinemaID int) ([]*domain.Seat, error) {
 	query := `
-		SELECT id, cinema_id, seat_row, seat_number, seat_type, created_at
+		SELECT ` + seatColumns + `
 		FROM seats
-		WHERE cinema_id = $1
+		WHERE cinema_id = $1 AND deleted_at IS NULL
+		ORDER BY screen_id, seat_row, seat_number
+	`
+
+	return r.list(ctx, query, cinemaID)
+}
+
+// GetByScreenID mengambil kursi di satu studio
+func (r *seatRepository) GetByScreenID(ctx context.Context, screenID int) ([]*domain.Seat, error) {
+	query := `
+		SELECT ` + seatColumns + `
+		FROM seats
+		WHERE screen_id = $1 AND deleted_at IS NULL
 		ORDER BY seat_row, seat_number
 	`
 
-	rows, err := r.db.Query(ctx, query, cinemaID)
+	return r.list(ctx, query, screenID)
+}
+
+func (r *seatRepository) list(ctx context.Context, query string, arg interface{}) ([]*domain.Seat, error) {
+	rows, err := r.db.Query(ctx, query, arg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get seats: %w", err)
 	}
@@ -38,39 +76,28 @@ func (r *seatRepository) GetByCinemaID(ctx context.Context, cinemaID int) ([]*do
 	var seats []*domain.Seat
 	for rows.Next() {
 		var seat domain.Seat
-		err := rows.Scan(
-			&seat.ID,
-			&seat.CinemaID,
-			&seat.SeatRow,
-			&seat.SeatNumber,
-			&seat.SeatType,
-			&seat.CreatedAt,
-		)
-		if err != nil {
+		if err := scanSeat(rows, &seat); err != nil {
 			return nil, fmt.Errorf("failed to scan seat: %w", err)
 		}
 		seats = append(seats, &seat)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating seats: %w", err)
+	}
+
 	return seats, nil
 }
 
 func (r *seatRepository) GetByID(ctx context.Context, id int) (*domain.Seat, error) {
 	query := `
-		SELECT id, cinema_id, seat_row, seat_number, seat_type, created_at
+		SELECT ` + seatColumns + `
 		FROM seats
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var seat domain.Seat
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&seat.ID,
-		&seat.CinemaID,
-		&seat.SeatRow,
-		&seat.SeatNumber,
-		&seat.SeatType,
-		&seat.CreatedAt,
-	)
+	err := scanSeat(r.db.QueryRow(ctx, query, id), &seat)
 
 	if err != nil {
 		return nil, fmt.Errorf("seat not found")
@@ -79,20 +106,22 @@ func (r *seatRepository) GetByID(ctx context.Context, id int) (*domain.Seat, err
 	return &seat, nil
 }
 
-func (r *seatRepository) GetAvailableSeats(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatAvailability, error) {
+// GetAvailableSeats mengambil kursi studio beserta status booking untuk showtime
+func (r *seatRepository) GetAvailableSeats(ctx context.Context, screenID, showtimeID int) ([]*domain.SeatAvailability, error) {
 	query := `
 		SELECT
-			s.id, s.cinema_id, s.seat_row, s.seat_number, s.seat_type, s.created_at,
-			CASE WHEN b.id IS NOT NULL THEN true ELSE false END as is_booked
+			s.id, s.cinema_id, s.screen_id, s.seat_row, s.seat_number, s.seat_type,
+			s.grid_x, s.grid_y, s.is_accessible, s.is_blocked, s.pair_seat_id, s.created_at,
+			CASE WHEN bs.id IS NOT NULL THEN true ELSE false END as is_booked
 		FROM seats s
-		LEFT JOIN bookings b ON s.id = b.seat_id
-			AND b.showtime_id = $2
-			AND b.status IN ('pending', 'confirmed')
-		WHERE s.cinema_id = $1
+		LEFT JOIN booking_seats bs ON s.id = bs.seat_id
+			AND bs.showtime_id = $2
+			AND bs.released_at IS NULL
+		WHERE s.screen_id = $1 AND s.deleted_at IS NULL
 		ORDER BY s.seat_row, s.seat_number
 	`
 
-	rows, err := r.db.Query(ctx, query, cinemaID, showtimeID)
+	rows, err := r.db.Query(ctx, query, screenID, showtimeID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get seat availability: %w", err)
 	}
@@ -103,16 +132,7 @@ func (r *seatRepository) GetAvailableSeats(ctx context.Context, cinemaID, showti
 		var seat domain.Seat
 		var isBooked bool
 
-		err := rows.Scan(
-			&seat.ID,
-			&seat.CinemaID,
-			&seat.SeatRow,
-			&seat.SeatNumber,
-			&seat.SeatType,
-			&seat.CreatedAt,
-			&isBooked,
-		)
-		if err != nil {
+		if err := scanSeat(rows, &seat, &isBooked); err != nil {
 			return nil, fmt.Errorf("failed to scan seat availability: %w", err)
 		}
 
@@ -123,5 +143,148 @@ func (r *seatRepository) GetAvailableSeats(ctx context.Context, cinemaID, showti
 		})
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating seat availability: %w", err)
+	}
+
 	return seatAvailability, nil
 }
+
+// Create menambah kursi ke studio. Kursi yang pernah dihapus dengan baris dan nomor yang sama dipakai lagi.
+func (r *seatRepository) Create(ctx context.Context, seat *domain.Seat) error {
+	query := `
+		INSERT INTO seats (cinema_id, screen_id, seat_row, seat_number, seat_type, grid_x, grid_y, is_accessible, is_blocked, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (screen_id, seat_row, seat_number) DO UPDATE SET
+			seat_type = EXCLUDED.seat_type,
+			grid_x = EXCLUDED.grid_x,
+			grid_y = EXCLUDED.grid_y,
+			is_accessible = EXCLUDED.is_accessible,
+			is_blocked = EXCLUDED.is_blocked,
+			pair_seat_id = NULL,
+			deleted_at = NULL
+		WHERE seats.deleted_at IS NOT NULL
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		seat.CinemaID,
+		seat.ScreenID,
+		seat.SeatRow,
+		seat.SeatNumber,
+		seat.SeatType,
+		seat.GridX,
+		seat.GridY,
+		seat.IsAccessible,
+		seat.IsBlocked,
+		time.Now(),
+	).Scan(&seat.ID, &seat.CreatedAt)
+	if err != nil {
+		// The conflicting seat is still live, so the upsert returned nothing
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrSeatExists
+		}
+		if isUniqueViolation(err, "seats_screen_id_grid_position_key") {
+			return ErrSeatPositionTaken
+		}
+		return fmt.Errorf("failed to create seat: %w", err)
+	}
+
+	return nil
+}
+
+// Update mengubah tipe, posisi, dan status kursi. Baris dan nomor kursi tidak bisa diubah.
+// Status blokir couple seat ikut diterapkan ke pasangannya supaya tidak terjual setengah.
+func (r *seatRepository) Update(ctx context.Context, seat *domain.Seat) error {
+	query := `
+		WITH updated AS (
+			UPDATE seats
+			SET seat_type = $2, grid_x = $3, grid_y = $4, is_accessible = $5, is_blocked = $6
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING pair_seat_id
+		), paired AS (
+			UPDATE seats p
+			SET is_blocked = $6
+			FROM updated u
+			WHERE p.id = u.pair_seat_id
+			  AND p.deleted_at IS NULL
+		)
+		SELECT COUNT(*) FROM updated
+	`
+
+	var updated int
+	err := r.db.QueryRow(ctx, query, seat.ID, seat.SeatType, seat.GridX, seat.GridY, seat.IsAccessible, seat.IsBlocked).Scan(&updated)
+	if err != nil {
+		if isUniqueViolation(err, "seats_screen_id_grid_position_key") {
+			return ErrSeatPositionTaken
+		}
+		return fmt.Errorf("failed to update seat: %w", err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("seat %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// Delete menandai kursi sebagai terhapus (soft delete) dan melepas pasangan couple seat-nya.
+// Ditolak selama kursi masih dibooking untuk showtime yang belum mulai.
+func (r *seatRepository) Delete(ctx context.Context, id int) error {
+	query := `
+		WITH deleted AS (
+			UPDATE seats
+			SET deleted_at = $2, pair_seat_id = NULL, grid_x = NULL, grid_y = NULL -- free the grid cell for a new seat
+			WHERE id = $1
+			  AND deleted_at IS NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM booking_seats bs
+				JOIN bookings b ON b.id = bs.booking_id
+				JOIN showtimes s ON s.id = bs.showtime_id
+				JOIN cinemas c ON c.id = s.cinema_id
+				WHERE bs.seat_id = seats.id
+				  AND bs.released_at IS NULL
+				  AND b.status IN ('pending', 'confirmed')
+				  AND (s.show_date + s.show_time) AT TIME ZONE c.timezone >= NOW()
+			  )
+			RETURNING id
+		), unpaired AS (
+			UPDATE seats
+			SET pair_seat_id = NULL
+			WHERE pair_seat_id IN (SELECT id FROM deleted)
+		)
+		SELECT COUNT(*) FROM deleted
+	`
+
+	var deleted int
+	if err := r.db.QueryRow(ctx, query, id, time.Now()).Scan(&deleted); err != nil {
+		return fmt.Errorf("failed to delete seat: %w", err)
+	}
+
+	if deleted == 0 {
+		return softDeleteMiss(ctx, r.db, "seats", "seat", id, ErrSeatHasBookings)
+	}
+
+	return nil
+}
+
+// scanSeat membaca satu baris seatColumns, diikuti kolom tambahan (extra) jika ada
+func scanSeat(row pgx.Row, seat *domain.Seat, extra ...interface{}) error {
+	dest := []interface{}{
+		&seat.ID,
+		&seat.CinemaID,
+		&seat.ScreenID,
+		&seat.SeatRow,
+		&seat.SeatNumber,
+		&seat.SeatType,
+		&seat.GridX,
+		&seat.GridY,
+		&seat.IsAccessible,
+		&seat.IsBlocked,
+		&seat.PairSeatID,
+		&seat.CreatedAt,
+	}
+	return row.Scan(append(dest, extra...)...)
+}
@@ -5,12 +5,20 @@ import (
 	"testing"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+var seatMockColumns = []string{
+	"id", "cinema_id", "screen_id", "seat_row", "seat_number", "seat_type",
+	"grid_x", "grid_y", "is_accessible", "is_blocked", "pair_seat_id", "created_at",
+}
+
 func TestSeatRepository_GetByCinemaID(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -19,10 +27,10 @@ func TestSeatRepository_GetByCinemaID(t *testing.T) {
 	repo := NewSeatRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "cinema_id", "seat_row", "seat_number", "seat_type", "created_at"}).
-		AddRow(1, 1, "A", 1, "regular", now).
-		AddRow(2, 1, "A", 2, "regular", now).
-		AddRow(3, 1, "B", 1, "vip", now)
+	rows := pgxmock.NewRows(seatMockColumns).
+		AddRow(1, 1, 1, "A", 1, "regular", nil, nil, false, false, nil, now).
+		AddRow(2, 1, 1, "A", 2, "regular", nil, nil, false, false, nil, now).
+		AddRow(3, 1, 1, "B", 1, "vip", nil, nil, false, false, nil, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM seats WHERE cinema_id").
 		WithArgs(1).
@@ -44,7 +52,7 @@ func TestSeatRepository_GetByCinemaID_Empty(t *testing.T) {
 
 	repo := NewSeatRepository(mock)
 
-	rows := pgxmock.NewRows([]string{"id", "cinema_id", "seat_row", "seat_number", "seat_type", "created_at"})
+	rows := pgxmock.NewRows(seatMockColumns)
 
 	mock.ExpectQuery("SELECT (.+) FROM seats WHERE cinema_id").
 		WithArgs(999).
@@ -75,6 +83,54 @@ func TestSeatRepository_GetByCinemaID_Error(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSeatRepository_GetByCinemaID_RowError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows(seatMockColumns).
+		AddRow(1, 1, 1, "A", 1, "regular", nil, nil, false, false, nil, now).
+		AddRow(2, 1, 1, "A", 2, "regular", nil, nil, false, false, nil, now).
+		RowError(2, assert.AnError) // stream breaks after the rows already read
+
+	mock.ExpectQuery("SELECT (.+) FROM seats WHERE cinema_id").
+		WithArgs(1).
+		WillReturnRows(rows)
+
+	seats, err := repo.GetByCinemaID(context.Background(), 1)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, seats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_GetByScreenID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows(seatMockColumns).
+		AddRow(21, 1, 2, "A", 1, "regular", nil, nil, false, false, nil, now).
+		AddRow(22, 1, 2, "A", 2, "regular", nil, nil, false, false, nil, now)
+
+	mock.ExpectQuery("SELECT (.+) FROM seats WHERE screen_id").
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	seats, err := repo.GetByScreenID(context.Background(), 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, seats, 2)
+	assert.Equal(t, 2, seats[0].ScreenID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSeatRepository_GetByID(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -83,8 +139,8 @@ func TestSeatRepository_GetByID(t *testing.T) {
 	repo := NewSeatRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "cinema_id", "seat_row", "seat_number", "seat_type", "created_at"}).
-		AddRow(10, 1, "C", 5, "vip", now)
+	rows := pgxmock.NewRows(seatMockColumns).
+		AddRow(10, 1, 1, "C", 5, "vip", nil, nil, false, false, nil, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM seats WHERE id").
 		WithArgs(10).
@@ -127,12 +183,12 @@ func TestSeatRepository_GetAvailableSeats(t *testing.T) {
 	repo := NewSeatRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "cinema_id", "seat_row", "seat_number", "seat_type", "created_at", "is_booked"}).
-		AddRow(1, 1, "A", 1, "regular", now, false).
-		AddRow(2, 1, "A", 2, "regular", now, true).
-		AddRow(3, 1, "B", 1, "vip", now, false)
+	rows := pgxmock.NewRows(append(seatMockColumns, "is_booked")).
+		AddRow(1, 1, 1, "A", 1, "regular", ptr(0), ptr(0), false, false, ptr(2), now, false).
+		AddRow(2, 1, 1, "A", 2, "regular", ptr(1), ptr(0), false, false, ptr(1), now, true).
+		AddRow(3, 1, 1, "B", 1, "vip", ptr(0), ptr(1), true, false, nil, now, false)
 
-	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN bookings").
+	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN booking_seats (.+) WHERE s.screen_id = \\$1").
 		WithArgs(1, 10).
 		WillReturnRows(rows)
 
@@ -143,6 +199,10 @@ func TestSeatRepository_GetAvailableSeats(t *testing.T) {
 	assert.False(t, availableSeats[0].IsBooked)
 	assert.True(t, availableSeats[1].IsBooked)
 	assert.Equal(t, 10, availableSeats[0].ShowtimeID)
+	assert.Equal(t, 1, *availableSeats[1].Seat.GridX)
+	assert.Equal(t, 2, *availableSeats[0].Seat.PairSeatID)
+	assert.True(t, availableSeats[2].Seat.IsAccessible)
+	assert.Nil(t, availableSeats[2].Seat.PairSeatID)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -154,11 +214,11 @@ func TestSeatRepository_GetAvailableSeats_AllBooked(t *testing.T) {
 	repo := NewSeatRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "cinema_id", "seat_row", "seat_number", "seat_type", "created_at", "is_booked"}).
-		AddRow(1, 1, "A", 1, "regular", now, true).
-		AddRow(2, 1, "A", 2, "regular", now, true)
+	rows := pgxmock.NewRows(append(seatMockColumns, "is_booked")).
+		AddRow(1, 1, 1, "A", 1, "regular", nil, nil, false, false, nil, now, true).
+		AddRow(2, 1, 1, "A", 2, "regular", nil, nil, false, false, nil, now, true)
 
-	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN bookings").
+	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN booking_seats").
 		WithArgs(1, 10).
 		WillReturnRows(rows)
 
@@ -178,7 +238,7 @@ func TestSeatRepository_GetAvailableSeats_Error(t *testing.T) {
 
 	repo := NewSeatRepository(mock)
 
-	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN bookings").
+	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN booking_seats").
 		WithArgs(1, 10).
 		WillReturnError(assert.AnError)
 
@@ -188,3 +248,118 @@ func TestSeatRepository_GetAvailableSeats_Error(t *testing.T) {
 	assert.Nil(t, availableSeats)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSeatRepository_GetAvailableSeats_RowError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows(append(seatMockColumns, "is_booked")).
+		AddRow(1, 1, 1, "A", 1, "regular", nil, nil, false, false, nil, now, false).
+		AddRow(2, 1, 1, "A", 2, "regular", nil, nil, false, false, nil, now, true).
+		RowError(2, assert.AnError)
+
+	mock.ExpectQuery("SELECT (.+) FROM seats s LEFT JOIN booking_seats").
+		WithArgs(1, 10).
+		WillReturnRows(rows)
+
+	availableSeats, err := repo.GetAvailableSeats(context.Background(), 1, 10)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, availableSeats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_Create_Exists(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	// baris aktif dengan posisi yang sama membuat upsert tidak mengembalikan apa pun
+	mock.ExpectQuery("INSERT INTO seats").
+		WithArgs(1, 1, "A", 1, "regular", ptr(0), ptr(2), false, false, pgxmock.AnyArg()).
+		WillReturnError(pgx.ErrNoRows)
+
+	seat := &domain.Seat{CinemaID: 1, ScreenID: 1, SeatRow: "A", SeatNumber: 1, SeatType: "regular", GridX: ptr(0), GridY: ptr(2)}
+	err = repo.Create(context.Background(), seat)
+
+	assert.ErrorIs(t, err, ErrSeatExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_Create_GridPositionTaken(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	conflict := &pgconn.PgError{Code: "23505", ConstraintName: "seats_screen_id_grid_position_key"}
+	mock.ExpectQuery("INSERT INTO seats").
+		WithArgs(1, 1, "A", 3, "regular", ptr(0), ptr(2), false, false, pgxmock.AnyArg()).
+		WillReturnError(conflict)
+
+	seat := &domain.Seat{CinemaID: 1, ScreenID: 1, SeatRow: "A", SeatNumber: 3, SeatType: "regular", GridX: ptr(0), GridY: ptr(2)}
+	err = repo.Create(context.Background(), seat)
+
+	assert.ErrorIs(t, err, ErrSeatPositionTaken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_Update(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	// pasangan couple seat ikut diblokir di query yang sama
+	mock.ExpectQuery("WITH updated AS").
+		WithArgs(5, "premium", ptr(3), ptr(1), false, true).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+	seat := &domain.Seat{ID: 5, SeatType: "premium", GridX: ptr(3), GridY: ptr(1), IsBlocked: true}
+	err = repo.Update(context.Background(), seat)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_Update_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	mock.ExpectQuery("WITH updated AS").
+		WithArgs(5, "regular", (*int)(nil), (*int)(nil), false, false).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+
+	err = repo.Update(context.Background(), &domain.Seat{ID: 5, SeatType: "regular"})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeatRepository_Delete(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewSeatRepository(mock)
+
+	mock.ExpectQuery("WITH deleted AS").
+		WithArgs(5, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+	err = repo.Delete(context.Background(), 5)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
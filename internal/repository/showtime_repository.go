@@ -2,18 +2,57 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	// ErrAmbiguousShowtime dikembalikan ketika cinema, tanggal, dan jam cocok dengan lebih dari satu showtime
+	ErrAmbiguousShowtime = errors.New("more than one showtime matches this cinema, date and time")
+	// ErrShowtimeExists dikembalikan ketika film yang sama sudah dijadwalkan di studio dan jam yang sama
+	ErrShowtimeExists = errors.New("showtime already exists for this movie, screen, date and time")
+	// ErrShowtimeHasBookings dikembalikan ketika showtime yang akan dihapus masih punya booking aktif
+	ErrShowtimeHasBookings = errors.New("showtime still has active bookings")
+	// ErrShowtimeOverlap dikembalikan ketika jadwal bentrok dengan showtime lain di studio yang sama
+	ErrShowtimeOverlap = errors.New("showtime overlaps another showtime on this screen")
+)
+
 type ShowtimeRepository interface {
 	GetByCinemaDateTime(ctx context.Context, cinemaID int, date, time string) (*domain.Showtime, error)
 	GetByID(ctx context.Context, id int) (*domain.Showtime, error)
+	GetUpcomingByMovieID(ctx context.Context, movieID int, from time.Time) ([]*domain.Showtime, error)
+	GetByCinemaAndDate(ctx context.Context, cinemaID int, date time.Time) ([]*domain.ShowtimeAvailability, error)
+	GetByMovieAndDateRange(ctx context.Context, movieID int, from, to time.Time) ([]*domain.ShowtimeAvailability, error)
+	Create(ctx context.Context, showtime *domain.Showtime) error
+	Update(ctx context.Context, showtime *domain.Showtime) error
+	Delete(ctx context.Context, id int) error
+	HasActiveBookings(ctx context.Context, id int) (bool, error)
+	GetOverlapping(ctx context.Context, screenID int, startsAt, freeAt time.Time, excludeID int) ([]*domain.Showtime, error)
 }
 
+// showtimeDetailColumns adalah kolom showtime beserta cinema, screen dan movie untuk scanShowtimeDetail
+const showtimeDetailColumns = `s.id, s.cinema_id, s.screen_id, s.movie_id, s.show_date, s.show_time, s.ends_at, s.cleaning_buffer_minutes, s.price, s.created_at,
+		       c.id, c.name, c.location, c.description, c.timezone, c.created_at,
+		       sc.id, sc.cinema_id, sc.name, sc.created_at,
+		       m.id, m.title, m.description, m.duration, m.genre, m.poster_url, m.rating, m.created_at`
+
+// showtimeDetailJoins menggabungkan showtimes (alias s) dengan cinema, screen dan movie
+const showtimeDetailJoins = `
+		FROM showtimes s
+		JOIN cinemas c ON s.cinema_id = c.id
+		JOIN screens sc ON s.screen_id = sc.id
+		JOIN movies m ON s.movie_id = m.id`
+
+// showtimeAvailabilityColumns menghitung total kursi studio yang dijual dan kursi yang masih ditahan booking aktif
+const showtimeAvailabilityColumns = `
+		(SELECT COUNT(*) FROM seats st WHERE st.screen_id = s.screen_id AND NOT st.is_blocked AND st.deleted_at IS NULL) AS total_seats,
+		(SELECT COUNT(*) FROM booking_seats bs WHERE bs.showtime_id = s.id AND bs.released_at IS NULL) AS booked_seats`
+
 type showtimeRepository struct {
 	db PgxPool
 }
@@ -22,88 +61,313 @@ func NewShowtimeRepository(db PgxPool) ShowtimeRepository {
 	return &showtimeRepository{db: db}
 }
 
-func (r *showtimeRepository) GetByCinemaDateTime(ctx context.Context, cinemaID int, date, time string) (*domain.Showtime, error) {
-	// Query dengan casting parameter ke DATE dan TIME
+func (r *showtimeRepository) GetByCinemaDateTime(ctx context.Context, cinemaID int, date, showTime string) (*domain.Showtime, error) {
+	// Query dengan casting parameter ke DATE dan TIME.
+	// Beberapa film bisa tayang di cinema yang sama pada jam yang sama, jadi ambil 2 baris untuk mendeteksi ambigu.
 	query := `
-		SELECT s.id, s.cinema_id, s.movie_id, s.show_date, s.show_time, s.price, s.created_at,
-		       c.id, c.name, c.location, c.description, c.created_at,
-		       m.id, m.title, m.description, m.duration, m.genre, m.poster_url, m.rating, m.created_at
-		FROM showtimes s
-		JOIN cinemas c ON s.cinema_id = c.id
-		JOIN movies m ON s.movie_id = m.id
+		SELECT ` + showtimeDetailColumns + showtimeDetailJoins + `
 		WHERE s.cinema_id = $1
 		  AND s.show_date = $2::date
 		  AND s.show_time = $3::time
+		  AND s.deleted_at IS NULL
+		ORDER BY s.id
+		LIMIT 2
 	`
 
-	var showtime domain.Showtime
-	var cinema domain.Cinema
-	var movie domain.Movie
+	rows, err := r.db.Query(ctx, query, cinemaID, date, showTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get showtime: %w", err)
+	}
+	defer rows.Close()
 
-	err := r.db.QueryRow(ctx, query, cinemaID, date, time).Scan(
-		&showtime.ID,
-		&showtime.CinemaID,
-		&showtime.MovieID,
-		&showtime.ShowDate,
-		&showtime.ShowTime,
-		&showtime.Price,
-		&showtime.CreatedAt,
-		&cinema.ID,
-		&cinema.Name,
-		&cinema.Location,
-		&cinema.Description,
-		&cinema.CreatedAt,
-		&movie.ID,
-		&movie.Title,
-		&movie.Description,
-		&movie.Duration,
-		&movie.Genre,
-		&movie.PosterURL,
-		&movie.Rating,
-		&movie.CreatedAt,
-	)
+	var showtimes []*domain.Showtime
+	for rows.Next() {
+		showtime, err := scanShowtimeDetail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan showtime: %w", err)
+		}
+		showtimes = append(showtimes, showtime)
+	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating showtimes: %w", err)
+	}
+
+	switch len(showtimes) {
+	case 0:
+		return nil, fmt.Errorf("showtime not found for cinema_id=%d, date=%s, time=%s", cinemaID, date, showTime)
+	case 1:
+		return showtimes[0], nil
+	default:
+		return nil, fmt.Errorf("%w: cinema_id=%d, date=%s, time=%s", ErrAmbiguousShowtime, cinemaID, date, showTime)
+	}
+}
+
+func (r *showtimeRepository) GetByID(ctx context.Context, id int) (*domain.Showtime, error) {
+	query := `
+		SELECT ` + showtimeDetailColumns + showtimeDetailJoins + `
+		WHERE s.id = $1 AND s.deleted_at IS NULL
+	`
+
+	showtime, err := scanShowtimeDetail(r.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("showtime not found for cinema_id=%d, date=%s, time=%s", cinemaID, date, time)
+			return nil, fmt.Errorf("showtime not found")
 		}
 		return nil, fmt.Errorf("failed to get showtime: %w", err)
 	}
 
-	showtime.Cinema = &cinema
-	showtime.Movie = &movie
+	return showtime, nil
+}
 
-	return &showtime, nil
+// GetUpcomingByMovieID mengambil jadwal tayang film di semua cinema mulai dari waktu tertentu
+func (r *showtimeRepository) GetUpcomingByMovieID(ctx context.Context, movieID int, from time.Time) ([]*domain.Showtime, error) {
+	query := `
+		SELECT ` + showtimeDetailColumns + showtimeDetailJoins + `
+		WHERE s.movie_id = $1
+		  AND (s.show_date + s.show_time) AT TIME ZONE c.timezone >= $2
+		  AND s.deleted_at IS NULL
+		ORDER BY s.show_date ASC, s.show_time ASC, c.name ASC, sc.name ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, movieID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+	defer rows.Close()
+
+	showtimes := make([]*domain.Showtime, 0)
+	for rows.Next() {
+		showtime, err := scanShowtimeDetail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan showtime: %w", err)
+		}
+		// Movie is the parent of this list, no need to repeat it
+		showtime.Movie = nil
+		showtimes = append(showtimes, showtime)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating showtimes: %w", err)
+	}
+
+	return showtimes, nil
 }
 
-func (r *showtimeRepository) GetByID(ctx context.Context, id int) (*domain.Showtime, error) {
+// GetByCinemaAndDate mengambil semua jadwal tayang cinema pada tanggal tertentu beserta sisa kursi
+func (r *showtimeRepository) GetByCinemaAndDate(ctx context.Context, cinemaID int, date time.Time) ([]*domain.ShowtimeAvailability, error) {
 	query := `
-		SELECT s.id, s.cinema_id, s.movie_id, s.show_date, s.show_time, s.price, s.created_at,
-		       c.id, c.name, c.location, c.description, c.created_at,
-		       m.id, m.title, m.description, m.duration, m.genre, m.poster_url, m.rating, m.created_at
-		FROM showtimes s
-		JOIN cinemas c ON s.cinema_id = c.id
-		JOIN movies m ON s.movie_id = m.id
-		WHERE s.id = $1
+		SELECT ` + showtimeDetailColumns + `,` + showtimeAvailabilityColumns + showtimeDetailJoins + `
+		WHERE s.cinema_id = $1
+		  AND s.show_date = $2::date
+		  AND s.deleted_at IS NULL
+		ORDER BY s.show_time ASC, sc.name ASC, m.title ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, cinemaID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanShowtimeAvailability(rows)
+}
+
+// GetByMovieAndDateRange mengambil jadwal tayang film di semua cinema antara dua tanggal (inklusif) beserta sisa kursi
+func (r *showtimeRepository) GetByMovieAndDateRange(ctx context.Context, movieID int, from, to time.Time) ([]*domain.ShowtimeAvailability, error) {
+	query := `
+		SELECT ` + showtimeDetailColumns + `,` + showtimeAvailabilityColumns + showtimeDetailJoins + `
+		WHERE s.movie_id = $1
+		  AND s.show_date BETWEEN $2::date AND $3::date
+		  AND s.deleted_at IS NULL
+		ORDER BY s.show_date ASC, s.show_time ASC, c.name ASC, sc.name ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, movieID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+	defer rows.Close()
+
+	return scanShowtimeAvailability(rows)
+}
+
+// activeShowtimeBookings bernilai true jika showtime (alias showtimes) masih punya kursi yang ditahan booking pending/confirmed
+const activeShowtimeBookings = `EXISTS (
+			SELECT 1 FROM booking_seats bs
+			JOIN bookings b ON b.id = bs.booking_id
+			WHERE bs.showtime_id = showtimes.id
+			  AND bs.released_at IS NULL
+			  AND b.status IN ('pending', 'confirmed')
+		)`
+
+func (r *showtimeRepository) Create(ctx context.Context, showtime *domain.Showtime) error {
+	query := `
+		INSERT INTO showtimes (cinema_id, screen_id, movie_id, show_date, show_time, ends_at, cleaning_buffer_minutes, price, created_at)
+		VALUES ($1, $2, $3, $4::date, $5::time, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		showtime.CinemaID,
+		showtime.ScreenID,
+		showtime.MovieID,
+		showtime.ShowDate.Format("2006-01-02"),
+		showtime.ShowTime.Format("15:04:05"),
+		showtime.EndsAt,
+		showtime.CleaningBufferMinutes,
+		showtime.Price,
+		time.Now(),
+	).Scan(&showtime.ID, &showtime.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err, "idx_showtimes_screen_movie_slot") {
+			return ErrShowtimeExists
+		}
+		if isExclusionViolation(err, "showtimes_screen_no_overlap") {
+			return ErrShowtimeOverlap
+		}
+		return fmt.Errorf("failed to create showtime: %w", err)
+	}
+
+	return nil
+}
+
+func (r *showtimeRepository) Update(ctx context.Context, showtime *domain.Showtime) error {
+	query := `
+		UPDATE showtimes
+		SET cinema_id = $2, screen_id = $3, movie_id = $4, show_date = $5::date, show_time = $6::time,
+		    ends_at = $7, cleaning_buffer_minutes = $8, price = $9
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(
+		ctx,
+		query,
+		showtime.ID,
+		showtime.CinemaID,
+		showtime.ScreenID,
+		showtime.MovieID,
+		showtime.ShowDate.Format("2006-01-02"),
+		showtime.ShowTime.Format("15:04:05"),
+		showtime.EndsAt,
+		showtime.CleaningBufferMinutes,
+		showtime.Price,
+	).Scan(&showtime.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("showtime %w", ErrNotFound)
+		}
+		if isUniqueViolation(err, "idx_showtimes_screen_movie_slot") {
+			return ErrShowtimeExists
+		}
+		if isExclusionViolation(err, "showtimes_screen_no_overlap") {
+			return ErrShowtimeOverlap
+		}
+		return fmt.Errorf("failed to update showtime: %w", err)
+	}
+
+	return nil
+}
+
+// Delete menandai showtime sebagai terhapus (soft delete), ditolak selama masih ada booking aktif
+func (r *showtimeRepository) Delete(ctx context.Context, id int) error {
+	query := `
+		UPDATE showtimes
+		SET deleted_at = $2
+		WHERE id = $1
+		  AND deleted_at IS NULL
+		  AND NOT ` + activeShowtimeBookings + `
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to delete showtime: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return softDeleteMiss(ctx, r.db, "showtimes", "showtime", id, ErrShowtimeHasBookings)
+	}
+
+	return nil
+}
+
+// HasActiveBookings memeriksa apakah showtime masih punya kursi yang ditahan booking pending/confirmed
+func (r *showtimeRepository) HasActiveBookings(ctx context.Context, id int) (bool, error) {
+	query := `SELECT ` + activeShowtimeBookings + ` FROM showtimes WHERE id = $1`
+
+	var active bool
+	if err := r.db.QueryRow(ctx, query, id).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check showtime bookings: %w", err)
+	}
+
+	return active, nil
+}
+
+// GetOverlapping mengambil showtime di studio yang pemakaiannya (mulai sampai selesai + buffer)
+// beririsan dengan rentang startsAt-freeAt, selain showtime excludeID
+func (r *showtimeRepository) GetOverlapping(ctx context.Context, screenID int, startsAt, freeAt time.Time, excludeID int) ([]*domain.Showtime, error) {
+	query := `
+		SELECT ` + showtimeDetailColumns + showtimeDetailJoins + `
+		WHERE s.screen_id = $1
+		  AND s.id <> $4
+		  AND s.deleted_at IS NULL
+		  AND tsrange(s.show_date + s.show_time, s.ends_at + s.cleaning_buffer_minutes * INTERVAL '1 minute') && tsrange($2, $3)
+		ORDER BY s.show_date ASC, s.show_time ASC
 	`
 
+	rows, err := r.db.Query(ctx, query, screenID, startsAt, freeAt, excludeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overlapping showtimes: %w", err)
+	}
+	defer rows.Close()
+
+	showtimes := make([]*domain.Showtime, 0)
+	for rows.Next() {
+		showtime, err := scanShowtimeDetail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan showtime: %w", err)
+		}
+		showtimes = append(showtimes, showtime)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating showtimes: %w", err)
+	}
+
+	return showtimes, nil
+}
+
+// scanShowtimeDetail membaca kolom showtimeDetailColumns, diikuti kolom tambahan di extra
+func scanShowtimeDetail(row pgx.Row, extra ...interface{}) (*domain.Showtime, error) {
 	var showtime domain.Showtime
 	var cinema domain.Cinema
+	var screen domain.Screen
 	var movie domain.Movie
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
+	dest := []interface{}{
 		&showtime.ID,
 		&showtime.CinemaID,
+		&showtime.ScreenID,
 		&showtime.MovieID,
 		&showtime.ShowDate,
 		&showtime.ShowTime,
+		&showtime.EndsAt,
+		&showtime.CleaningBufferMinutes,
 		&showtime.Price,
 		&showtime.CreatedAt,
 		&cinema.ID,
 		&cinema.Name,
 		&cinema.Location,
 		&cinema.Description,
+		&cinema.Timezone,
 		&cinema.CreatedAt,
+		&screen.ID,
+		&screen.CinemaID,
+		&screen.Name,
+		&screen.CreatedAt,
 		&movie.ID,
 		&movie.Title,
 		&movie.Description,
@@ -112,17 +376,51 @@ func (r *showtimeRepository) GetByID(ctx context.Context, id int) (*domain.Showt
 		&movie.PosterURL,
 		&movie.Rating,
 		&movie.CreatedAt,
-	)
+	}
 
-	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("showtime not found")
-		}
-		return nil, fmt.Errorf("failed to get showtime: %w", err)
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
 	}
 
+	// ends_at disimpan sebagai jam lokal cinema (TIMESTAMP tanpa zona), sama seperti show_date/show_time
+	showtime.Timezone = cinema.Timezone
+	showtime.EndsAt = wallClockIn(showtime.EndsAt, showtime.TimeLocation())
 	showtime.Cinema = &cinema
+	showtime.Screen = &screen
 	showtime.Movie = &movie
 
 	return &showtime, nil
 }
+
+func scanShowtimeAvailability(rows pgx.Rows) ([]*domain.ShowtimeAvailability, error) {
+	showtimes := make([]*domain.ShowtimeAvailability, 0)
+	for rows.Next() {
+		var totalSeats, bookedSeats int
+		showtime, err := scanShowtimeDetail(rows, &totalSeats, &bookedSeats)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan showtime: %w", err)
+		}
+
+		available := totalSeats - bookedSeats
+		if available < 0 {
+			available = 0
+		}
+
+		showtimes = append(showtimes, &domain.ShowtimeAvailability{
+			Showtime:       showtime,
+			TotalSeats:     totalSeats,
+			AvailableSeats: available,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating showtimes: %w", err)
+	}
+
+	return showtimes, nil
+}
+
+// wallClockIn memindahkan jam dari kolom TIMESTAMP (tanpa zona, dibaca pgx sebagai UTC) ke zona loc
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var showtimeDetailMockColumns = []string{
+	"id", "cinema_id", "screen_id", "movie_id", "show_date", "show_time", "ends_at", "cleaning_buffer_minutes", "price", "created_at",
+	"c_id", "c_name", "c_location", "c_description", "c_timezone", "c_created_at",
+	"sc_id", "sc_cinema_id", "sc_name", "sc_created_at",
+	"m_id", "m_title", "m_description", "m_duration", "m_genre", "m_poster_url", "m_rating", "m_created_at",
+}
+
+var showtimeAvailabilityMockColumns = append(append([]string{}, showtimeDetailMockColumns...), "total_seats", "booked_seats")
+
+// showtimeDetailRow membuat nilai baris untuk showtimeDetailMockColumns
+func showtimeDetailRow(id, cinemaID, screenID, movieID int, startsAt time.Time, extra ...interface{}) []interface{} {
+	now := time.Now()
+	row := []interface{}{
+		id, cinemaID, screenID, movieID, startsAt, startsAt, startsAt.Add(181 * time.Minute), 15, domain.NewMoney(50000), now,
+		cinemaID, "CGV Grand Indonesia", "Jakarta Pusat", "Premium cinema", domain.TimezoneWIB, now,
+		screenID, cinemaID, "Studio 1", now,
+		movieID, "Avengers: Endgame", "Final battle", 181, "Action", "https://example.com/1.jpg", "PG-13", now,
+	}
+	return append(row, extra...)
+}
+
+func TestShowtimeRepository_GetByID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	rows := pgxmock.NewRows(showtimeDetailMockColumns).
+		AddRow(showtimeDetailRow(7, 1, 3, 1, time.Now())...)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s (.+) JOIN screens sc (.+) WHERE s.id = \\$1").
+		WithArgs(7).
+		WillReturnRows(rows)
+
+	showtime, err := repo.GetByID(context.Background(), 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, showtime.ScreenID)
+	assert.Equal(t, "Studio 1", showtime.Screen.Name)
+	assert.Equal(t, "Avengers: Endgame", showtime.Movie.Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetUpcomingByMovieID(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	now := time.Now()
+	showDate := now.AddDate(0, 0, 1)
+	rows := pgxmock.NewRows(showtimeDetailMockColumns).
+		AddRow(showtimeDetailRow(1, 1, 1, 3, showDate)...).
+		AddRow(showtimeDetailRow(2, 2, 4, 3, showDate)...)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s (.+) WHERE s.movie_id = \\$1").
+		WithArgs(3, now).
+		WillReturnRows(rows)
+
+	showtimes, err := repo.GetUpcomingByMovieID(context.Background(), 3, now)
+
+	assert.NoError(t, err)
+	assert.Len(t, showtimes, 2)
+	assert.Equal(t, 4, showtimes[1].ScreenID)
+	assert.NotNil(t, showtimes[1].Cinema)
+	assert.Nil(t, showtimes[1].Movie)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetUpcomingByMovieID_Empty(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM showtimes").
+		WithArgs(3, now).
+		WillReturnRows(pgxmock.NewRows(showtimeDetailMockColumns))
+
+	showtimes, err := repo.GetUpcomingByMovieID(context.Background(), 3, now)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, showtimes)
+	assert.Len(t, showtimes, 0)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetByCinemaAndDate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	date := time.Date(2026, 1, 11, 0, 0, 0, 0, time.Local)
+	rows := pgxmock.NewRows(showtimeAvailabilityMockColumns).
+		AddRow(showtimeDetailRow(1, 1, 1, 1, date, 50, 12)...).
+		AddRow(showtimeDetailRow(2, 1, 2, 2, date, 50, 50)...)
+
+	mock.ExpectQuery("SELECT (.+) FROM seats st WHERE st.screen_id = s.screen_id(.+) WHERE s.cinema_id = \\$1 AND s.show_date = \\$2::date").
+		WithArgs(1, date).
+		WillReturnRows(rows)
+
+	showtimes, err := repo.GetByCinemaAndDate(context.Background(), 1, date)
+
+	assert.NoError(t, err)
+	assert.Len(t, showtimes, 2)
+	assert.Equal(t, "Avengers: Endgame", showtimes[0].Movie.Title)
+	assert.Equal(t, 50, showtimes[0].TotalSeats)
+	assert.Equal(t, 38, showtimes[0].AvailableSeats)
+	assert.Equal(t, 2, showtimes[1].Screen.ID)
+	assert.Equal(t, 0, showtimes[1].AvailableSeats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetByMovieAndDateRange(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	from := time.Date(2026, 1, 11, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 6)
+	rows := pgxmock.NewRows(showtimeAvailabilityMockColumns).
+		AddRow(showtimeDetailRow(1, 2, 5, 3, from, 40, 3)...)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s (.+) WHERE s.movie_id = \\$1 AND s.show_date BETWEEN").
+		WithArgs(3, from, to).
+		WillReturnRows(rows)
+
+	showtimes, err := repo.GetByMovieAndDateRange(context.Background(), 3, from, to)
+
+	assert.NoError(t, err)
+	assert.Len(t, showtimes, 1)
+	assert.Equal(t, 2, showtimes[0].Cinema.ID)
+	assert.Equal(t, 37, showtimes[0].AvailableSeats)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetByCinemaDateTime(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	rows := pgxmock.NewRows(showtimeDetailMockColumns).
+		AddRow(showtimeDetailRow(1, 1, 1, 1, time.Now())...)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s (.+) WHERE s.cinema_id = \\$1 (.+) LIMIT 2").
+		WithArgs(1, "2024-01-15", "14:00").
+		WillReturnRows(rows)
+
+	showtime, err := repo.GetByCinemaDateTime(context.Background(), 1, "2024-01-15", "14:00")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, showtime.ID)
+	assert.Equal(t, "Avengers: Endgame", showtime.Movie.Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetByCinemaDateTime_Ambiguous(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	now := time.Now()
+	rows := pgxmock.NewRows(showtimeDetailMockColumns).
+		AddRow(showtimeDetailRow(1, 1, 1, 1, now)...).
+		AddRow(showtimeDetailRow(2, 1, 2, 2, now)...)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s").
+		WithArgs(1, "2024-01-15", "14:00").
+		WillReturnRows(rows)
+
+	showtime, err := repo.GetByCinemaDateTime(context.Background(), 1, "2024-01-15", "14:00")
+
+	assert.True(t, errors.Is(err, ErrAmbiguousShowtime))
+	assert.Nil(t, showtime)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetByCinemaDateTime_NotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s").
+		WithArgs(1, "2024-01-15", "14:00").
+		WillReturnRows(pgxmock.NewRows(showtimeDetailMockColumns))
+
+	showtime, err := repo.GetByCinemaDateTime(context.Background(), 1, "2024-01-15", "14:00")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "showtime not found")
+	assert.Nil(t, showtime)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_Delete_HasBookings(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	mock.ExpectExec("UPDATE showtimes\\s+SET deleted_at").
+		WithArgs(1, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectQuery("SELECT EXISTS (.+) FROM showtimes").
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err = repo.Delete(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrShowtimeHasBookings)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_GetOverlapping(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	existingStart := time.Date(2026, 1, 11, 19, 0, 0, 0, time.UTC)
+	wib := domain.TimezoneLocation(domain.TimezoneWIB)
+	startsAt := time.Date(2026, 1, 11, 21, 0, 0, 0, wib)
+	freeAt := startsAt.Add(150 * time.Minute)
+
+	rows := pgxmock.NewRows(showtimeDetailMockColumns).
+		AddRow(showtimeDetailRow(4, 1, 2, 5, existingStart)...)
+	mock.ExpectQuery("SELECT (.+) FROM showtimes s (.+) WHERE s.screen_id = \\$1\\s+AND s.id <> \\$4 (.+) tsrange").
+		WithArgs(2, startsAt, freeAt, 0).
+		WillReturnRows(rows)
+
+	showtimes, err := repo.GetOverlapping(context.Background(), 2, startsAt, freeAt, 0)
+
+	assert.NoError(t, err)
+	require.Len(t, showtimes, 1)
+	assert.Equal(t, 4, showtimes[0].ID)
+	assert.Equal(t, domain.TimezoneWIB, showtimes[0].Timezone)
+	assert.Equal(t, time.Date(2026, 1, 11, 22, 1, 0, 0, wib), showtimes[0].EndsAt)
+	assert.Equal(t, time.Date(2026, 1, 11, 22, 16, 0, 0, wib), showtimes[0].ScreenFreeAt())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestShowtimeRepository_Create_Overlap(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewShowtimeRepository(mock)
+
+	showtime := &domain.Showtime{
+		CinemaID: 1, ScreenID: 2, MovieID: 5,
+		ShowDate:              time.Date(2026, 1, 11, 0, 0, 0, 0, time.Local),
+		ShowTime:              time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+		EndsAt:                time.Date(2026, 1, 11, 22, 1, 0, 0, time.Local),
+		CleaningBufferMinutes: 15,
+		Price:                 domain.NewMoney(50000),
+	}
+
+	mock.ExpectQuery("INSERT INTO showtimes").
+		WithArgs(1, 2, 5, "2026-01-11", "19:00:00", showtime.EndsAt, 15, domain.NewMoney(50000), pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: "23P01", ConstraintName: "showtimes_screen_no_overlap"})
+
+	err = repo.Create(context.Background(), showtime)
+
+	assert.ErrorIs(t, err, ErrShowtimeOverlap)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound dikembalikan ketika data yang diubah atau dihapus tidak ada (atau sudah dihapus)
+var ErrNotFound = errors.New("not found")
+
+// softDeleteMiss menjelaskan kenapa soft delete tidak mengubah baris apa pun:
+// data tidak ada (ErrNotFound), atau masih ada tapi ditahan guard (inUse)
+func softDeleteMiss(ctx context.Context, db PgxPool, table, entity string, id int, inUse error) error {
+	var exists bool
+	query := "SELECT EXISTS (SELECT 1 FROM " + table + " WHERE id = $1 AND deleted_at IS NULL)"
+	if err := db.QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to get %s: %w", entity, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%s %w", entity, ErrNotFound)
+	}
+
+	return inUse
+}
+
+// isUniqueViolation memeriksa apakah err adalah pelanggaran unique constraint/index tertentu
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
+
+// isExclusionViolation memeriksa apakah err adalah pelanggaran exclusion constraint tertentu
+func isExclusionViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01" && pgErr.ConstraintName == constraint
+}
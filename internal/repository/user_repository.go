@@ -29,11 +29,15 @@ func NewUserRepository(db PgxPool) UserRepository {
 
 func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users (username, email, password_hash, is_verified, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (username, email, password_hash, is_verified, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at, updated_at
 	`
 
+	if user.Role == "" {
+		user.Role = domain.RoleCustomer
+	}
+
 	now := time.Now()
 	err := r.db.QueryRow(
 		ctx,
@@ -42,6 +46,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 		user.Email,
 		user.PasswordHash,
 		user.IsVerified,
+		user.Role,
 		now,
 		now,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
@@ -55,7 +60,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 
 func (r *userRepository) GetByID(ctx context.Context, id int) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, is_verified, created_at, updated_at
+		SELECT id, username, email, password_hash, is_verified, role, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`
@@ -67,6 +72,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*domain.User, err
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsVerified,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -83,7 +89,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*domain.User, err
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, is_verified, created_at, updated_at
+		SELECT id, username, email, password_hash, is_verified, role, created_at, updated_at
 		FROM users
 		WHERE username = $1
 	`
@@ -95,6 +101,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*d
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsVerified,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -111,7 +118,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*d
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, username, email, password_hash, is_verified, created_at, updated_at
+		SELECT id, username, email, password_hash, is_verified, role, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -123,6 +130,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsVerified,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -140,8 +148,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
-		SET username = $1, email = $2, is_verified = $3, updated_at = $4
-		WHERE id = $5
+		SET username = $1, email = $2, is_verified = $3, role = $4, updated_at = $5
+		WHERE id = $6
 	`
 
 	_, err := r.db.Exec(
@@ -150,6 +158,7 @@ func (r *userRepository) Update(ctx context.Context, user *domain.User) error {
 		user.Username,
 		user.Email,
 		user.IsVerified,
+		user.Role,
 		time.Now(),
 		user.ID,
 	)
@@ -32,13 +32,15 @@ func TestUserRepository_Create(t *testing.T) {
 		AddRow(1, now, now)
 
 	mock.ExpectQuery("INSERT INTO users").
-		WithArgs(user.Username, user.Email, user.PasswordHash, user.IsVerified, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WithArgs(user.Username, user.Email, user.PasswordHash, user.IsVerified, domain.RoleCustomer, pgxmock.AnyArg(), pgxmock.AnyArg()).
 		WillReturnRows(rows)
 
 	err = repo.Create(context.Background(), user)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, user.ID)
+	// New users default to customer
+	assert.Equal(t, domain.RoleCustomer, user.Role)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -50,8 +52,8 @@ func TestUserRepository_GetByID(t *testing.T) {
 	repo := NewUserRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "created_at", "updated_at"}).
-		AddRow(1, "testuser", "test@example.com", "hashedpassword", true, now, now)
+	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "role", "created_at", "updated_at"}).
+		AddRow(1, "testuser", "test@example.com", "hashedpassword", true, "admin", now, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM users WHERE id").
 		WithArgs(1).
@@ -63,6 +65,7 @@ func TestUserRepository_GetByID(t *testing.T) {
 	assert.NotNil(t, user)
 	assert.Equal(t, 1, user.ID)
 	assert.Equal(t, "testuser", user.Username)
+	assert.Equal(t, domain.RoleAdmin, user.Role)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -74,8 +77,8 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 	repo := NewUserRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "created_at", "updated_at"}).
-		AddRow(1, "testuser", "test@example.com", "hashedpassword", false, now, now)
+	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "role", "created_at", "updated_at"}).
+		AddRow(1, "testuser", "test@example.com", "hashedpassword", false, "customer", now, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM users WHERE username").
 		WithArgs("testuser").
@@ -116,8 +119,8 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	repo := NewUserRepository(mock)
 
 	now := time.Now()
-	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "created_at", "updated_at"}).
-		AddRow(1, "testuser", "test@example.com", "hashedpassword", false, now, now)
+	rows := pgxmock.NewRows([]string{"id", "username", "email", "password_hash", "is_verified", "role", "created_at", "updated_at"}).
+		AddRow(1, "testuser", "test@example.com", "hashedpassword", false, "customer", now, now)
 
 	mock.ExpectQuery("SELECT (.+) FROM users WHERE email").
 		WithArgs("test@example.com").
@@ -162,10 +165,11 @@ func TestUserRepository_Update(t *testing.T) {
 		Username:   "updateduser",
 		Email:      "updated@example.com",
 		IsVerified: true,
+		Role:       domain.RoleStaff,
 	}
 
 	mock.ExpectExec("UPDATE users").
-		WithArgs(user.Username, user.Email, user.IsVerified, pgxmock.AnyArg(), user.ID).
+		WithArgs(user.Username, user.Email, user.IsVerified, user.Role, pgxmock.AnyArg(), user.ID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	err = repo.Update(context.Background(), user)
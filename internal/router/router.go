@@ -3,6 +3,7 @@ package router
 import (
 	"net/http"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/handler"
 	"project-app-bioskop-golang-homework-anas/internal/middleware"
 
@@ -12,38 +13,50 @@ import (
 )
 
 type Router struct {
-	authHandler          *handler.AuthHandler
-	cinemaHandler        *handler.CinemaHandler
-	seatHandler          *handler.SeatHandler
-	paymentMethodHandler *handler.PaymentMethodHandler
-	bookingHandler       *handler.BookingHandler
-	paymentHandler       *handler.PaymentHandler
-	otpHandler           *handler.OTPHandler
-	authMiddleware       *middleware.AuthMiddleware
-	logger               *zap.Logger
+	authHandler           *handler.AuthHandler
+	cinemaHandler         *handler.CinemaHandler
+	movieHandler          *handler.MovieHandler
+	showtimeHandler       *handler.ShowtimeHandler
+	seatHandler           *handler.SeatHandler
+	paymentMethodHandler  *handler.PaymentMethodHandler
+	bookingHandler        *handler.BookingHandler
+	paymentHandler        *handler.PaymentHandler
+	otpHandler            *handler.OTPHandler
+	ticketHandler         *handler.TicketHandler
+	authMiddleware        *middleware.AuthMiddleware
+	idempotencyMiddleware *middleware.IdempotencyMiddleware
+	logger                *zap.Logger
 }
 
 func NewRouter(
 	authHandler *handler.AuthHandler,
 	cinemaHandler *handler.CinemaHandler,
+	movieHandler *handler.MovieHandler,
+	showtimeHandler *handler.ShowtimeHandler,
 	seatHandler *handler.SeatHandler,
 	paymentMethodHandler *handler.PaymentMethodHandler,
 	bookingHandler *handler.BookingHandler,
 	paymentHandler *handler.PaymentHandler,
 	otpHandler *handler.OTPHandler,
+	ticketHandler *handler.TicketHandler,
 	authMiddleware *middleware.AuthMiddleware,
+	idempotencyMiddleware *middleware.IdempotencyMiddleware,
 	logger *zap.Logger,
 ) *Router {
 	return &Router{
-		authHandler:          authHandler,
-		cinemaHandler:        cinemaHandler,
-		seatHandler:          seatHandler,
-		paymentMethodHandler: paymentMethodHandler,
-		bookingHandler:       bookingHandler,
-		paymentHandler:       paymentHandler,
-		otpHandler:           otpHandler,
-		authMiddleware:       authMiddleware,
-		logger:               logger,
+		authHandler:           authHandler,
+		cinemaHandler:         cinemaHandler,
+		movieHandler:          movieHandler,
+		showtimeHandler:       showtimeHandler,
+		seatHandler:           seatHandler,
+		paymentMethodHandler:  paymentMethodHandler,
+		bookingHandler:        bookingHandler,
+		paymentHandler:        paymentHandler,
+		otpHandler:            otpHandler,
+		ticketHandler:         ticketHandler,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+		logger:                logger,
 	}
 }
 
@@ -74,11 +87,14 @@ func (rt *Router) SetupRoutes() http.Handler {
 		// Cinema routes (public)
 		rt.setupCinemaRoutes(r)
 
+		// Movie routes (public)
+		rt.setupMovieRoutes(r)
+
 		// Payment method routes (public)
 		rt.setupPaymentMethodRoutes(r)
 
-		// Payment routes (public)
-		rt.setupPaymentRoutes(r)
+		// Payment provider webhooks (public, verified by signature)
+		rt.setupPaymentWebhookRoutes(r)
 
 		// Protected routes (auth required)
 		r.Group(func(r chi.Router) {
@@ -90,8 +106,25 @@ func (rt *Router) SetupRoutes() http.Handler {
 			// Booking routes
 			rt.setupBookingRoutes(r)
 
+			// Payment routes
+			rt.setupPaymentRoutes(r)
+
 			// User booking history
 			rt.setupUserRoutes(r)
+
+			// Staff routes (staff or admin role required)
+			r.Group(func(r chi.Router) {
+				r.Use(rt.authMiddleware.RequireRole(domain.RoleStaff, domain.RoleAdmin))
+
+				rt.setupStaffRoutes(r)
+			})
+
+			// Admin routes (admin role required)
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(rt.authMiddleware.RequireRole(domain.RoleAdmin))
+
+				rt.setupAdminRoutes(r)
+			})
 		})
 	})
 
@@ -115,6 +148,14 @@ func (rt *Router) setupCinemaRoutes(r chi.Router) {
 	r.Get("/cinemas", rt.cinemaHandler.GetAllCinemas)
 	r.Get("/cinemas/{cinemaId}", rt.cinemaHandler.GetCinemaByID)
 	r.Get("/cinemas/{cinemaId}/seats", rt.seatHandler.GetSeatAvailability)
+	r.Get("/cinemas/{cinemaId}/showtimes", rt.showtimeHandler.GetCinemaShowtimes)
+}
+
+// setupMovieRoutes mengatur routing untuk movie
+func (rt *Router) setupMovieRoutes(r chi.Router) {
+	r.Get("/movies", rt.movieHandler.GetAllMovies)
+	r.Get("/movies/{id}", rt.movieHandler.GetMovieByID)
+	r.Get("/movies/{id}/showtimes", rt.showtimeHandler.GetMovieShowtimes)
 }
 
 // setupPaymentMethodRoutes mengatur routing untuk payment methods
@@ -122,14 +163,49 @@ func (rt *Router) setupPaymentMethodRoutes(r chi.Router) {
 	r.Get("/payment-methods", rt.paymentMethodHandler.GetAllPaymentMethods)
 }
 
-// setupPaymentRoutes mengatur routing untuk payment
+// setupPaymentRoutes mengatur routing untuk payment (protected)
 func (rt *Router) setupPaymentRoutes(r chi.Router) {
-	r.Post("/pay", rt.paymentHandler.ProcessPayment)
+	r.With(rt.idempotencyMiddleware.Idempotent).Post("/pay", rt.paymentHandler.ProcessPayment)
+}
+
+// setupPaymentWebhookRoutes mengatur routing untuk webhook payment provider
+func (rt *Router) setupPaymentWebhookRoutes(r chi.Router) {
+	r.Post("/payments/webhook/{provider}", rt.paymentHandler.PaymentWebhook)
 }
 
 // setupBookingRoutes mengatur routing untuk booking (protected)
 func (rt *Router) setupBookingRoutes(r chi.Router) {
-	r.Post("/booking", rt.bookingHandler.CreateBooking)
+	r.With(rt.idempotencyMiddleware.Idempotent).Post("/booking", rt.bookingHandler.CreateBooking)
+	r.Get("/bookings/{code}", rt.bookingHandler.GetBookingByCode)
+	r.Get("/bookings/{code}/ticket", rt.ticketHandler.GetTicket)
+	r.Post("/bookings/{id}/cancel", rt.bookingHandler.CancelBooking)
+}
+
+// setupStaffRoutes mengatur routing untuk petugas bioskop (staff only)
+func (rt *Router) setupStaffRoutes(r chi.Router) {
+	r.Post("/checkin", rt.ticketHandler.CheckIn)
+}
+
+// setupAdminRoutes mengatur routing untuk pengelolaan data (admin only)
+func (rt *Router) setupAdminRoutes(r chi.Router) {
+	r.Post("/cinemas", rt.cinemaHandler.CreateCinema)
+	r.Put("/cinemas/{cinemaId}", rt.cinemaHandler.UpdateCinema)
+	r.Delete("/cinemas/{cinemaId}", rt.cinemaHandler.DeleteCinema)
+	r.Post("/cinemas/{cinemaId}/screens", rt.cinemaHandler.CreateScreen)
+	r.Put("/cinemas/{cinemaId}/screens/{screenId}/layout", rt.seatHandler.ImportSeatLayout)
+	r.Post("/cinemas/{cinemaId}/screens/{screenId}/seats", rt.seatHandler.CreateSeat)
+
+	r.Put("/seats/{seatId}", rt.seatHandler.UpdateSeat)
+	r.Delete("/seats/{seatId}", rt.seatHandler.DeleteSeat)
+
+	r.Post("/movies", rt.movieHandler.CreateMovie)
+	r.Put("/movies/{id}", rt.movieHandler.UpdateMovie)
+	r.Delete("/movies/{id}", rt.movieHandler.DeleteMovie)
+
+	r.Post("/showtimes", rt.showtimeHandler.CreateShowtime)
+	r.Post("/showtimes/generate", rt.showtimeHandler.GenerateSchedule)
+	r.Put("/showtimes/{showtimeId}", rt.showtimeHandler.UpdateShowtime)
+	r.Delete("/showtimes/{showtimeId}", rt.showtimeHandler.DeleteShowtime)
 }
 
 // setupUserRoutes mengatur routing untuk user-related endpoints (protected)
@@ -20,6 +20,7 @@ type AuthService interface {
 	Login(ctx context.Context, req *domain.LoginRequest) (*domain.AuthResponse, error)
 	Logout(ctx context.Context, token string) error
 	ValidateToken(ctx context.Context, token string) (*domain.User, error)
+	BootstrapAdmin(ctx context.Context) error
 }
 
 type authService struct {
@@ -72,6 +73,7 @@ func (s *authService) Register(ctx context.Context, req *domain.RegisterRequest)
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		IsVerified:   false, // Default false - need email verification
+		Role:         domain.RoleCustomer,
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
@@ -170,6 +172,66 @@ func (s *authService) ValidateToken(ctx context.Context, token string) (*domain.
 	return user, nil
 }
 
+// BootstrapAdmin membuat admin pertama dari config (ADMIN_USERNAME, ADMIN_EMAIL, ADMIN_PASSWORD).
+// User yang sudah ada hanya dijadikan admin jika password-nya cocok dengan config.
+func (s *authService) BootstrapAdmin(ctx context.Context) error {
+	cfg := s.config.Admin
+	if cfg.Username == "" {
+		return nil
+	}
+
+	if cfg.Password == "" {
+		return errors.New("admin password is required to bootstrap admin")
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, cfg.Username)
+	if err == nil {
+		if user.Role == domain.RoleAdmin {
+			return nil
+		}
+
+		// Someone else could have registered the username first
+		if !utils.CheckPassword(cfg.Password, user.PasswordHash) {
+			return fmt.Errorf("user %s already exists with a different password", cfg.Username)
+		}
+
+		user.Role = domain.RoleAdmin
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return fmt.Errorf("failed to promote admin: %w", err)
+		}
+
+		s.logger.Info("Existing user promoted to admin", zap.Int("user_id", user.ID), zap.String("username", user.Username))
+		return nil
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		return fmt.Errorf("failed to get admin user: %w", err)
+	}
+
+	if cfg.Email == "" {
+		return errors.New("admin email is required to bootstrap admin")
+	}
+
+	hashedPassword, err := utils.HashPassword(cfg.Password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	admin := &domain.User{
+		Username:     cfg.Username,
+		Email:        cfg.Email,
+		PasswordHash: hashedPassword,
+		IsVerified:   true,
+		Role:         domain.RoleAdmin,
+	}
+
+	if err := s.userRepo.Create(ctx, admin); err != nil {
+		return fmt.Errorf("failed to create admin: %w", err)
+	}
+
+	s.logger.Info("Admin user created", zap.Int("user_id", admin.ID), zap.String("username", admin.Username))
+	return nil
+}
+
 func (s *authService) generateToken(ctx context.Context, userID int) (string, error) {
 	// Generate random token
 	tokenString, err := utils.GenerateToken(32)
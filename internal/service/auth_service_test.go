@@ -7,6 +7,7 @@ import (
 
 	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -265,3 +266,83 @@ func TestAuthService_Logout_Error(t *testing.T) {
 	assert.Error(t, err)
 	mockTokenRepo.AssertExpectations(t)
 }
+
+func adminConfig() *config.Config {
+	return &config.Config{
+		Admin: config.AdminConfig{
+			Username: "admin",
+			Email:    "admin@example.com",
+			Password: "admin123",
+		},
+	}
+}
+
+func TestAuthService_BootstrapAdmin_CreatesAdmin(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	service := NewAuthService(mockUserRepo, new(MockAuthTokenRepository), new(MockOTPService), adminConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	mockUserRepo.On("GetByUsername", ctx, "admin").Return(nil, errors.New("user not found"))
+	mockUserRepo.On("Create", ctx, mock.MatchedBy(func(u *domain.User) bool {
+		return u.Role == domain.RoleAdmin && u.IsVerified && u.Email == "admin@example.com" &&
+			utils.CheckPassword("admin123", u.PasswordHash)
+	})).Return(nil)
+
+	err := service.BootstrapAdmin(ctx)
+
+	assert.NoError(t, err)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestAuthService_BootstrapAdmin_ExistingUser(t *testing.T) {
+	hash, err := utils.HashPassword("admin123")
+	assert.NoError(t, err)
+
+	t.Run("already admin", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewAuthService(mockUserRepo, new(MockAuthTokenRepository), new(MockOTPService), adminConfig(), zap.NewNop())
+
+		ctx := context.Background()
+		mockUserRepo.On("GetByUsername", ctx, "admin").Return(&domain.User{ID: 1, Role: domain.RoleAdmin}, nil)
+
+		assert.NoError(t, service.BootstrapAdmin(ctx))
+		mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("promoted when password matches", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		service := NewAuthService(mockUserRepo, new(MockAuthTokenRepository), new(MockOTPService), adminConfig(), zap.NewNop())
+
+		ctx := context.Background()
+		user := &domain.User{ID: 1, Role: domain.RoleCustomer, PasswordHash: hash}
+		mockUserRepo.On("GetByUsername", ctx, "admin").Return(user, nil)
+		mockUserRepo.On("Update", ctx, user).Return(nil)
+
+		assert.NoError(t, service.BootstrapAdmin(ctx))
+		assert.Equal(t, domain.RoleAdmin, user.Role)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("not promoted with different password", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		cfg := adminConfig()
+		cfg.Admin.Password = "other-password"
+		service := NewAuthService(mockUserRepo, new(MockAuthTokenRepository), new(MockOTPService), cfg, zap.NewNop())
+
+		ctx := context.Background()
+		user := &domain.User{ID: 1, Role: domain.RoleCustomer, PasswordHash: hash}
+		mockUserRepo.On("GetByUsername", ctx, "admin").Return(user, nil)
+
+		assert.Error(t, service.BootstrapAdmin(ctx))
+		assert.Equal(t, domain.RoleCustomer, user.Role)
+		mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthService_BootstrapAdmin_NotConfigured(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	service := NewAuthService(mockUserRepo, new(MockAuthTokenRepository), new(MockOTPService), &config.Config{}, zap.NewNop())
+
+	assert.NoError(t, service.BootstrapAdmin(context.Background()))
+	mockUserRepo.AssertNotCalled(t, "GetByUsername", mock.Anything, mock.Anything)
+}
@@ -12,26 +12,34 @@ import (
 type BackgroundService interface {
 	StartTokenCleanup(interval time.Duration)
 	StartOTPCleanup(interval time.Duration)
+	StartBookingExpiry(interval time.Duration)
+	StartIdempotencyKeyCleanup(interval time.Duration)
 	Stop()
 }
 
 type backgroundService struct {
-	tokenRepo repository.AuthTokenRepository
-	otpRepo   repository.OTPRepository
-	logger    *zap.Logger
-	stopChan  chan bool
+	tokenRepo       repository.AuthTokenRepository
+	otpRepo         repository.OTPRepository
+	bookingRepo     repository.BookingRepository
+	idempotencyRepo repository.IdempotencyRepository
+	logger          *zap.Logger
+	stopChan        chan bool
 }
 
 func NewBackgroundService(
 	tokenRepo repository.AuthTokenRepository,
 	otpRepo repository.OTPRepository,
+	bookingRepo repository.BookingRepository,
+	idempotencyRepo repository.IdempotencyRepository,
 	logger *zap.Logger,
 ) BackgroundService {
 	return &backgroundService{
-		tokenRepo: tokenRepo,
-		otpRepo:   otpRepo,
-		logger:    logger,
-		stopChan:  make(chan bool),
+		tokenRepo:       tokenRepo,
+		otpRepo:         otpRepo,
+		bookingRepo:     bookingRepo,
+		idempotencyRepo: idempotencyRepo,
+		logger:          logger,
+		stopChan:        make(chan bool),
 	}
 }
 
@@ -75,6 +83,46 @@ func (s *backgroundService) StartOTPCleanup(interval time.Duration) {
 	}()
 }
 
+// StartBookingExpiry menjalankan background job untuk expire booking pending yang tidak dibayar
+func (s *backgroundService) StartBookingExpiry(interval time.Duration) {
+	s.logger.Info("Starting booking expiry background job", zap.Duration("interval", interval))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.expireOverdueBookings()
+			case <-s.stopChan:
+				s.logger.Info("Booking expiry background job stopped")
+				return
+			}
+		}
+	}()
+}
+
+// StartIdempotencyKeyCleanup menjalankan background job untuk cleanup idempotency key yang expired
+func (s *backgroundService) StartIdempotencyKeyCleanup(interval time.Duration) {
+	s.logger.Info("Starting idempotency key cleanup background job", zap.Duration("interval", interval))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.cleanupExpiredIdempotencyKeys()
+			case <-s.stopChan:
+				s.logger.Info("Idempotency key cleanup background job stopped")
+				return
+			}
+		}
+	}()
+}
+
 func (s *backgroundService) cleanupExpiredTokens() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -109,6 +157,34 @@ func (s *backgroundService) cleanupExpiredOTPs() {
 	}()
 }
 
+func (s *backgroundService) expireOverdueBookings() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := s.bookingRepo.ExpireOverdue(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to expire overdue bookings", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		s.logger.Info("Expired overdue bookings", zap.Int("count", count))
+	}
+}
+
+func (s *backgroundService) cleanupExpiredIdempotencyKeys() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.idempotencyRepo.DeleteExpired(ctx); err != nil {
+		s.logger.Error("Failed to cleanup expired idempotency keys", zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Idempotency key cleanup completed successfully")
+}
+
+// Stop menghentikan semua background job
 func (s *backgroundService) Stop() {
-	s.stopChan <- true
+	close(s.stopChan)
 }
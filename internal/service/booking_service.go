@@ -2,19 +2,46 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/gateway"
 	"project-app-bioskop-golang-homework-anas/internal/repository"
 	"project-app-bioskop-golang-homework-anas/internal/utils"
 
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrSeatAlreadyTaken dikembalikan ketika salah satu kursi sudah dipesan orang lain
+	ErrSeatAlreadyTaken = repository.ErrSeatAlreadyTaken
+	// ErrBookingNotFound dikembalikan ketika booking tidak ditemukan
+	ErrBookingNotFound = errors.New("booking not found")
+	// ErrBookingNotOwned dikembalikan ketika booking milik user lain
+	ErrBookingNotOwned = errors.New("booking does not belong to this user")
+	// ErrBookingStatusChanged dikembalikan ketika booking sudah diubah request lain
+	ErrBookingStatusChanged = repository.ErrBookingStatusChanged
+	// ErrInvalidSeatSelection dikembalikan ketika kursi yang dipilih tidak bisa dipesan untuk showtime ini
+	ErrInvalidSeatSelection = errors.New("invalid seat selection")
+	// ErrInvalidPaymentMethod dikembalikan ketika payment method tidak dikenal
+	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+	// ErrBookingCancelled dikembalikan ketika booking sudah dibatalkan
+	ErrBookingCancelled = errors.New("booking is already cancelled")
+	// ErrBookingExpired dikembalikan ketika batas waktu pembayaran booking sudah lewat
+	ErrBookingExpired = errors.New("booking has expired")
+	// ErrShowtimeStarted dikembalikan ketika booking dibatalkan setelah film mulai
+	ErrShowtimeStarted = errors.New("showtime has already started, booking can no longer be cancelled")
+)
+
 type BookingService interface {
 	CreateBooking(ctx context.Context, userID int, req *domain.BookingRequest) (*domain.Booking, error)
 	GetUserBookings(ctx context.Context, userID int) ([]*domain.Booking, error)
 	GetBookingByID(ctx context.Context, bookingID int) (*domain.Booking, error)
+	GetBookingByCode(ctx context.Context, userID int, code string) (*domain.Booking, error)
+	CancelBooking(ctx context.Context, userID, bookingID int) (*domain.Booking, error)
 }
 
 type bookingService struct {
@@ -22,7 +49,12 @@ type bookingService struct {
 	showtimeRepo      repository.ShowtimeRepository
 	seatRepo          repository.SeatRepository
 	paymentMethodRepo repository.PaymentMethodRepository
+	pricingService    PricingService
+	promotionService  PromotionService
+	gateways          *gateway.Registry
+	config            *config.Config
 	logger            *zap.Logger
+	now               func() time.Time // jam sekarang, bisa diganti di test
 }
 
 func NewBookingService(
@@ -30,6 +62,10 @@ func NewBookingService(
 	showtimeRepo repository.ShowtimeRepository,
 	seatRepo repository.SeatRepository,
 	paymentMethodRepo repository.PaymentMethodRepository,
+	pricingService PricingService,
+	promotionService PromotionService,
+	gateways *gateway.Registry,
+	config *config.Config,
 	logger *zap.Logger,
 ) BookingService {
 	return &bookingService{
@@ -37,45 +73,81 @@ func NewBookingService(
 		showtimeRepo:      showtimeRepo,
 		seatRepo:          seatRepo,
 		paymentMethodRepo: paymentMethodRepo,
+		pricingService:    pricingService,
+		promotionService:  promotionService,
+		gateways:          gateways,
+		config:            config,
 		logger:            logger,
+		now:               time.Now,
 	}
 }
 
 func (s *bookingService) CreateBooking(ctx context.Context, userID int, req *domain.BookingRequest) (*domain.Booking, error) {
-	// Validate showtime exists
-	showtime, err := s.showtimeRepo.GetByCinemaDateTime(ctx, req.CinemaID, req.Date, req.Time)
+	// Validate showtime exists (by showtime_id, or legacy cinema/date/time)
+	showtime, err := resolveShowtime(ctx, s.showtimeRepo, req.ShowtimeID, req.CinemaID, req.Date, req.Time)
 	if err != nil {
-		s.logger.Error("Showtime not found", zap.Error(err))
-		return nil, fmt.Errorf("showtime not found for the specified date and time")
+		s.logger.Error("Failed to resolve showtime",
+			zap.Int("showtime_id", req.ShowtimeID),
+			zap.Int("cinema_id", req.CinemaID),
+			zap.Error(err),
+		)
+		return nil, err
 	}
 
-	// Validate seat exists and belongs to cinema
-	seat, err := s.seatRepo.GetByID(ctx, req.SeatID)
-	if err != nil {
-		s.logger.Error("Seat not found", zap.Error(err))
-		return nil, fmt.Errorf("seat not found")
-	}
+	now := s.now()
 
-	if seat.CinemaID != req.CinemaID {
-		return nil, fmt.Errorf("seat does not belong to this cinema")
+	// Ticket sales close a configurable time after the showtime starts
+	if err := checkSalesOpen(showtime, now, s.config.Booking.SalesCutoff); err != nil {
+		return nil, err
 	}
 
-	// Check if seat is already booked for this showtime
-	isBooked, err := s.bookingRepo.CheckSeatBooked(ctx, showtime.ID, req.SeatID)
-	if err != nil {
-		s.logger.Error("Failed to check seat availability", zap.Error(err))
-		return nil, fmt.Errorf("failed to check seat availability")
+	// Validate each seat exists and belongs to cinema
+	seats := make([]*domain.Seat, 0, len(req.SeatIDs))
+	seen := make(map[int]bool, len(req.SeatIDs))
+	var paired []*domain.Seat
+	for _, seatID := range req.SeatIDs {
+		if seen[seatID] {
+			return nil, fmt.Errorf("%w: seat %d is selected more than once", ErrInvalidSeatSelection, seatID)
+		}
+		seen[seatID] = true
+
+		seat, err := s.seatRepo.GetByID(ctx, seatID)
+		if err != nil {
+			s.logger.Error("Seat not found", zap.Int("seat_id", seatID), zap.Error(err))
+			return nil, ErrSeatNotFound
+		}
+
+		if seat.CinemaID != showtime.CinemaID {
+			return nil, fmt.Errorf("%w: seat does not belong to this cinema", ErrInvalidSeatSelection)
+		}
+
+		if seat.ScreenID != showtime.ScreenID {
+			return nil, fmt.Errorf("%w: seat is not in the screen of this showtime", ErrInvalidSeatSelection)
+		}
+
+		if seat.IsBlocked {
+			return nil, fmt.Errorf("%w: seat %s is not available for booking", ErrInvalidSeatSelection, seat.Label())
+		}
+
+		if seat.PairSeatID != nil {
+			paired = append(paired, seat)
+		}
+
+		seats = append(seats, seat)
 	}
 
-	if isBooked {
-		return nil, fmt.Errorf("seat is already booked for this showtime")
+	// Couple seats are sold as a pair
+	for _, seat := range paired {
+		if !seen[*seat.PairSeatID] {
+			return nil, fmt.Errorf("%w: seat %s is a couple seat and must be booked together with its pair", ErrInvalidSeatSelection, seat.Label())
+		}
 	}
 
 	// Validate payment method
 	_, err = s.paymentMethodRepo.GetByCode(ctx, req.PaymentMethod)
 	if err != nil {
 		s.logger.Error("Invalid payment method", zap.Error(err))
-		return nil, fmt.Errorf("invalid payment method")
+		return nil, ErrInvalidPaymentMethod
 	}
 
 	// Generate booking code
@@ -85,17 +157,62 @@ func (s *bookingService) CreateBooking(ctx context.Context, userID int, req *dom
 		return nil, fmt.Errorf("failed to generate booking code")
 	}
 
-	// Create booking
+	// Price each seat by its type, then combine into the order total
+	prices, err := s.pricingService.PriceSeats(ctx, showtime, seats)
+	if err != nil {
+		s.logger.Error("Failed to price seats", zap.Int("showtime_id", showtime.ID), zap.Error(err))
+		return nil, ErrSeatPricingFailed
+	}
+
+	bookingSeats := make([]*domain.BookingSeat, 0, len(seats))
+	var totalPrice domain.Money
+	for _, seat := range seats {
+		price := prices[seat.ID]
+		bookingSeats = append(bookingSeats, &domain.BookingSeat{
+			SeatID:         seat.ID,
+			Price:          price.Price,
+			PriceBreakdown: price,
+		})
+		totalPrice += price.Price
+	}
+
+	// Pending booking must be paid within the payment window
+	expiresAt := now.Add(s.config.Booking.PaymentWindow)
+
+	// Reserve all seats in a single transaction, availability is checked under lock
 	booking := &domain.Booking{
 		UserID:      userID,
 		ShowtimeID:  showtime.ID,
-		SeatID:      req.SeatID,
 		BookingCode: bookingCode,
 		Status:      "pending",
-		TotalPrice:  showtime.Price,
+		TotalPrice:  totalPrice,
+		ExpiresAt:   &expiresAt,
+		Seats:       bookingSeats,
 	}
 
-	if err := s.bookingRepo.Create(ctx, booking); err != nil {
+	// Promo discount is taken off the total, usage is recorded together with the reservation
+	if req.PromoCode != "" {
+		redemption, err := s.promotionService.Quote(ctx, userID, req.PromoCode, showtime, req.PaymentMethod, totalPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		code := redemption.Promotion.Code
+		booking.TotalPrice = totalPrice - redemption.DiscountAmount
+		booking.DiscountAmount = redemption.DiscountAmount
+		booking.PromoCode = &code
+		booking.Redemption = redemption
+	}
+
+	if err := s.bookingRepo.Reserve(ctx, booking, now); err != nil {
+		if errors.Is(err, ErrSeatAlreadyTaken) {
+			s.logger.Warn("Seat already taken", zap.Int("showtime_id", showtime.ID), zap.Error(err))
+			return nil, err
+		}
+		if errors.Is(err, ErrPromotionExhausted) || errors.Is(err, ErrPromotionUserLimit) {
+			s.logger.Warn("Promo code usage limit reached", zap.String("promo_code", req.PromoCode), zap.Error(err))
+			return nil, err
+		}
 		s.logger.Error("Failed to create booking", zap.Error(err))
 		return nil, fmt.Errorf("failed to create booking: %w", err)
 	}
@@ -104,6 +221,7 @@ func (s *bookingService) CreateBooking(ctx context.Context, userID int, req *dom
 		zap.Int("booking_id", booking.ID),
 		zap.Int("user_id", userID),
 		zap.String("booking_code", bookingCode),
+		zap.Int("seat_count", len(seats)),
 	)
 
 	// GOROUTINE: Async logging to file
@@ -133,8 +251,110 @@ func (s *bookingService) GetBookingByID(ctx context.Context, bookingID int) (*do
 	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
 	if err != nil {
 		s.logger.Error("Failed to get booking", zap.Int("booking_id", bookingID), zap.Error(err))
-		return nil, fmt.Errorf("booking not found")
+		return nil, ErrBookingNotFound
+	}
+
+	return booking, nil
+}
+
+// GetBookingByCode mengambil detail booking milik user berdasarkan booking code
+func (s *bookingService) GetBookingByCode(ctx context.Context, userID int, code string) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByCode(ctx, code)
+	if err != nil {
+		s.logger.Error("Failed to get booking", zap.String("booking_code", code), zap.Error(err))
+		return nil, ErrBookingNotFound
+	}
+
+	if booking.UserID != userID {
+		return nil, ErrBookingNotOwned
+	}
+
+	return booking, nil
+}
+
+// CancelBooking membatalkan booking milik user. Booking yang sudah dibayar
+// di-refund penuh jika dibatalkan sebelum free window, setelah itu dipotong fee.
+func (s *bookingService) CancelBooking(ctx context.Context, userID, bookingID int) (*domain.Booking, error) {
+	booking, err := s.bookingRepo.GetByID(ctx, bookingID)
+	if err != nil {
+		s.logger.Error("Failed to get booking", zap.Int("booking_id", bookingID), zap.Error(err))
+		return nil, ErrBookingNotFound
 	}
 
+	if booking.UserID != userID {
+		return nil, ErrBookingNotOwned
+	}
+
+	switch booking.Status {
+	case "cancelled":
+		return nil, ErrBookingCancelled
+	case "expired":
+		return nil, ErrBookingExpired
+	}
+
+	now := s.now()
+	startsAt := booking.Showtime.StartsAt()
+	if !now.Before(startsAt) {
+		return nil, ErrShowtimeStarted
+	}
+
+	// Refund for paid bookings. It runs inside the cancel transaction, after the
+	// status guard, so a concurrent cancel can never refund the same payment twice.
+	var refund func() error
+	if payment := booking.Payment; booking.Status == "confirmed" && payment != nil && payment.Status == "success" {
+		fee := s.cancellationFee(payment.Amount, startsAt, now)
+		refundAmount := payment.Amount - fee
+
+		refund = func() error {
+			// Payments made before the gateway integration have no provider reference
+			if payment.ProviderReference != nil && payment.PaymentMethod != nil && refundAmount > 0 {
+				gw := s.gateways.Get(payment.PaymentMethod.Code)
+				if _, err := gw.Refund(ctx, *payment.ProviderReference, refundAmount); err != nil {
+					s.logger.Error("Failed to refund payment",
+						zap.Int("booking_id", bookingID),
+						zap.String("provider_reference", *payment.ProviderReference),
+						zap.Error(err),
+					)
+					return fmt.Errorf("failed to refund payment: %w", err)
+				}
+			}
+
+			payment.Status = "refunded"
+			payment.RefundAmount = &refundAmount
+			payment.RefundedAt = &now
+			return nil
+		}
+	}
+
+	if err := s.bookingRepo.Cancel(ctx, booking, now, refund); err != nil {
+		s.logger.Error("Failed to cancel booking", zap.Int("booking_id", bookingID), zap.Error(err))
+		if payment := booking.Payment; payment != nil && payment.Status == "refunded" && refund != nil {
+			// The provider refund went through but the cancellation was rolled back
+			s.logger.Error("Payment refunded but booking cancellation failed, needs manual reconciliation",
+				zap.Int("booking_id", bookingID),
+				zap.Int("payment_id", payment.ID),
+			)
+		}
+		return nil, fmt.Errorf("failed to cancel booking: %w", err)
+	}
+
+	s.logger.Info("Booking cancelled successfully",
+		zap.Int("booking_id", booking.ID),
+		zap.Int("user_id", userID),
+		zap.String("booking_code", booking.BookingCode),
+	)
+
+	// GOROUTINE: Async logging to file
+	utils.LogBookingAsync(s.logger, userID, booking.BookingCode, "cancelled")
+
 	return booking, nil
 }
+
+// cancellationFee menghitung potongan refund berdasarkan sisa waktu sebelum showtime
+func (s *bookingService) cancellationFee(amount domain.Money, startsAt, now time.Time) domain.Money {
+	if startsAt.Sub(now) >= s.config.Booking.CancellationFreeWindow {
+		return 0
+	}
+
+	return amount.Percent(s.config.Booking.CancellationFeePercent)
+}
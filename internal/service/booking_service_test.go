@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/gateway"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -18,8 +23,8 @@ type MockBookingRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookingRepository) Create(ctx context.Context, booking *domain.Booking) error {
-	args := m.Called(ctx, booking)
+func (m *MockBookingRepository) Reserve(ctx context.Context, booking *domain.Booking, now time.Time) error {
+	args := m.Called(ctx, booking, now)
 	return args.Error(0)
 }
 
@@ -31,6 +36,14 @@ func (m *MockBookingRepository) GetByID(ctx context.Context, id int) (*domain.Bo
 	return args.Get(0).(*domain.Booking), args.Error(1)
 }
 
+func (m *MockBookingRepository) GetByCode(ctx context.Context, code string) (*domain.Booking, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Booking), args.Error(1)
+}
+
 func (m *MockBookingRepository) GetByUserID(ctx context.Context, userID int) ([]*domain.Booking, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -39,16 +52,55 @@ func (m *MockBookingRepository) GetByUserID(ctx context.Context, userID int) ([]
 	return args.Get(0).([]*domain.Booking), args.Error(1)
 }
 
-func (m *MockBookingRepository) Update(ctx context.Context, booking *domain.Booking) error {
-	args := m.Called(ctx, booking)
-	return args.Error(0)
+func (m *MockBookingRepository) UpdateStatusIfPending(ctx context.Context, booking *domain.Booking, now time.Time) (bool, error) {
+	args := m.Called(ctx, booking, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBookingRepository) ExpireOverdue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBookingRepository) Cancel(ctx context.Context, booking *domain.Booking, now time.Time, refund func() error) error {
+	args := m.Called(ctx, booking, now)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	// Like the real repository, the refund only runs once the cancel wins
+	if refund != nil {
+		return refund()
+	}
+	return nil
 }
 
-func (m *MockBookingRepository) CheckSeatBooked(ctx context.Context, showtimeID, seatID int) (bool, error) {
-	args := m.Called(ctx, showtimeID, seatID)
+func (m *MockBookingRepository) MarkCheckedIn(ctx context.Context, bookingID int, at time.Time) (bool, error) {
+	args := m.Called(ctx, bookingID, at)
 	return args.Bool(0), args.Error(1)
 }
 
+func testBookingConfig() *config.Config {
+	return &config.Config{
+		Booking: config.BookingConfig{
+			PaymentWindow:          15 * time.Minute,
+			CancellationFreeWindow: 24 * time.Hour,
+			CancellationFeePercent: 25,
+			SalesCutoff:            15 * time.Minute,
+		},
+	}
+}
+
+// bookingTestNow adalah jam "sekarang" untuk test booking dan kursi: 10 Januari 2026 10:00 WIB
+var bookingTestNow = time.Date(2026, 1, 10, 10, 0, 0, 0, domain.TimezoneLocation(domain.DefaultTimezone))
+
+// upcomingShowDate adalah tanggal sehari setelah bookingTestNow, supaya penjualan showtime di test masih buka
+var upcomingShowDate = time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+
+// fixedClock mengembalikan jam yang selalu menunjuk ke at, untuk mengganti time.Now di service
+func fixedClock(at time.Time) func() time.Time {
+	return func() time.Time { return at }
+}
+
 type MockShowtimeRepository struct {
 	mock.Mock
 }
@@ -69,6 +121,58 @@ func (m *MockShowtimeRepository) GetByID(ctx context.Context, id int) (*domain.S
 	return args.Get(0).(*domain.Showtime), args.Error(1)
 }
 
+func (m *MockShowtimeRepository) GetUpcomingByMovieID(ctx context.Context, movieID int, from time.Time) ([]*domain.Showtime, error) {
+	args := m.Called(ctx, movieID, from)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Showtime), args.Error(1)
+}
+
+func (m *MockShowtimeRepository) GetByCinemaAndDate(ctx context.Context, cinemaID int, date time.Time) ([]*domain.ShowtimeAvailability, error) {
+	args := m.Called(ctx, cinemaID, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShowtimeAvailability), args.Error(1)
+}
+
+func (m *MockShowtimeRepository) GetByMovieAndDateRange(ctx context.Context, movieID int, from, to time.Time) ([]*domain.ShowtimeAvailability, error) {
+	args := m.Called(ctx, movieID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ShowtimeAvailability), args.Error(1)
+}
+
+func (m *MockShowtimeRepository) Create(ctx context.Context, showtime *domain.Showtime) error {
+	args := m.Called(ctx, showtime)
+	return args.Error(0)
+}
+
+func (m *MockShowtimeRepository) Update(ctx context.Context, showtime *domain.Showtime) error {
+	args := m.Called(ctx, showtime)
+	return args.Error(0)
+}
+
+func (m *MockShowtimeRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockShowtimeRepository) HasActiveBookings(ctx context.Context, id int) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockShowtimeRepository) GetOverlapping(ctx context.Context, screenID int, startsAt, freeAt time.Time, excludeID int) ([]*domain.Showtime, error) {
+	args := m.Called(ctx, screenID, startsAt, freeAt, excludeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Showtime), args.Error(1)
+}
+
 type MockSeatRepository struct {
 	mock.Mock
 }
@@ -81,6 +185,14 @@ func (m *MockSeatRepository) GetByCinemaID(ctx context.Context, cinemaID int) ([
 	return args.Get(0).([]*domain.Seat), args.Error(1)
 }
 
+func (m *MockSeatRepository) GetByScreenID(ctx context.Context, screenID int) ([]*domain.Seat, error) {
+	args := m.Called(ctx, screenID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Seat), args.Error(1)
+}
+
 func (m *MockSeatRepository) GetByID(ctx context.Context, id int) (*domain.Seat, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -89,14 +201,54 @@ func (m *MockSeatRepository) GetByID(ctx context.Context, id int) (*domain.Seat,
 	return args.Get(0).(*domain.Seat), args.Error(1)
 }
 
-func (m *MockSeatRepository) GetAvailableSeats(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatAvailability, error) {
-	args := m.Called(ctx, cinemaID, showtimeID)
+func (m *MockSeatRepository) GetAvailableSeats(ctx context.Context, screenID, showtimeID int) ([]*domain.SeatAvailability, error) {
+	args := m.Called(ctx, screenID, showtimeID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*domain.SeatAvailability), args.Error(1)
 }
 
+func (m *MockSeatRepository) Create(ctx context.Context, seat *domain.Seat) error {
+	args := m.Called(ctx, seat)
+	return args.Error(0)
+}
+
+func (m *MockSeatRepository) Update(ctx context.Context, seat *domain.Seat) error {
+	args := m.Called(ctx, seat)
+	return args.Error(0)
+}
+
+func (m *MockSeatRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+type MockScreenRepository struct {
+	mock.Mock
+}
+
+func (m *MockScreenRepository) GetByID(ctx context.Context, id int) (*domain.Screen, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Screen), args.Error(1)
+}
+
+func (m *MockScreenRepository) Create(ctx context.Context, screen *domain.Screen) error {
+	args := m.Called(ctx, screen)
+	return args.Error(0)
+}
+
+func (m *MockScreenRepository) ImportLayout(ctx context.Context, screen *domain.Screen, layout *domain.SeatLayout) ([]*domain.Seat, error) {
+	args := m.Called(ctx, screen, layout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Seat), args.Error(1)
+}
+
 type MockPaymentMethodRepository struct {
 	mock.Mock
 }
@@ -132,16 +284,18 @@ func TestBookingService_CreateBooking_Success(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
-	now := time.Now()
+	now := bookingTestNow
 
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
 		MovieID:  1,
-		Price:    50000,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
 	}
 
 	seat := &domain.Seat{
@@ -160,16 +314,15 @@ func TestBookingService_CreateBooking_Success(t *testing.T) {
 		ID:          1,
 		UserID:      1,
 		ShowtimeID:  1,
-		SeatID:      10,
 		BookingCode: "BK123456",
 		Status:      "pending",
-		TotalPrice:  50000,
+		TotalPrice:  domain.NewMoney(50000),
 		CreatedAt:   now,
 	}
 
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        10,
+		SeatIDs:       []int{10},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "CREDIT_CARD",
@@ -177,9 +330,9 @@ func TestBookingService_CreateBooking_Success(t *testing.T) {
 
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetByID", ctx, 10).Return(seat, nil)
-	mockBookingRepo.On("CheckSeatBooked", ctx, 1, 10).Return(false, nil)
 	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
-	mockBookingRepo.On("Create", ctx, mock.AnythingOfType("*domain.Booking")).Return(nil).Run(func(args mock.Arguments) {
+	// Timestamps come from the service clock, the same one the payment window is computed from
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), now).Return(nil).Run(func(args mock.Arguments) {
 		b := args.Get(1).(*domain.Booking)
 		b.ID = 1
 	})
@@ -196,6 +349,98 @@ func TestBookingService_CreateBooking_Success(t *testing.T) {
 	mockPaymentMethodRepo.AssertExpectations(t)
 }
 
+func TestBookingService_CreateBooking_MultipleSeats(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	showtime := &domain.Showtime{
+		ID:       1,
+		CinemaID: 1,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
+	}
+
+	req := &domain.BookingRequest{
+		CinemaID:      1,
+		SeatIDs:       []int{10, 11, 12, 13},
+		Date:          "2024-01-15",
+		Time:          "14:00",
+		PaymentMethod: "CREDIT_CARD",
+	}
+
+	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
+	for _, seatID := range req.SeatIDs {
+		mockSeatRepo.On("GetByID", ctx, seatID).Return(&domain.Seat{ID: seatID, CinemaID: 1}, nil)
+	}
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(&domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD"}, nil)
+
+	var created *domain.Booking
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*domain.Booking)
+		created.ID = 1
+	})
+	mockBookingRepo.On("GetByID", ctx, 1).Return(nil, errors.New("db error"))
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Len(t, created.Seats, 4)
+	assert.Equal(t, domain.NewMoney(200000), created.TotalPrice)
+	assert.Equal(t, 13, created.Seats[3].SeatID)
+	if assert.NotNil(t, created.ExpiresAt) {
+		assert.Equal(t, bookingTestNow.Add(15*time.Minute), *created.ExpiresAt)
+	}
+	mockSeatRepo.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_CreateBooking_DuplicateSeat(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	showtime := &domain.Showtime{
+		ID:       1,
+		CinemaID: 1,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
+	}
+
+	req := &domain.BookingRequest{
+		CinemaID:      1,
+		SeatIDs:       []int{10, 10},
+		Date:          "2024-01-15",
+		Time:          "14:00",
+		PaymentMethod: "CREDIT_CARD",
+	}
+
+	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 10).Return(&domain.Seat{ID: 10, CinemaID: 1}, nil).Once()
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "selected more than once")
+	mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestBookingService_CreateBooking_ShowtimeNotFound(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
@@ -203,12 +448,12 @@ func TestBookingService_CreateBooking_ShowtimeNotFound(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        10,
+		SeatIDs:       []int{10},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "CREDIT_CARD",
@@ -231,14 +476,16 @@ func TestBookingService_CreateBooking_SeatAlreadyBooked(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
-		Price:    50000,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
 	}
 
 	seat := &domain.Seat{
@@ -248,7 +495,7 @@ func TestBookingService_CreateBooking_SeatAlreadyBooked(t *testing.T) {
 
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        10,
+		SeatIDs:       []int{10},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "CREDIT_CARD",
@@ -256,18 +503,421 @@ func TestBookingService_CreateBooking_SeatAlreadyBooked(t *testing.T) {
 
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetByID", ctx, 10).Return(seat, nil)
-	mockBookingRepo.On("CheckSeatBooked", ctx, 1, 10).Return(true, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(&domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD"}, nil)
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).
+		Return(fmt.Errorf("%w (seat_id: [10])", ErrSeatAlreadyTaken))
 
 	result, err := service.CreateBooking(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
+	assert.ErrorIs(t, err, ErrSeatAlreadyTaken)
 	assert.Contains(t, err.Error(), "seat is already booked")
 	mockShowtimeRepo.AssertExpectations(t)
 	mockSeatRepo.AssertExpectations(t)
 	mockBookingRepo.AssertExpectations(t)
 }
 
+func TestBookingService_CreateBooking_ByShowtimeID(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	showtime := &domain.Showtime{ID: 7, CinemaID: 2, MovieID: 3, Price: domain.NewMoney(45000), ShowDate: upcomingShowDate}
+	seat := &domain.Seat{ID: 10, CinemaID: 2, SeatRow: "A"}
+
+	req := &domain.BookingRequest{
+		ShowtimeID:    7,
+		SeatIDs:       []int{10},
+		PaymentMethod: "GOPAY",
+	}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 10).Return(seat, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{ID: 1, Code: "GOPAY"}, nil)
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Booking).ID = 1
+	})
+	mockBookingRepo.On("GetByID", ctx, 1).Return(&domain.Booking{ID: 1, ShowtimeID: 7}, nil)
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, result.ShowtimeID)
+	mockShowtimeRepo.AssertNotCalled(t, "GetByCinemaDateTime", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+// witaShowtime adalah showtime 11 Januari 2026 19:00 di cinema berzona WITA
+func witaShowtime() *domain.Showtime {
+	return &domain.Showtime{
+		ID:       7,
+		CinemaID: 1,
+		ScreenID: 1,
+		Price:    domain.NewMoney(45000),
+		ShowDate: time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC),
+		ShowTime: time.Date(0, 1, 1, 19, 0, 0, 0, time.UTC),
+		Timezone: domain.TimezoneWITA,
+	}
+}
+
+func TestBookingService_CreateBooking_SalesClosed(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+
+	service := NewBookingService(new(MockBookingRepository), mockShowtimeRepo, mockSeatRepo, new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	// 19:15 WITA: tepat 15 menit setelah film mulai, penjualan sudah ditutup
+	service.now = fixedClock(time.Date(2026, 1, 11, 19, 15, 0, 0, domain.TimezoneLocation(domain.TimezoneWITA)))
+
+	ctx := context.Background()
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(witaShowtime(), nil)
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30}, PaymentMethod: "GOPAY"})
+
+	assert.ErrorIs(t, err, ErrSalesClosed)
+	assert.Contains(t, err.Error(), "2026-01-11T19:15:00+08:00")
+	assert.Nil(t, result)
+	mockSeatRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CreateBooking_WithinSalesCutoff(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	// 18:10 WIB sama dengan 19:10 WITA: film sudah mulai 10 menit, tiket masih dijual
+	now := time.Date(2026, 1, 11, 18, 10, 0, 0, domain.TimezoneLocation(domain.TimezoneWIB))
+	service.now = fixedClock(now)
+
+	ctx := context.Background()
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(witaShowtime(), nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(&domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1, SeatRow: "A"}, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{ID: 1, Code: "GOPAY"}, nil)
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Booking).ID = 1
+	})
+	mockBookingRepo.On("GetByID", ctx, 1).Return(nil, errors.New("not found"))
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30}, PaymentMethod: "GOPAY"})
+
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(15*time.Minute), *result.ExpiresAt)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_CreateBooking_ShowtimeIDOtherCinema(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	req := &domain.BookingRequest{
+		ShowtimeID:    7,
+		CinemaID:      1,
+		SeatIDs:       []int{10},
+		PaymentMethod: "GOPAY",
+	}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(&domain.Showtime{ID: 7, CinemaID: 2, ShowDate: upcomingShowDate}, nil)
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrShowtimeNotFound)
+	assert.Nil(t, result)
+	mockSeatRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CreateBooking_SeatInOtherScreen(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	// Studio 1 and Studio 2 belong to the same cinema
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(45000), ShowDate: upcomingShowDate}
+	seat := &domain.Seat{ID: 30, CinemaID: 1, ScreenID: 2, SeatRow: "A"}
+
+	req := &domain.BookingRequest{
+		ShowtimeID:    7,
+		SeatIDs:       []int{30},
+		PaymentMethod: "GOPAY",
+	}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(seat, nil)
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "not in the screen of this showtime")
+	mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CreateBooking_SeatTypePricing(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	pricing := NewPricingService(staticPriceRules{
+		multiplierRule(1, "regular", 1),
+		multiplierRule(2, "vip", 1.5),
+	}, zap.NewNop())
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, pricing, testPromotions(), testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(40000), ShowDate: upcomingShowDate}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(&domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1, SeatType: "regular"}, nil)
+	mockSeatRepo.On("GetByID", ctx, 31).Return(&domain.Seat{ID: 31, CinemaID: 1, ScreenID: 1, SeatType: "vip"}, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{Code: "GOPAY"}, nil)
+
+	var reserved *domain.Booking
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		reserved = args.Get(1).(*domain.Booking)
+		reserved.ID = 9
+	})
+	mockBookingRepo.On("GetByID", ctx, 9).Return(nil, errors.New("not found"))
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30, 31}, PaymentMethod: "GOPAY"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(100000), result.TotalPrice)
+	assert.Equal(t, domain.NewMoney(40000), reserved.Seats[0].Price)
+	assert.Equal(t, domain.NewMoney(60000), reserved.Seats[1].Price)
+	assert.Equal(t, domain.NewMoney(40000), reserved.Seats[1].PriceBreakdown.BasePrice)
+	assert.Equal(t, 1.5, *reserved.Seats[1].PriceBreakdown.Multiplier)
+	assert.Equal(t, 2, *reserved.Seats[1].PriceBreakdown.RuleID)
+}
+
+func TestBookingService_CreateBooking_PromoCode(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), promotions, testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, MovieID: 3, Price: domain.NewMoney(40000), ShowDate: upcomingShowDate}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(&domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1}, nil)
+	mockSeatRepo.On("GetByID", ctx, 31).Return(&domain.Seat{ID: 31, CinemaID: 1, ScreenID: 1}, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{Code: "GOPAY"}, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "hemat20").Return(testPromotion(), nil)
+
+	var reserved *domain.Booking
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		reserved = args.Get(1).(*domain.Booking)
+		reserved.ID = 9
+	})
+	mockBookingRepo.On("GetByID", ctx, 9).Return(nil, errors.New("not found"))
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30, 31}, PaymentMethod: "GOPAY", PromoCode: "hemat20"})
+
+	require.NoError(t, err)
+	// 20% of 80000, capped at 15000
+	assert.Equal(t, domain.NewMoney(65000), result.TotalPrice)
+	assert.Equal(t, domain.NewMoney(15000), result.DiscountAmount)
+	assert.Equal(t, "HEMAT20", *result.PromoCode)
+	require.NotNil(t, reserved.Redemption)
+	assert.Equal(t, 1, reserved.Redemption.PromotionID)
+}
+
+func TestBookingService_CreateBooking_PromoCodeExhausted(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), promotions, testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(40000), ShowDate: upcomingShowDate}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(&domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1}, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{Code: "GOPAY"}, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+	// Another booking took the last redemption between quote and reservation
+	mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(fmt.Errorf("failed to reserve: %w", ErrPromotionExhausted))
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30}, PaymentMethod: "GOPAY", PromoCode: "HEMAT20"})
+
+	assert.ErrorIs(t, err, ErrPromotionExhausted)
+	assert.Nil(t, result)
+}
+
+func TestBookingService_CreateBooking_PromoCodeNotApplicable(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), promotions, testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(40000), ShowDate: upcomingShowDate}
+	promo := testPromotion()
+	promo.PaymentMethods = []string{"CREDIT_CARD"}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(&domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1}, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{Code: "GOPAY"}, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(promo, nil)
+
+	result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30}, PaymentMethod: "GOPAY", PromoCode: "HEMAT20"})
+
+	assert.ErrorIs(t, err, ErrPromotionNotApplicable)
+	assert.Nil(t, result)
+	mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CreateBooking_BlockedSeat(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(45000), ShowDate: upcomingShowDate}
+	seat := &domain.Seat{ID: 30, CinemaID: 1, ScreenID: 1, SeatRow: "D", SeatNumber: 4, IsBlocked: true}
+
+	req := &domain.BookingRequest{
+		ShowtimeID:    7,
+		SeatIDs:       []int{30},
+		PaymentMethod: "GOPAY",
+	}
+
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetByID", ctx, 30).Return(seat, nil)
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "seat D4 is not available")
+	mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CreateBooking_CoupleSeat(t *testing.T) {
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 1, Price: domain.NewMoney(45000), ShowDate: upcomingShowDate}
+	leftID, rightID := 30, 31
+	left := &domain.Seat{ID: leftID, CinemaID: 1, ScreenID: 1, SeatRow: "E", SeatNumber: 1, PairSeatID: &rightID}
+	right := &domain.Seat{ID: rightID, CinemaID: 1, ScreenID: 1, SeatRow: "E", SeatNumber: 2, PairSeatID: &leftID}
+
+	t.Run("without its pair", func(t *testing.T) {
+		mockBookingRepo := new(MockBookingRepository)
+		mockShowtimeRepo := new(MockShowtimeRepository)
+		mockSeatRepo := new(MockSeatRepository)
+		service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+		service.now = fixedClock(bookingTestNow)
+
+		mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+		mockSeatRepo.On("GetByID", ctx, 30).Return(left, nil)
+
+		result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30}, PaymentMethod: "GOPAY"})
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "E1 is a couple seat")
+		mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("together with its pair", func(t *testing.T) {
+		mockBookingRepo := new(MockBookingRepository)
+		mockShowtimeRepo := new(MockShowtimeRepository)
+		mockSeatRepo := new(MockSeatRepository)
+		mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+		service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), zap.NewNop()).(*bookingService)
+		service.now = fixedClock(bookingTestNow)
+
+		mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+		mockSeatRepo.On("GetByID", ctx, 30).Return(left, nil)
+		mockSeatRepo.On("GetByID", ctx, 31).Return(right, nil)
+		mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(&domain.PaymentMethod{Code: "GOPAY"}, nil)
+		mockBookingRepo.On("Reserve", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.Booking).ID = 9
+		})
+		mockBookingRepo.On("GetByID", ctx, 9).Return(nil, errors.New("not found"))
+
+		result, err := service.CreateBooking(ctx, 1, &domain.BookingRequest{ShowtimeID: 7, SeatIDs: []int{30, 31}, PaymentMethod: "GOPAY"})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Seats, 2)
+		assert.Equal(t, domain.NewMoney(90000), result.TotalPrice)
+	})
+}
+
+func TestBookingService_CreateBooking_AmbiguousShowtime(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockSeatRepo := new(MockSeatRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+
+	req := &domain.BookingRequest{
+		CinemaID:      1,
+		SeatIDs:       []int{10},
+		Date:          "2024-01-15",
+		Time:          "14:00",
+		PaymentMethod: "GOPAY",
+	}
+
+	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").
+		Return(nil, fmt.Errorf("%w: cinema_id=1", ErrAmbiguousShowtime))
+
+	result, err := service.CreateBooking(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrAmbiguousShowtime)
+	assert.Contains(t, err.Error(), "showtime_id")
+	assert.Nil(t, result)
+	mockBookingRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestBookingService_GetBookingByID_Success(t *testing.T) {
 	mockBookingRepo := new(MockBookingRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
@@ -275,7 +925,7 @@ func TestBookingService_GetBookingByID_Success(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 	booking := &domain.Booking{
@@ -300,19 +950,21 @@ func TestBookingService_CreateBooking_SeatNotFound(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
-		Price:    50000,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
 	}
 
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        999,
+		SeatIDs:       []int{999},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "CREDIT_CARD",
@@ -337,14 +989,16 @@ func TestBookingService_CreateBooking_SeatNotInCinema(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
-		Price:    50000,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
 	}
 
 	seat := &domain.Seat{
@@ -355,7 +1009,7 @@ func TestBookingService_CreateBooking_SeatNotInCinema(t *testing.T) {
 
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        10,
+		SeatIDs:       []int{10},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "CREDIT_CARD",
@@ -380,14 +1034,16 @@ func TestBookingService_CreateBooking_InvalidPaymentMethod(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
-		Price:    50000,
+		Price:    domain.NewMoney(50000),
+		ShowDate: upcomingShowDate,
 	}
 
 	seat := &domain.Seat{
@@ -398,7 +1054,7 @@ func TestBookingService_CreateBooking_InvalidPaymentMethod(t *testing.T) {
 
 	req := &domain.BookingRequest{
 		CinemaID:      1,
-		SeatID:        10,
+		SeatIDs:       []int{10},
 		Date:          "2024-01-15",
 		Time:          "14:00",
 		PaymentMethod: "INVALID_METHOD",
@@ -406,7 +1062,6 @@ func TestBookingService_CreateBooking_InvalidPaymentMethod(t *testing.T) {
 
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetByID", ctx, 10).Return(seat, nil)
-	mockBookingRepo.On("CheckSeatBooked", ctx, 1, 10).Return(false, nil)
 	mockPaymentMethodRepo.On("GetByCode", ctx, "INVALID_METHOD").Return(nil, errors.New("not found"))
 
 	result, err := service.CreateBooking(ctx, 1, req)
@@ -427,7 +1082,7 @@ func TestBookingService_GetBookingByID_NotFound(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 
@@ -448,7 +1103,7 @@ func TestBookingService_GetUserBookings_Success(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 	bookings := []*domain.Booking{
@@ -472,7 +1127,7 @@ func TestBookingService_GetUserBookings_Error(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, logger)
+	service := NewBookingService(mockBookingRepo, mockShowtimeRepo, mockSeatRepo, mockPaymentMethodRepo, testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 
@@ -484,3 +1139,290 @@ func TestBookingService_GetUserBookings_Error(t *testing.T) {
 	assert.Nil(t, result)
 	mockBookingRepo.AssertExpectations(t)
 }
+
+// paidBooking membuat booking confirmed dengan showtime yang mulai setelah startsIn,
+// di cinema berzona WIT (jam tayang disimpan sebagai jam lokal cinema), dihitung dari bookingTestNow
+func paidBooking(startsIn time.Duration) *domain.Booking {
+	startsAt := bookingTestNow.In(domain.TimezoneLocation(domain.TimezoneWIT)).Add(startsIn)
+	return &domain.Booking{
+		ID:          1,
+		UserID:      1,
+		BookingCode: "BK001",
+		Status:      "confirmed",
+		TotalPrice:  domain.NewMoney(100000),
+		Showtime: &domain.Showtime{
+			ShowDate: startsAt,
+			ShowTime: startsAt,
+			Timezone: domain.TimezoneWIT,
+		},
+		Payment: &domain.Payment{ID: 10, Amount: domain.NewMoney(100000), Status: "success"},
+	}
+}
+
+func TestBookingService_CancelBooking_FullRefund(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	booking := paidBooking(48 * time.Hour)
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "refunded", result.Payment.Status)
+	assert.Equal(t, domain.NewMoney(100000), *result.Payment.RefundAmount)
+	assert.NotNil(t, result.Payment.RefundedAt)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_CancelBooking_FeeWithinWindow(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	booking := paidBooking(2 * time.Hour)
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "refunded", result.Payment.Status)
+	assert.Equal(t, domain.NewMoney(75000), *result.Payment.RefundAmount)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_CancelBooking_PendingNoRefund(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	booking := paidBooking(48 * time.Hour)
+	booking.Status = "pending"
+	booking.Payment = nil
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.NoError(t, err)
+	assert.Nil(t, result.Payment)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_CancelBooking_NotOwner(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(paidBooking(48*time.Hour), nil)
+
+	result, err := service.CancelBooking(ctx, 2, 1)
+
+	assert.ErrorIs(t, err, ErrBookingNotOwned)
+	assert.Nil(t, result)
+	mockBookingRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CancelBooking_ShowtimeStarted(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(paidBooking(-time.Hour), nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "already started")
+	mockBookingRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBookingService_CancelBooking_NotFound(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+
+	mockBookingRepo.On("GetByID", ctx, 999).Return(nil, errors.New("not found"))
+
+	result, err := service.CancelBooking(ctx, 1, 999)
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+	assert.Nil(t, result)
+}
+
+func TestBookingService_CancelBooking_RefundFailsAtProvider(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), simulatorGateways(gateway.ModeTimeout), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	reference := "SIM-GOPAY-1-1"
+	booking := paidBooking(48 * time.Hour)
+	booking.Payment.ProviderReference = &reference
+	booking.Payment.PaymentMethod = &domain.PaymentMethod{ID: 1, Code: "GOPAY"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "failed to refund")
+	assert.Equal(t, "success", booking.Payment.Status)
+}
+
+func TestBookingService_CancelBooking_AsyncPaymentRefunded(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	gateways := simulatorGateways(gateway.ModeAsync)
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), gateways, testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+	paymentService := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), gateways, testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	// GOPAY payment that stayed pending until the provider reported success
+	auth, err := gateways.Get("GOPAY").Authorize(ctx, &gateway.AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(100000)})
+	require.NoError(t, err)
+
+	payment := &domain.Payment{
+		ID:                10,
+		BookingID:         1,
+		Amount:            domain.NewMoney(100000),
+		Status:            "pending",
+		ProviderReference: &auth.Reference,
+		PaymentMethod:     &domain.PaymentMethod{ID: 3, Code: "GOPAY", Name: "GoPay"},
+	}
+	pending := paidBooking(48 * time.Hour)
+	pending.Status = "pending"
+	payload := []byte(`{"reference": "` + auth.Reference + `", "status": "success"}`)
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, auth.Reference).Return(payment, nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, payment).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(pending, nil).Once()
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, pending, mock.Anything).Return(true, nil)
+
+	require.NoError(t, paymentService.HandleWebhook(ctx, "GOPAY", payload, utils.SignHMAC(testWebhookSecret, payload)))
+
+	booking := paidBooking(48 * time.Hour)
+	booking.Payment = payment
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil).Once()
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(nil)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "refunded", result.Payment.Status)
+	assert.Equal(t, domain.NewMoney(100000), *result.Payment.RefundAmount)
+	status, err := gateways.Get("GOPAY").Status(ctx, auth.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, gateway.StatusRefunded, status.Status)
+}
+
+func TestBookingService_CancelBooking_ConcurrentCancelSkipsRefund(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), simulatorGateways(gateway.ModeTimeout), testBookingConfig(), logger).(*bookingService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	reference := "SIM-GOPAY-1-1"
+	booking := paidBooking(48 * time.Hour)
+	booking.Payment.ProviderReference = &reference
+	booking.Payment.PaymentMethod = &domain.PaymentMethod{ID: 1, Code: "GOPAY"}
+
+	// Another request cancelled the booking first; the gateway (which would fail)
+	// must not be reached at all
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("Cancel", ctx, booking, mock.Anything).Return(ErrBookingStatusChanged)
+
+	result, err := service.CancelBooking(ctx, 1, 1)
+
+	assert.ErrorIs(t, err, ErrBookingStatusChanged)
+	assert.Nil(t, result)
+	assert.Equal(t, "success", booking.Payment.Status)
+	assert.Nil(t, booking.Payment.RefundAmount)
+}
+
+func TestBookingService_GetBookingByCode_Success(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, BookingCode: "BK0123456789ab"}
+
+	mockBookingRepo.On("GetByCode", ctx, "BK0123456789ab").Return(booking, nil)
+
+	result, err := service.GetBookingByCode(ctx, 1, "BK0123456789ab")
+
+	assert.NoError(t, err)
+	assert.Equal(t, booking, result)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestBookingService_GetBookingByCode_NotOwner(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+
+	mockBookingRepo.On("GetByCode", ctx, "BK0123456789ab").Return(&domain.Booking{ID: 1, UserID: 2}, nil)
+
+	result, err := service.GetBookingByCode(ctx, 1, "BK0123456789ab")
+
+	assert.ErrorIs(t, err, ErrBookingNotOwned)
+	assert.Nil(t, result)
+}
+
+func TestBookingService_GetBookingByCode_NotFound(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewBookingService(mockBookingRepo, new(MockShowtimeRepository), new(MockSeatRepository), new(MockPaymentMethodRepository), testPricing(), testPromotions(), testGateways(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+
+	mockBookingRepo.On("GetByCode", ctx, "BKUNKNOWN").Return(nil, errors.New("booking not found"))
+
+	result, err := service.GetBookingByCode(ctx, 1, "BKUNKNOWN")
+
+	assert.ErrorIs(t, err, ErrBookingNotFound)
+	assert.Nil(t, result)
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/repository"
@@ -12,19 +14,34 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrCinemaNotFound dikembalikan ketika cinema tidak ada
+	ErrCinemaNotFound = errors.New("cinema not found")
+	// ErrCinemaHasShowtimes dikembalikan ketika cinema yang akan dihapus masih punya jadwal tayang mendatang
+	ErrCinemaHasShowtimes = repository.ErrCinemaHasShowtimes
+	// ErrScreenExists dikembalikan ketika nama studio sudah dipakai di cinema yang sama
+	ErrScreenExists = repository.ErrScreenExists
+)
+
 type CinemaService interface {
 	GetAllCinemas(ctx context.Context, page, limit int) ([]*domain.Cinema, *utils.PaginationMeta, error)
 	GetCinemaByID(ctx context.Context, id int) (*domain.Cinema, error)
+	CreateCinema(ctx context.Context, req *domain.CinemaRequest) (*domain.Cinema, error)
+	UpdateCinema(ctx context.Context, id int, req *domain.CinemaRequest) (*domain.Cinema, error)
+	DeleteCinema(ctx context.Context, id int) error
+	CreateScreen(ctx context.Context, cinemaID int, req *domain.ScreenRequest) (*domain.Screen, error)
 }
 
 type cinemaService struct {
 	cinemaRepo repository.CinemaRepository
+	screenRepo repository.ScreenRepository
 	logger     *zap.Logger
 }
 
-func NewCinemaService(cinemaRepo repository.CinemaRepository, logger *zap.Logger) CinemaService {
+func NewCinemaService(cinemaRepo repository.CinemaRepository, screenRepo repository.ScreenRepository, logger *zap.Logger) CinemaService {
 	return &cinemaService{
 		cinemaRepo: cinemaRepo,
+		screenRepo: screenRepo,
 		logger:     logger,
 	}
 }
@@ -63,8 +80,96 @@ func (s *cinemaService) GetCinemaByID(ctx context.Context, id int) (*domain.Cine
 	cinema, err := s.cinemaRepo.GetByID(ctx, id)
 	if err != nil {
 		s.logger.Error("Failed to get cinema", zap.Int("cinema_id", id), zap.Error(err))
-		return nil, fmt.Errorf("cinema not found")
+		return nil, ErrCinemaNotFound
+	}
+
+	return cinema, nil
+}
+
+// CreateCinema menambah cinema baru (admin)
+func (s *cinemaService) CreateCinema(ctx context.Context, req *domain.CinemaRequest) (*domain.Cinema, error) {
+	cinema := &domain.Cinema{
+		Name:        strings.TrimSpace(req.Name),
+		Location:    strings.TrimSpace(req.Location),
+		Description: strings.TrimSpace(req.Description),
+		Timezone:    cinemaTimezone(req.Timezone),
+	}
+
+	if err := s.cinemaRepo.Create(ctx, cinema); err != nil {
+		s.logger.Error("Failed to create cinema", zap.Error(err))
+		return nil, fmt.Errorf("failed to create cinema: %w", err)
+	}
+
+	s.logger.Info("Cinema created", zap.Int("cinema_id", cinema.ID), zap.String("name", cinema.Name))
+	return cinema, nil
+}
+
+// UpdateCinema mengubah data cinema (admin)
+func (s *cinemaService) UpdateCinema(ctx context.Context, id int, req *domain.CinemaRequest) (*domain.Cinema, error) {
+	cinema := &domain.Cinema{
+		ID:          id,
+		Name:        strings.TrimSpace(req.Name),
+		Location:    strings.TrimSpace(req.Location),
+		Description: strings.TrimSpace(req.Description),
+		Timezone:    cinemaTimezone(req.Timezone),
+	}
+
+	if err := s.cinemaRepo.Update(ctx, cinema); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrCinemaNotFound
+		}
+		s.logger.Error("Failed to update cinema", zap.Int("cinema_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to update cinema: %w", err)
 	}
 
+	s.logger.Info("Cinema updated", zap.Int("cinema_id", id))
 	return cinema, nil
 }
+
+// cinemaTimezone memakai DefaultTimezone (WIB) jika zona waktu tidak diisi
+func cinemaTimezone(name string) string {
+	if name == "" {
+		return domain.DefaultTimezone
+	}
+	return name
+}
+
+// DeleteCinema menghapus cinema (soft delete), ditolak selama masih ada jadwal tayang mendatang
+func (s *cinemaService) DeleteCinema(ctx context.Context, id int) error {
+	if err := s.cinemaRepo.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrCinemaNotFound
+		case errors.Is(err, ErrCinemaHasShowtimes):
+			return err
+		}
+		s.logger.Error("Failed to delete cinema", zap.Int("cinema_id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete cinema: %w", err)
+	}
+
+	s.logger.Info("Cinema deleted", zap.Int("cinema_id", id))
+	return nil
+}
+
+// CreateScreen menambah studio ke cinema; denah kursinya diupload terpisah
+func (s *cinemaService) CreateScreen(ctx context.Context, cinemaID int, req *domain.ScreenRequest) (*domain.Screen, error) {
+	if _, err := s.cinemaRepo.GetByID(ctx, cinemaID); err != nil {
+		return nil, ErrCinemaNotFound
+	}
+
+	screen := &domain.Screen{
+		CinemaID: cinemaID,
+		Name:     strings.TrimSpace(req.Name),
+	}
+
+	if err := s.screenRepo.Create(ctx, screen); err != nil {
+		if errors.Is(err, ErrScreenExists) {
+			return nil, err
+		}
+		s.logger.Error("Failed to create screen", zap.Int("cinema_id", cinemaID), zap.Error(err))
+		return nil, fmt.Errorf("failed to create screen: %w", err)
+	}
+
+	s.logger.Info("Screen created", zap.Int("cinema_id", cinemaID), zap.Int("screen_id", screen.ID))
+	return screen, nil
+}
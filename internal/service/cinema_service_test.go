@@ -3,9 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -29,10 +31,25 @@ func (m *MockCinemaRepository) GetByID(ctx context.Context, id int) (*domain.Cin
 	return args.Get(0).(*domain.Cinema), args.Error(1)
 }
 
+func (m *MockCinemaRepository) Create(ctx context.Context, cinema *domain.Cinema) error {
+	args := m.Called(ctx, cinema)
+	return args.Error(0)
+}
+
+func (m *MockCinemaRepository) Update(ctx context.Context, cinema *domain.Cinema) error {
+	args := m.Called(ctx, cinema)
+	return args.Error(0)
+}
+
+func (m *MockCinemaRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func TestCinemaService_GetAllCinemas(t *testing.T) {
 	mockRepo := new(MockCinemaRepository)
 	logger, _ := zap.NewDevelopment()
-	service := NewCinemaService(mockRepo, logger)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), logger)
 
 	cinemas := []*domain.Cinema{
 		{ID: 1, Name: "Cinema 1"},
@@ -53,7 +70,7 @@ func TestCinemaService_GetAllCinemas(t *testing.T) {
 func TestCinemaService_GetCinemaByID(t *testing.T) {
 	mockRepo := new(MockCinemaRepository)
 	logger, _ := zap.NewDevelopment()
-	service := NewCinemaService(mockRepo, logger)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), logger)
 
 	cinema := &domain.Cinema{ID: 1, Name: "CGV Grand Indonesia"}
 	mockRepo.On("GetByID", mock.Anything, 1).Return(cinema, nil)
@@ -69,7 +86,7 @@ func TestCinemaService_GetCinemaByID(t *testing.T) {
 // func TestCinemaService_GetAllCinemas_Error(t *testing.T) {
 // 	mockRepo := new(MockCinemaRepository)
 // 	logger := zap.NewNop()
-// 	service := NewCinemaService(mockRepo, logger)
+// 	service := NewCinemaService(mockRepo, new(MockScreenRepository), logger)
 
 // 	mockRepo.On("GetAll", mock.Anything, 10, 0).Return(nil, 0, errors.New("database error"))
 
@@ -84,7 +101,7 @@ func TestCinemaService_GetCinemaByID(t *testing.T) {
 func TestCinemaService_GetAllCinemas_EmptyResult(t *testing.T) {
 	mockRepo := new(MockCinemaRepository)
 	logger := zap.NewNop()
-	service := NewCinemaService(mockRepo, logger)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), logger)
 
 	emptyCinemas := []*domain.Cinema{}
 
@@ -101,7 +118,7 @@ func TestCinemaService_GetAllCinemas_EmptyResult(t *testing.T) {
 func TestCinemaService_GetCinemaByID_NotFound(t *testing.T) {
 	mockRepo := new(MockCinemaRepository)
 	logger := zap.NewNop()
-	service := NewCinemaService(mockRepo, logger)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), logger)
 
 	mockRepo.On("GetByID", mock.Anything, 999).Return(nil, errors.New("cinema not found"))
 
@@ -111,3 +128,27 @@ func TestCinemaService_GetCinemaByID_NotFound(t *testing.T) {
 	assert.Nil(t, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestCinemaService_DeleteCinema_HasShowtimes(t *testing.T) {
+	mockRepo := new(MockCinemaRepository)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), zap.NewNop())
+
+	mockRepo.On("Delete", mock.Anything, 1).Return(ErrCinemaHasShowtimes)
+
+	err := service.DeleteCinema(context.Background(), 1)
+
+	assert.ErrorIs(t, err, ErrCinemaHasShowtimes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCinemaService_DeleteCinema_NotFound(t *testing.T) {
+	mockRepo := new(MockCinemaRepository)
+	service := NewCinemaService(mockRepo, new(MockScreenRepository), zap.NewNop())
+
+	mockRepo.On("Delete", mock.Anything, 999).Return(fmt.Errorf("cinema %w", repository.ErrNotFound))
+
+	err := service.DeleteCinema(context.Background(), 999)
+
+	assert.ErrorIs(t, err, ErrCinemaNotFound)
+	mockRepo.AssertExpectations(t)
+}
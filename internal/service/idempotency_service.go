@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrIdempotencyKeyInProgress dikembalikan ketika request dengan key yang sama masih diproses
+	ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is still being processed")
+	// ErrIdempotencyKeyMismatch dikembalikan ketika key dipakai ulang untuk request yang berbeda
+	ErrIdempotencyKeyMismatch = errors.New("Idempotency-Key was already used with a different request")
+)
+
+type IdempotencyService interface {
+	Begin(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error)
+	Complete(ctx context.Context, record *domain.IdempotencyKey, statusCode int, body []byte) error
+	Release(ctx context.Context, record *domain.IdempotencyKey) error
+}
+
+type idempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepository
+	config          *config.Config
+	logger          *zap.Logger
+}
+
+func NewIdempotencyService(
+	idempotencyRepo repository.IdempotencyRepository,
+	config *config.Config,
+	logger *zap.Logger,
+) IdempotencyService {
+	return &idempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		config:          config,
+		logger:          logger,
+	}
+}
+
+// Begin mengklaim key untuk request baru. Jika key sudah selesai diproses,
+// record yang dikembalikan berisi response awal (IsCompleted true) untuk di-replay.
+func (s *idempotencyService) Begin(ctx context.Context, userID int, key, requestHash string) (*domain.IdempotencyKey, error) {
+	record := &domain.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(s.config.Idempotency.KeyTTL),
+	}
+
+	created, err := s.idempotencyRepo.Create(ctx, record)
+	if err != nil {
+		s.logger.Error("Failed to store idempotency key", zap.Int("user_id", userID), zap.Error(err))
+		return nil, fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	if created {
+		return record, nil
+	}
+
+	existing, err := s.idempotencyRepo.GetByKey(ctx, userID, key)
+	if err != nil {
+		// Key was released between the insert and the lookup
+		s.logger.Warn("Idempotency key disappeared", zap.Int("user_id", userID), zap.Error(err))
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if !existing.IsCompleted() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	s.logger.Info("Replaying idempotent response",
+		zap.Int("user_id", userID),
+		zap.String("idempotency_key", key),
+		zap.Int("status_code", existing.StatusCode),
+	)
+
+	return existing, nil
+}
+
+// Complete menyimpan response agar retry berikutnya mendapat hasil yang sama
+func (s *idempotencyService) Complete(ctx context.Context, record *domain.IdempotencyKey, statusCode int, body []byte) error {
+	record.StatusCode = statusCode
+	record.ResponseBody = body
+
+	if err := s.idempotencyRepo.Complete(ctx, record); err != nil {
+		s.logger.Error("Failed to store idempotent response", zap.Int("idempotency_key_id", record.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Release menghapus key supaya request bisa dicoba lagi (mis. setelah server error)
+func (s *idempotencyService) Release(ctx context.Context, record *domain.IdempotencyKey) error {
+	if err := s.idempotencyRepo.Delete(ctx, record.ID); err != nil {
+		s.logger.Error("Failed to release idempotency key", zap.Int("idempotency_key_id", record.ID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Create(ctx context.Context, key *domain.IdempotencyKey) (bool, error) {
+	args := m.Called(ctx, key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, userID int, key string) (*domain.IdempotencyKey, error) {
+	args := m.Called(ctx, userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key *domain.IdempotencyKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func testIdempotencyConfig() *config.Config {
+	return &config.Config{
+		Idempotency: config.IdempotencyConfig{KeyTTL: 24 * time.Hour},
+	}
+}
+
+func TestIdempotencyService_Begin_NewKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(k *domain.IdempotencyKey) bool {
+		return k.UserID == 1 && k.Key == "retry-123" && k.RequestHash == "hash" && k.ExpiresAt.After(time.Now())
+	})).Return(true, nil)
+
+	record, err := service.Begin(ctx, 1, "retry-123", "hash")
+
+	assert.NoError(t, err)
+	assert.False(t, record.IsCompleted())
+	mockRepo.AssertNotCalled(t, "GetByKey", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyService_Begin_Replay(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	now := time.Now()
+	existing := &domain.IdempotencyKey{
+		ID:           7,
+		UserID:       1,
+		Key:          "retry-123",
+		RequestHash:  "hash",
+		StatusCode:   201,
+		ResponseBody: []byte(`{"success":true}`),
+		CompletedAt:  &now,
+	}
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.IdempotencyKey")).Return(false, nil)
+	mockRepo.On("GetByKey", ctx, 1, "retry-123").Return(existing, nil)
+
+	record, err := service.Begin(ctx, 1, "retry-123", "hash")
+
+	assert.NoError(t, err)
+	assert.True(t, record.IsCompleted())
+	assert.Equal(t, 201, record.StatusCode)
+}
+
+func TestIdempotencyService_Begin_InProgress(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.IdempotencyKey")).Return(false, nil)
+	mockRepo.On("GetByKey", ctx, 1, "retry-123").Return(&domain.IdempotencyKey{ID: 7, RequestHash: "hash"}, nil)
+
+	record, err := service.Begin(ctx, 1, "retry-123", "hash")
+
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+	assert.Nil(t, record)
+}
+
+func TestIdempotencyService_Begin_DifferentRequest(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	now := time.Now()
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.IdempotencyKey")).Return(false, nil)
+	mockRepo.On("GetByKey", ctx, 1, "retry-123").Return(&domain.IdempotencyKey{ID: 7, RequestHash: "other", CompletedAt: &now}, nil)
+
+	record, err := service.Begin(ctx, 1, "retry-123", "hash")
+
+	assert.ErrorIs(t, err, ErrIdempotencyKeyMismatch)
+	assert.Nil(t, record)
+}
+
+func TestIdempotencyService_Begin_StoreError(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.IdempotencyKey")).Return(false, errors.New("database error"))
+
+	record, err := service.Begin(ctx, 1, "retry-123", "hash")
+
+	assert.Error(t, err)
+	assert.Nil(t, record)
+}
+
+func TestIdempotencyService_Complete(t *testing.T) {
+	mockRepo := new(MockIdempotencyRepository)
+	service := NewIdempotencyService(mockRepo, testIdempotencyConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	record := &domain.IdempotencyKey{ID: 7}
+
+	mockRepo.On("Complete", ctx, record).Return(nil)
+
+	err := service.Complete(ctx, record, 201, []byte(`{}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 201, record.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrMovieNotFound dikembalikan ketika film tidak ada
+	ErrMovieNotFound = errors.New("movie not found")
+	// ErrMovieHasShowtimes dikembalikan ketika film yang akan dihapus masih punya jadwal tayang mendatang
+	ErrMovieHasShowtimes = repository.ErrMovieHasShowtimes
+)
+
+type MovieService interface {
+	GetAllMovies(ctx context.Context, filter domain.MovieFilter, page, limit int) ([]*domain.Movie, *utils.PaginationMeta, error)
+	GetMovieByID(ctx context.Context, id int) (*domain.Movie, error)
+	CreateMovie(ctx context.Context, req *domain.MovieRequest) (*domain.Movie, error)
+	UpdateMovie(ctx context.Context, id int, req *domain.MovieRequest) (*domain.Movie, error)
+	DeleteMovie(ctx context.Context, id int) error
+}
+
+type movieService struct {
+	movieRepo    repository.MovieRepository
+	showtimeRepo repository.ShowtimeRepository
+	logger       *zap.Logger
+}
+
+func NewMovieService(
+	movieRepo repository.MovieRepository,
+	showtimeRepo repository.ShowtimeRepository,
+	logger *zap.Logger,
+) MovieService {
+	return &movieService{
+		movieRepo:    movieRepo,
+		showtimeRepo: showtimeRepo,
+		logger:       logger,
+	}
+}
+
+func (s *movieService) GetAllMovies(ctx context.Context, filter domain.MovieFilter, page, limit int) ([]*domain.Movie, *utils.PaginationMeta, error) {
+	// Validate pagination parameters
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10 // default limit
+	}
+
+	offset := (page - 1) * limit
+
+	// Get movies
+	movies, total, err := s.movieRepo.GetAll(ctx, filter, limit, offset)
+	if err != nil {
+		s.logger.Error("Failed to get movies", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to get movies: %w", err)
+	}
+
+	// Calculate pagination meta
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	meta := &utils.PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		TotalRows:  total,
+		TotalPages: totalPages,
+	}
+
+	return movies, meta, nil
+}
+
+// GetMovieByID mengambil detail film beserta jadwal tayang yang akan datang di semua cinema
+func (s *movieService) GetMovieByID(ctx context.Context, id int) (*domain.Movie, error) {
+	movie, err := s.movieRepo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to get movie", zap.Int("movie_id", id), zap.Error(err))
+		return nil, ErrMovieNotFound
+	}
+
+	showtimes, err := s.showtimeRepo.GetUpcomingByMovieID(ctx, id, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to get movie showtimes", zap.Int("movie_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+	movie.Showtimes = showtimes
+
+	return movie, nil
+}
+
+// CreateMovie menambah film baru (admin)
+func (s *movieService) CreateMovie(ctx context.Context, req *domain.MovieRequest) (*domain.Movie, error) {
+	movie := movieFromRequest(req)
+
+	if err := s.movieRepo.Create(ctx, movie); err != nil {
+		s.logger.Error("Failed to create movie", zap.Error(err))
+		return nil, fmt.Errorf("failed to create movie: %w", err)
+	}
+
+	s.logger.Info("Movie created", zap.Int("movie_id", movie.ID), zap.String("title", movie.Title))
+	return movie, nil
+}
+
+// UpdateMovie mengubah data film (admin)
+func (s *movieService) UpdateMovie(ctx context.Context, id int, req *domain.MovieRequest) (*domain.Movie, error) {
+	movie := movieFromRequest(req)
+	movie.ID = id
+
+	if err := s.movieRepo.Update(ctx, movie); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrMovieNotFound
+		case errors.Is(err, ErrShowtimeOverlap):
+			// durasi baru membuat showtime mendatang bentrok dengan jadwal berikutnya di studio
+			return nil, err
+		}
+		s.logger.Error("Failed to update movie", zap.Int("movie_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to update movie: %w", err)
+	}
+
+	s.logger.Info("Movie updated", zap.Int("movie_id", id))
+	return movie, nil
+}
+
+// DeleteMovie menghapus film (soft delete), ditolak selama masih ada jadwal tayang mendatang
+func (s *movieService) DeleteMovie(ctx context.Context, id int) error {
+	if err := s.movieRepo.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrMovieNotFound
+		case errors.Is(err, ErrMovieHasShowtimes):
+			return err
+		}
+		s.logger.Error("Failed to delete movie", zap.Int("movie_id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete movie: %w", err)
+	}
+
+	s.logger.Info("Movie deleted", zap.Int("movie_id", id))
+	return nil
+}
+
+func movieFromRequest(req *domain.MovieRequest) *domain.Movie {
+	return &domain.Movie{
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Duration:    req.Duration,
+		Genre:       strings.TrimSpace(req.Genre),
+		PosterURL:   strings.TrimSpace(req.PosterURL),
+		Rating:      strings.TrimSpace(req.Rating),
+	}
+}
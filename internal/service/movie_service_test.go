@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type MockMovieRepository struct {
+	mock.Mock
+}
+
+func (m *MockMovieRepository) GetAll(ctx context.Context, filter domain.MovieFilter, limit, offset int) ([]*domain.Movie, int, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*domain.Movie), args.Int(1), args.Error(2)
+}
+
+func (m *MockMovieRepository) GetByID(ctx context.Context, id int) (*domain.Movie, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Movie), args.Error(1)
+}
+
+func (m *MockMovieRepository) Create(ctx context.Context, movie *domain.Movie) error {
+	args := m.Called(ctx, movie)
+	return args.Error(0)
+}
+
+func (m *MockMovieRepository) Update(ctx context.Context, movie *domain.Movie) error {
+	args := m.Called(ctx, movie)
+	return args.Error(0)
+}
+
+func (m *MockMovieRepository) Delete(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestMovieService_GetAllMovies(t *testing.T) {
+	mockRepo := new(MockMovieRepository)
+	service := NewMovieService(mockRepo, new(MockShowtimeRepository), zap.NewNop())
+
+	filter := domain.MovieFilter{Genre: "Action"}
+	movies := []*domain.Movie{
+		{ID: 1, Title: "Movie 1"},
+		{ID: 2, Title: "Movie 2"},
+	}
+
+	mockRepo.On("GetAll", mock.Anything, filter, 2, 2).Return(movies, 5, nil)
+
+	result, meta, err := service.GetAllMovies(context.Background(), filter, 2, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 5, meta.TotalRows)
+	assert.Equal(t, 3, meta.TotalPages)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMovieService_GetAllMovies_InvalidPagination(t *testing.T) {
+	mockRepo := new(MockMovieRepository)
+	service := NewMovieService(mockRepo, new(MockShowtimeRepository), zap.NewNop())
+
+	mockRepo.On("GetAll", mock.Anything, domain.MovieFilter{}, 10, 0).Return([]*domain.Movie{}, 0, nil)
+
+	_, meta, err := service.GetAllMovies(context.Background(), domain.MovieFilter{}, 0, 500)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, meta.Page)
+	assert.Equal(t, 10, meta.Limit)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMovieService_GetMovieByID_WithShowtimes(t *testing.T) {
+	mockRepo := new(MockMovieRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	service := NewMovieService(mockRepo, mockShowtimeRepo, zap.NewNop())
+
+	showtimes := []*domain.Showtime{
+		{ID: 1, CinemaID: 1, MovieID: 1},
+		{ID: 2, CinemaID: 2, MovieID: 1},
+	}
+
+	mockRepo.On("GetByID", mock.Anything, 1).Return(&domain.Movie{ID: 1, Title: "Movie 1"}, nil)
+	mockShowtimeRepo.On("GetUpcomingByMovieID", mock.Anything, 1, mock.AnythingOfType("time.Time")).Return(showtimes, nil)
+
+	result, err := service.GetMovieByID(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Showtimes, 2)
+	mockRepo.AssertExpectations(t)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestMovieService_GetMovieByID_NotFound(t *testing.T) {
+	mockRepo := new(MockMovieRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	service := NewMovieService(mockRepo, mockShowtimeRepo, zap.NewNop())
+
+	mockRepo.On("GetByID", mock.Anything, 999).Return(nil, errors.New("movie not found"))
+
+	result, err := service.GetMovieByID(context.Background(), 999)
+
+	assert.ErrorIs(t, err, ErrMovieNotFound)
+	assert.Nil(t, result)
+	mockShowtimeRepo.AssertNotCalled(t, "GetUpcomingByMovieID", mock.Anything, mock.Anything, mock.Anything)
+}
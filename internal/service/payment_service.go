@@ -2,63 +2,147 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/gateway"
 	"project-app-bioskop-golang-homework-anas/internal/repository"
 	"project-app-bioskop-golang-homework-anas/internal/utils"
 
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrPaymentDeclined dikembalikan ketika provider menolak pembayaran
+	ErrPaymentDeclined = gateway.ErrDeclined
+	// ErrPaymentTimeout dikembalikan ketika provider tidak merespon
+	ErrPaymentTimeout = gateway.ErrTimeout
+	// ErrInvalidSignature dikembalikan ketika signature webhook tidak valid
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrInvalidWebhookPayload dikembalikan ketika body webhook tidak bisa dibaca
+	ErrInvalidWebhookPayload = errors.New("invalid webhook payload")
+	// ErrPaymentNotFound dikembalikan ketika payment tidak ditemukan
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrDuplicatePayment dikembalikan ketika booking sudah punya payment
+	ErrDuplicatePayment = repository.ErrDuplicatePayment
+	// ErrBookingAlreadyPaid dikembalikan ketika booking sudah dibayar
+	ErrBookingAlreadyPaid = errors.New("booking is already paid")
+	// ErrPaymentInProgress dikembalikan ketika pembayaran booking masih menunggu provider
+	ErrPaymentInProgress = errors.New("payment is still being processed")
+	// ErrBookingNotPending dikembalikan ketika booking sudah tidak pending (mis. expired) saat pembayaran selesai;
+	// dana yang sudah di-capture dikembalikan
+	ErrBookingNotPending = errors.New("booking is no longer pending, payment has been refunded")
+)
+
 type PaymentService interface {
-	ProcessPayment(ctx context.Context, req *domain.PaymentRequest) (*domain.Payment, error)
+	ProcessPayment(ctx context.Context, userID int, req *domain.PaymentRequest) (*domain.Payment, error)
+	HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error
 }
 
 type paymentService struct {
 	paymentRepo       repository.PaymentRepository
 	bookingRepo       repository.BookingRepository
 	paymentMethodRepo repository.PaymentMethodRepository
+	promotionService  PromotionService
+	gateways          *gateway.Registry
+	config            *config.Config
 	logger            *zap.Logger
+	now               func() time.Time // jam sekarang, bisa diganti di test
 }
 
 func NewPaymentService(
 	paymentRepo repository.PaymentRepository,
 	bookingRepo repository.BookingRepository,
 	paymentMethodRepo repository.PaymentMethodRepository,
+	promotionService PromotionService,
+	gateways *gateway.Registry,
+	config *config.Config,
 	logger *zap.Logger,
 ) PaymentService {
 	return &paymentService{
 		paymentRepo:       paymentRepo,
 		bookingRepo:       bookingRepo,
 		paymentMethodRepo: paymentMethodRepo,
+		promotionService:  promotionService,
+		gateways:          gateways,
+		config:            config,
 		logger:            logger,
+		now:               time.Now,
 	}
 }
 
-func (s *paymentService) ProcessPayment(ctx context.Context, req *domain.PaymentRequest) (*domain.Payment, error) {
+func (s *paymentService) ProcessPayment(ctx context.Context, userID int, req *domain.PaymentRequest) (*domain.Payment, error) {
 	// Validate booking exists
 	booking, err := s.bookingRepo.GetByID(ctx, req.BookingID)
 	if err != nil {
 		s.logger.Error("Booking not found", zap.Error(err))
-		return nil, fmt.Errorf("booking not found")
+		return nil, ErrBookingNotFound
+	}
+
+	// Only the booking owner may pay for it
+	if booking.UserID != userID {
+		s.logger.Warn("Payment attempt for booking owned by another user",
+			zap.Int("booking_id", booking.ID),
+			zap.Int("user_id", userID),
+		)
+		return nil, ErrBookingNotOwned
 	}
 
 	// Check if booking is already confirmed or cancelled
 	if booking.Status == "confirmed" {
-		return nil, fmt.Errorf("booking is already paid")
+		return nil, ErrBookingAlreadyPaid
 	}
 
 	if booking.Status == "cancelled" {
-		return nil, fmt.Errorf("booking is cancelled")
+		return nil, ErrBookingCancelled
+	}
+
+	if booking.Status == "expired" {
+		return nil, ErrBookingExpired
+	}
+
+	if booking.Payment != nil && booking.Payment.Status == "pending" {
+		return nil, ErrPaymentInProgress
+	}
+
+	// Payment window passed but the expiry job has not run yet
+	if now := s.now(); booking.ExpiresAt != nil && now.After(*booking.ExpiresAt) {
+		booking.Status = "expired"
+		if _, err := s.bookingRepo.UpdateStatusIfPending(ctx, booking, now); err != nil {
+			s.logger.Error("Failed to expire booking", zap.Int("booking_id", booking.ID), zap.Error(err))
+		}
+		return nil, ErrBookingExpired
 	}
 
 	// Validate payment method
 	paymentMethod, err := s.paymentMethodRepo.GetByCode(ctx, req.PaymentMethod)
 	if err != nil {
 		s.logger.Error("Invalid payment method", zap.Error(err))
-		return nil, fmt.Errorf("invalid payment method")
+		return nil, ErrInvalidPaymentMethod
+	}
+
+	// Promo can be applied on payment, an existing promo must allow the chosen method
+	promoApplied := req.PromoCode != "" && booking.PromoCode == nil
+	if req.PromoCode != "" {
+		err = s.promotionService.ApplyToBooking(ctx, booking, req.PromoCode, paymentMethod.Code)
+	} else {
+		err = s.promotionService.CheckPaymentMethod(ctx, booking, paymentMethod.Code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A promo applied by this request is taken off again when the payment does not go through,
+	// otherwise its quota stays used by a booking that was never paid
+	removePromo := func() {
+		if promoApplied {
+			s.promotionService.RemoveFromBooking(ctx, booking)
+		}
 	}
 
 	// Validate payment details
@@ -68,39 +152,108 @@ func (s *paymentService) ProcessPayment(ctx context.Context, req *domain.Payment
 
 	// Add metadata if not provided
 	if req.PaymentDetails["timestamp"] == nil {
-		req.PaymentDetails["timestamp"] = time.Now().Format(time.RFC3339)
+		req.PaymentDetails["timestamp"] = s.now().Format(time.RFC3339)
 	}
 	if req.PaymentDetails["payment_method"] == nil {
 		req.PaymentDetails["payment_method"] = paymentMethod.Name
 	}
 
+	// Authorize with the provider of this payment method
+	gw := s.gateways.Get(paymentMethod.Code)
+	result, err := gw.Authorize(ctx, &gateway.AuthorizeRequest{
+		BookingID:     booking.ID,
+		BookingCode:   booking.BookingCode,
+		Amount:        booking.TotalPrice,
+		PaymentMethod: paymentMethod.Code,
+		Details:       req.PaymentDetails,
+	})
+	if err != nil {
+		removePromo()
+		return nil, s.gatewayError(booking.ID, paymentMethod.Code, err)
+	}
+
 	// Create payment record
 	payment := &domain.Payment{
-		BookingID:       req.BookingID,
-		PaymentMethodID: paymentMethod.ID,
-		Amount:          booking.TotalPrice,
-		Status:          "success",
-		PaymentDetails:  req.PaymentDetails,
+		BookingID:         req.BookingID,
+		PaymentMethodID:   paymentMethod.ID,
+		Amount:            booking.TotalPrice,
+		Status:            "pending",
+		PaymentDetails:    req.PaymentDetails,
+		ProviderReference: &result.Reference,
+	}
+
+	// Async provider: booking stays pending until the provider reports the final status
+	if result.Status == gateway.StatusPending {
+		if err := s.paymentRepo.Create(ctx, payment); err != nil {
+			s.logger.Error("Failed to create payment", zap.Error(err))
+			// Another payment of this booking was created with the discounted total, keep the promo
+			if errors.Is(err, ErrDuplicatePayment) {
+				return nil, err
+			}
+			removePromo()
+			return nil, fmt.Errorf("failed to process payment: %w", err)
+		}
+
+		s.logger.Info("Payment awaiting provider confirmation",
+			zap.Int("payment_id", payment.ID),
+			zap.Int("booking_id", req.BookingID),
+			zap.String("provider_reference", result.Reference),
+		)
+
+		return payment, nil
+	}
+
+	if _, err := gw.Capture(ctx, result.Reference, booking.TotalPrice); err != nil {
+		removePromo()
+		return nil, s.gatewayError(booking.ID, paymentMethod.Code, err)
 	}
 
-	now := time.Now()
+	now := s.now()
+	payment.Status = "success"
 	payment.PaidAt = &now
 
 	if err := s.paymentRepo.Create(ctx, payment); err != nil {
 		s.logger.Error("Failed to create payment", zap.Error(err))
+
+		// Funds were already captured, give them back
+		if _, refundErr := gw.Refund(ctx, result.Reference, payment.Amount); refundErr != nil {
+			s.logger.Error("Failed to refund captured payment",
+				zap.String("provider_reference", result.Reference),
+				zap.Error(refundErr),
+			)
+		}
+
+		if errors.Is(err, ErrDuplicatePayment) {
+			return nil, err
+		}
+		removePromo()
 		return nil, fmt.Errorf("failed to process payment: %w", err)
 	}
 
-	// Update booking status to confirmed
+	// Confirm only if the booking is still pending: the expiry job may have released its seats meanwhile
 	booking.Status = "confirmed"
-	if err := s.bookingRepo.Update(ctx, booking); err != nil {
-		s.logger.Error("Failed to update booking status", zap.Error(err))
+	confirmed, err := s.bookingRepo.UpdateStatusIfPending(ctx, booking, now)
+	if err != nil || !confirmed {
+		s.logger.Warn("Booking could not be confirmed after capture, refunding",
+			zap.Int("booking_id", booking.ID),
+			zap.Int("payment_id", payment.ID),
+			zap.Error(err),
+		)
+
+		if refundErr := s.refundCapturedPayment(ctx, gw, payment); refundErr != nil {
+			return nil, refundErr
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to confirm booking: %w", err)
+		}
+		return nil, ErrBookingNotPending
 	}
 
 	s.logger.Info("Payment processed successfully",
 		zap.Int("payment_id", payment.ID),
 		zap.Int("booking_id", req.BookingID),
-		zap.Float64("amount", payment.Amount),
+		zap.Stringer("amount", payment.Amount),
 		zap.Any("payment_details", payment.PaymentDetails),
 	)
 
@@ -118,3 +271,213 @@ func (s *paymentService) ProcessPayment(ctx context.Context, req *domain.Payment
 
 	return fullPayment, nil
 }
+
+// HandleWebhook memproses notifikasi status pembayaran async dari provider.
+// Webhook yang dikirim ulang melanjutkan update booking yang belum selesai, jadi aman di-retry.
+func (s *paymentService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) error {
+	// Providers without a configured secret are rejected (VerifyHMAC fails on an empty secret)
+	secret := s.config.Payment.WebhookSecrets[strings.ToUpper(provider)]
+	if !utils.VerifyHMAC(secret, payload, signature) {
+		s.logger.Warn("Invalid webhook signature", zap.String("provider", provider))
+		return ErrInvalidSignature
+	}
+
+	var event domain.PaymentWebhook
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookPayload, err)
+	}
+	if event.Reference == "" {
+		return fmt.Errorf("%w: reference is required", ErrInvalidWebhookPayload)
+	}
+	if event.Status != "success" && event.Status != "failed" {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidWebhookPayload, event.Status)
+	}
+
+	payment, err := s.paymentRepo.GetByProviderReference(ctx, event.Reference)
+	if err != nil {
+		s.logger.Warn("Webhook for unknown payment", zap.String("provider", provider), zap.String("reference", event.Reference))
+		return ErrPaymentNotFound
+	}
+
+	// Provider can only report on its own transactions
+	if payment.PaymentMethod == nil || !strings.EqualFold(payment.PaymentMethod.Code, provider) {
+		s.logger.Warn("Webhook provider does not match payment method",
+			zap.String("provider", provider),
+			zap.String("reference", event.Reference),
+		)
+		return ErrPaymentNotFound
+	}
+
+	switch payment.Status {
+	case "pending":
+		settled, err := s.settlePayment(ctx, payment, event.Status)
+		if err != nil {
+			return err
+		}
+		if !settled {
+			// Concurrent delivery of the same webhook already won
+			return nil
+		}
+	case event.Status:
+		// Redelivery: the booking step may not have finished the first time
+		s.logger.Info("Webhook redelivered, finishing booking update",
+			zap.Int("payment_id", payment.ID),
+			zap.String("payment_status", payment.Status),
+		)
+	default:
+		s.logger.Info("Webhook already processed",
+			zap.Int("payment_id", payment.ID),
+			zap.String("payment_status", payment.Status),
+			zap.String("webhook_status", event.Status),
+		)
+		return nil
+	}
+
+	booking, err := s.bookingRepo.GetByID(ctx, payment.BookingID)
+	if err != nil {
+		s.logger.Error("Failed to get booking for payment", zap.Int("booking_id", payment.BookingID), zap.Error(err))
+		return fmt.Errorf("failed to get booking: %w", err)
+	}
+
+	s.logger.Info("Payment status received from provider",
+		zap.Int("payment_id", payment.ID),
+		zap.Int("booking_id", booking.ID),
+		zap.String("provider", provider),
+		zap.String("status", payment.Status),
+	)
+
+	if event.Status == "failed" {
+		return s.releaseBooking(ctx, booking)
+	}
+
+	return s.confirmBooking(ctx, booking, payment)
+}
+
+// settlePayment mencatat status akhir pembayaran async. Pembayaran yang berhasil di-capture
+// dulu di provider supaya bisa di-refund nantinya.
+func (s *paymentService) settlePayment(ctx context.Context, payment *domain.Payment, status string) (bool, error) {
+	if status == "success" {
+		gw := s.gateways.Get(payment.PaymentMethod.Code)
+		if _, err := gw.Capture(ctx, *payment.ProviderReference, payment.Amount); err != nil {
+			s.logger.Error("Failed to capture payment", zap.Int("payment_id", payment.ID), zap.Error(err))
+			return false, fmt.Errorf("failed to capture payment: %w", err)
+		}
+
+		now := s.now()
+		payment.PaidAt = &now
+	}
+	payment.Status = status
+
+	updated, err := s.paymentRepo.UpdateStatusIfPending(ctx, payment)
+	if err != nil {
+		s.logger.Error("Failed to update payment status", zap.Int("payment_id", payment.ID), zap.Error(err))
+		return false, fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return updated, nil
+}
+
+// confirmBooking mengkonfirmasi booking setelah pembayaran async berhasil.
+// Jika booking sudah tidak pending (mis. expired), dana dikembalikan penuh.
+func (s *paymentService) confirmBooking(ctx context.Context, booking *domain.Booking, payment *domain.Payment) error {
+	gw := s.gateways.Get(payment.PaymentMethod.Code)
+
+	if booking.Status == "confirmed" {
+		return nil
+	}
+
+	if booking.Status != "pending" {
+		s.logger.Warn("Payment succeeded for inactive booking, refunding",
+			zap.Int("booking_id", booking.ID),
+			zap.String("booking_status", booking.Status),
+		)
+		return s.refundCapturedPayment(ctx, gw, payment)
+	}
+
+	booking.Status = "confirmed"
+	confirmed, err := s.bookingRepo.UpdateStatusIfPending(ctx, booking, s.now())
+	if err != nil {
+		s.logger.Error("Failed to update booking status", zap.Int("booking_id", booking.ID), zap.Error(err))
+		return fmt.Errorf("failed to confirm booking: %w", err)
+	}
+	if !confirmed {
+		// A concurrent redelivery may have confirmed it already
+		current, err := s.bookingRepo.GetByID(ctx, booking.ID)
+		if err != nil {
+			s.logger.Error("Failed to get booking", zap.Int("booking_id", booking.ID), zap.Error(err))
+			return fmt.Errorf("failed to get booking: %w", err)
+		}
+		if current.Status == "confirmed" {
+			return nil
+		}
+
+		// Expired (seats released) between reading the booking and confirming it
+		s.logger.Warn("Booking is no longer pending, refunding", zap.Int("booking_id", booking.ID))
+		return s.refundCapturedPayment(ctx, gw, payment)
+	}
+
+	// GOROUTINE: Async logging to file
+	utils.LogPaymentAsync(s.logger, booking.ID, payment.Amount, payment.PaymentMethod.Name)
+	utils.LogBookingAsync(s.logger, booking.UserID, booking.BookingCode, "confirmed")
+
+	return nil
+}
+
+// releaseBooking membatalkan booking setelah pembayaran async gagal sehingga kursinya tersedia lagi
+func (s *paymentService) releaseBooking(ctx context.Context, booking *domain.Booking) error {
+	if booking.Status != "pending" {
+		return nil
+	}
+
+	booking.Status = "cancelled"
+	released, err := s.bookingRepo.UpdateStatusIfPending(ctx, booking, s.now())
+	if err != nil {
+		s.logger.Error("Failed to release booking", zap.Int("booking_id", booking.ID), zap.Error(err))
+		return fmt.Errorf("failed to release booking: %w", err)
+	}
+	if !released {
+		return nil
+	}
+
+	// GOROUTINE: Async logging to file
+	utils.LogBookingAsync(s.logger, booking.UserID, booking.BookingCode, "cancelled")
+
+	return nil
+}
+
+// refundCapturedPayment mengembalikan penuh dana yang sudah di-capture dan mencatat payment sebagai refunded
+func (s *paymentService) refundCapturedPayment(ctx context.Context, gw gateway.PaymentGateway, payment *domain.Payment) error {
+	if _, err := gw.Refund(ctx, *payment.ProviderReference, payment.Amount); err != nil {
+		s.logger.Error("Failed to refund payment", zap.Int("payment_id", payment.ID), zap.Error(err))
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	now := s.now()
+	payment.Status = "refunded"
+	payment.RefundAmount = &payment.Amount
+	payment.RefundedAt = &now
+	if err := s.paymentRepo.Update(ctx, payment); err != nil {
+		s.logger.Error("Failed to update payment", zap.Int("payment_id", payment.ID), zap.Error(err))
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	return nil
+}
+
+// gatewayError mencatat error dari provider dan menerjemahkannya ke error service
+func (s *paymentService) gatewayError(bookingID int, methodCode string, err error) error {
+	s.logger.Warn("Payment gateway rejected transaction",
+		zap.Int("booking_id", bookingID),
+		zap.String("payment_method", methodCode),
+		zap.Error(err),
+	)
+
+	switch {
+	case errors.Is(err, gateway.ErrDeclined):
+		return ErrPaymentDeclined
+	case errors.Is(err, gateway.ErrTimeout):
+		return ErrPaymentTimeout
+	default:
+		return fmt.Errorf("failed to process payment: %w", err)
+	}
+}
@@ -6,10 +6,14 @@ import (
 	"testing"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/gateway"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
 
@@ -30,18 +34,52 @@ func (m *MockPaymentRepository) GetByBookingID(ctx context.Context, bookingID in
 	return args.Get(0).(*domain.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetByProviderReference(ctx context.Context, reference string) (*domain.Payment, error) {
+	args := m.Called(ctx, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Payment), args.Error(1)
+}
+
 func (m *MockPaymentRepository) Update(ctx context.Context, payment *domain.Payment) error {
 	args := m.Called(ctx, payment)
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) UpdateStatusIfPending(ctx context.Context, payment *domain.Payment) (bool, error) {
+	args := m.Called(ctx, payment)
+	return args.Bool(0), args.Error(1)
+}
+
+// testGateways membuat registry dengan simulator yang selalu sukses
+func testGateways() *gateway.Registry {
+	return simulatorGateways(gateway.ModeSucceed)
+}
+
+const testWebhookSecret = "webhook-secret"
+
+func testPaymentConfig() *config.Config {
+	return &config.Config{
+		Payment: config.PaymentConfig{WebhookSecrets: map[string]string{"GOPAY": testWebhookSecret, "OVO": "ovo-webhook-secret"}},
+	}
+}
+
+func simulatorGateways(mode string) *gateway.Registry {
+	sim, err := gateway.NewSimulator("test", mode)
+	if err != nil {
+		panic(err)
+	}
+	return gateway.NewRegistry(sim)
+}
+
 func TestPaymentService_ProcessPayment_Success(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepository)
 	mockBookingRepo := new(MockBookingRepository)
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, logger)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
 
 	ctx := context.Background()
 	now := time.Now()
@@ -50,7 +88,7 @@ func TestPaymentService_ProcessPayment_Success(t *testing.T) {
 		ID:          1,
 		UserID:      1,
 		Status:      "pending",
-		TotalPrice:  50000,
+		TotalPrice:  domain.NewMoney(50000),
 		BookingCode: "BK123",
 	}
 
@@ -64,7 +102,7 @@ func TestPaymentService_ProcessPayment_Success(t *testing.T) {
 		ID:              1,
 		BookingID:       1,
 		PaymentMethodID: 1,
-		Amount:          50000,
+		Amount:          domain.NewMoney(50000),
 		Status:          "success",
 		PaidAt:          &now,
 	}
@@ -81,10 +119,10 @@ func TestPaymentService_ProcessPayment_Success(t *testing.T) {
 		p := args.Get(1).(*domain.Payment)
 		p.ID = 1
 	})
-	mockBookingRepo.On("Update", ctx, mock.AnythingOfType("*domain.Booking")).Return(nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, mock.AnythingOfType("*domain.Booking"), mock.Anything).Return(true, nil)
 	mockPaymentRepo.On("GetByBookingID", ctx, 1).Return(payment, nil)
 
-	result, err := service.ProcessPayment(ctx, req)
+	result, err := service.ProcessPayment(ctx, 1, req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -95,13 +133,40 @@ func TestPaymentService_ProcessPayment_Success(t *testing.T) {
 	mockPaymentMethodRepo.AssertExpectations(t)
 }
 
+func TestPaymentService_ProcessPayment_ExpiredDuringCaptureRefunds(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000), BookingCode: "BK123"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(&domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}, nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	// The expiry job released the seats after the booking was read
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(false, nil)
+	mockPaymentRepo.On("Update", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Status == "refunded" && *p.RefundAmount == domain.NewMoney(50000)
+	})).Return(nil)
+
+	result, err := service.ProcessPayment(ctx, 1, &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD"})
+
+	assert.ErrorIs(t, err, ErrBookingNotPending)
+	assert.Nil(t, result)
+	mockPaymentRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertNotCalled(t, "GetByBookingID", mock.Anything, mock.Anything)
+}
+
 func TestPaymentService_ProcessPayment_BookingNotFound(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepository)
 	mockBookingRepo := new(MockBookingRepository)
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, logger)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
 
 	ctx := context.Background()
 	req := &domain.PaymentRequest{
@@ -111,7 +176,7 @@ func TestPaymentService_ProcessPayment_BookingNotFound(t *testing.T) {
 
 	mockBookingRepo.On("GetByID", ctx, 999).Return(nil, errors.New("not found"))
 
-	result, err := service.ProcessPayment(ctx, req)
+	result, err := service.ProcessPayment(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -125,12 +190,13 @@ func TestPaymentService_ProcessPayment_AlreadyPaid(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, logger)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
 
 	ctx := context.Background()
 
 	booking := &domain.Booking{
 		ID:     1,
+		UserID: 1,
 		Status: "confirmed",
 	}
 
@@ -141,7 +207,7 @@ func TestPaymentService_ProcessPayment_AlreadyPaid(t *testing.T) {
 
 	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
 
-	result, err := service.ProcessPayment(ctx, req)
+	result, err := service.ProcessPayment(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -155,12 +221,13 @@ func TestPaymentService_ProcessPayment_BookingCancelled(t *testing.T) {
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, logger)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
 
 	ctx := context.Background()
 
 	booking := &domain.Booking{
 		ID:     1,
+		UserID: 1,
 		Status: "cancelled",
 	}
 
@@ -171,7 +238,7 @@ func TestPaymentService_ProcessPayment_BookingCancelled(t *testing.T) {
 
 	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
 
-	result, err := service.ProcessPayment(ctx, req)
+	result, err := service.ProcessPayment(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -179,18 +246,87 @@ func TestPaymentService_ProcessPayment_BookingCancelled(t *testing.T) {
 	mockBookingRepo.AssertExpectations(t)
 }
 
+func TestPaymentService_ProcessPayment_BookingExpired(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{
+		ID:     1,
+		UserID: 1,
+		Status: "expired",
+	}
+
+	req := &domain.PaymentRequest{
+		BookingID:     1,
+		PaymentMethod: "CREDIT_CARD",
+	}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "expired")
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_ProcessPayment_PaymentWindowPassed(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	expiresAt := time.Now().Add(-time.Minute)
+
+	booking := &domain.Booking{
+		ID:        1,
+		UserID:    1,
+		Status:    "pending",
+		ExpiresAt: &expiresAt,
+	}
+
+	req := &domain.PaymentRequest{
+		BookingID:     1,
+		PaymentMethod: "CREDIT_CARD",
+	}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, mock.MatchedBy(func(b *domain.Booking) bool {
+		return b.ID == 1 && b.Status == "expired"
+	}), mock.Anything).Return(true, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "expired")
+	mockBookingRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestPaymentService_ProcessPayment_InvalidPaymentMethod(t *testing.T) {
 	mockPaymentRepo := new(MockPaymentRepository)
 	mockBookingRepo := new(MockBookingRepository)
 	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
 	logger := zap.NewNop()
 
-	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, logger)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
 
 	ctx := context.Background()
 
 	booking := &domain.Booking{
 		ID:     1,
+		UserID: 1,
 		Status: "pending",
 	}
 
@@ -202,7 +338,7 @@ func TestPaymentService_ProcessPayment_InvalidPaymentMethod(t *testing.T) {
 	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
 	mockPaymentMethodRepo.On("GetByCode", ctx, "INVALID").Return(nil, errors.New("not found"))
 
-	result, err := service.ProcessPayment(ctx, req)
+	result, err := service.ProcessPayment(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -210,3 +346,619 @@ func TestPaymentService_ProcessPayment_InvalidPaymentMethod(t *testing.T) {
 	mockBookingRepo.AssertExpectations(t)
 	mockPaymentMethodRepo.AssertExpectations(t)
 }
+
+func TestPaymentService_ProcessPayment_NotOwner(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{
+		ID:         1,
+		UserID:     2,
+		Status:     "pending",
+		TotalPrice: domain.NewMoney(50000),
+	}
+
+	req := &domain.PaymentRequest{
+		BookingID:     1,
+		PaymentMethod: "CREDIT_CARD",
+	}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrBookingNotOwned)
+	assert.Nil(t, result)
+	mockBookingRepo.AssertExpectations(t)
+	mockPaymentMethodRepo.AssertNotCalled(t, "GetByCode", mock.Anything, mock.Anything)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_Declined(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), simulatorGateways(gateway.ModeDecline), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000)}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrPaymentDeclined)
+	assert.Nil(t, result)
+	assert.Equal(t, "pending", booking.Status)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockBookingRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_PromoCode(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, promotions, testGateways(), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000), Showtime: &domain.Showtime{ID: 7}}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD", PromoCode: "HEMAT20"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+	mockPromotionRepo.On("ApplyToBooking", ctx, booking, mock.AnythingOfType("*domain.PromotionRedemption")).Return(nil).Run(func(args mock.Arguments) {
+		b := args.Get(1).(*domain.Booking)
+		r := args.Get(2).(*domain.PromotionRedemption)
+		b.TotalPrice -= r.DiscountAmount
+		b.DiscountAmount = r.DiscountAmount
+	})
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(true, nil)
+	mockPaymentRepo.On("GetByBookingID", ctx, 1).Return(nil, errors.New("not found"))
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	require.NoError(t, err)
+	// The discounted total is what gets charged
+	assert.Equal(t, domain.NewMoney(40000), result.Amount)
+	assert.Equal(t, "confirmed", booking.Status)
+	mockPromotionRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_ProcessPayment_PromoRemovedWhenDeclined(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, promotions, simulatorGateways(gateway.ModeDecline), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000), Showtime: &domain.Showtime{ID: 7}}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD", PromoCode: "HEMAT20"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+	mockPromotionRepo.On("ApplyToBooking", ctx, booking, mock.AnythingOfType("*domain.PromotionRedemption")).Return(nil).Run(func(args mock.Arguments) {
+		b := args.Get(1).(*domain.Booking)
+		r := args.Get(2).(*domain.PromotionRedemption)
+		code := r.Promotion.Code
+		b.TotalPrice -= r.DiscountAmount
+		b.DiscountAmount = r.DiscountAmount
+		b.PromoCode = &code
+	})
+	mockPromotionRepo.On("RemoveFromBooking", ctx, booking).Return(nil).Run(func(args mock.Arguments) {
+		b := args.Get(1).(*domain.Booking)
+		b.TotalPrice += b.DiscountAmount
+		b.DiscountAmount = 0
+		b.PromoCode = nil
+	})
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrPaymentDeclined)
+	assert.Nil(t, result)
+	assert.Equal(t, domain.NewMoney(50000), booking.TotalPrice)
+	assert.Nil(t, booking.PromoCode)
+	mockPromotionRepo.AssertExpectations(t)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_BookingPromoKeptWhenDeclined(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, promotions, simulatorGateways(gateway.ModeTimeout), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	// Promo was applied when the booking was made, a failed payment does not take it off
+	code := "HEMAT20"
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(40000), DiscountAmount: domain.NewMoney(10000), PromoCode: &code}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+
+	result, err := service.ProcessPayment(ctx, 1, &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD", PromoCode: "HEMAT20"})
+
+	assert.ErrorIs(t, err, ErrPaymentTimeout)
+	assert.Nil(t, result)
+	assert.Equal(t, "HEMAT20", *booking.PromoCode)
+	mockPromotionRepo.AssertNotCalled(t, "RemoveFromBooking", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_PromoPaymentMethodMismatch(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	mockPromotionRepo := new(MockPromotionRepository)
+	promotions := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, promotions, testGateways(), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+
+	// Booking was made with a promo that is only valid for GOPAY
+	code := "HEMAT20"
+	promo := testPromotion()
+	promo.PaymentMethods = []string{"GOPAY"}
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(40000), DiscountAmount: domain.NewMoney(10000), PromoCode: &code}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "CREDIT_CARD", Name: "Credit Card"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "CREDIT_CARD").Return(paymentMethod, nil)
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(promo, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, &domain.PaymentRequest{BookingID: 1, PaymentMethod: "CREDIT_CARD"})
+
+	assert.ErrorIs(t, err, ErrPromotionNotApplicable)
+	assert.Nil(t, result)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_Timeout(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), simulatorGateways(gateway.ModeTimeout), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000)}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "GOPAY", Name: "GoPay"}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "GOPAY"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "GOPAY").Return(paymentMethod, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.ErrorIs(t, err, ErrPaymentTimeout)
+	assert.Nil(t, result)
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_Async(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), simulatorGateways(gateway.ModeAsync), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", TotalPrice: domain.NewMoney(50000)}
+	paymentMethod := &domain.PaymentMethod{ID: 1, Code: "BANK_TRANSFER", Name: "Bank Transfer"}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "BANK_TRANSFER"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentMethodRepo.On("GetByCode", ctx, "BANK_TRANSFER").Return(paymentMethod, nil)
+	mockPaymentRepo.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	assert.Nil(t, result.PaidAt)
+	assert.NotNil(t, result.ProviderReference)
+	assert.Equal(t, "pending", booking.Status)
+	mockPaymentRepo.AssertExpectations(t)
+	mockBookingRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_ProcessPayment_AlreadyProcessing(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	mockPaymentMethodRepo := new(MockPaymentMethodRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, mockPaymentMethodRepo, testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+
+	booking := &domain.Booking{
+		ID:      1,
+		UserID:  1,
+		Status:  "pending",
+		Payment: &domain.Payment{ID: 5, Status: "pending"},
+	}
+	req := &domain.PaymentRequest{BookingID: 1, PaymentMethod: "BANK_TRANSFER"}
+
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+
+	result, err := service.ProcessPayment(ctx, 1, req)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "still being processed")
+	mockPaymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// pendingGopayPayment membuat payment async yang menunggu webhook
+func pendingGopayPayment() *domain.Payment {
+	reference := "SIM-GOPAY-1-1"
+	return &domain.Payment{
+		ID:                10,
+		BookingID:         1,
+		Amount:            domain.NewMoney(50000),
+		Status:            "pending",
+		ProviderReference: &reference,
+		PaymentMethod:     &domain.PaymentMethod{ID: 3, Code: "GOPAY", Name: "GoPay"},
+	}
+}
+
+func signedWebhook(status string) ([]byte, string) {
+	payload := []byte(`{"reference": "SIM-GOPAY-1-1", "status": "` + status + `"}`)
+	return payload, utils.SignHMAC(testWebhookSecret, payload)
+}
+
+func TestPaymentService_HandleWebhook_Success(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", BookingCode: "BK123"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Status == "success" && p.PaidAt != nil
+	})).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(true, nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "confirmed", booking.Status)
+	mockPaymentRepo.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleWebhook_FailedReleasesBooking(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", BookingCode: "BK123"}
+	payload, signature := signedWebhook("failed")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, mock.AnythingOfType("*domain.Payment")).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(true, nil)
+
+	err := service.HandleWebhook(ctx, "GOPAY", payload, signature)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", booking.Status)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleWebhook_AlreadyProcessed(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	payment := pendingGopayPayment()
+	payment.Status = "success"
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "confirmed", BookingCode: "BK123"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(payment, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	mockPaymentRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything)
+	mockPaymentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockBookingRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_RedeliveryFinishesConfirm(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	// Payment was settled but confirming the booking failed on the first delivery
+	payment := pendingGopayPayment()
+	payment.Status = "success"
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", BookingCode: "BK123"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(payment, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(true, nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "confirmed", booking.Status)
+	mockPaymentRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleWebhook_RefundedPaymentIgnored(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	payment := pendingGopayPayment()
+	payment.Status = "refunded"
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(payment, nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	mockBookingRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_ConcurrentDelivery(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, mock.AnythingOfType("*domain.Payment")).Return(false, nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	mockBookingRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_InvalidSignature(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, new(MockBookingRepository), new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	payload, _ := signedWebhook("success")
+
+	err := service.HandleWebhook(context.Background(), "gopay", payload, utils.SignHMAC("wrong-secret", payload))
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	mockPaymentRepo.AssertNotCalled(t, "GetByProviderReference", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_OtherProviderSecret(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, new(MockBookingRepository), new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	payload, _ := signedWebhook("success")
+
+	err := service.HandleWebhook(context.Background(), "gopay", payload, utils.SignHMAC("ovo-webhook-secret", payload))
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	mockPaymentRepo.AssertNotCalled(t, "GetByProviderReference", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_ProviderWithoutSecret(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, new(MockBookingRepository), new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	payload, _ := signedWebhook("success")
+
+	err := service.HandleWebhook(context.Background(), "bank_transfer", payload, utils.SignHMAC("", payload))
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	mockPaymentRepo.AssertNotCalled(t, "GetByProviderReference", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_InvalidPayload(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, new(MockBookingRepository), new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	payload := []byte(`{"reference": "SIM-GOPAY-1-1", "status": "settled"}`)
+
+	err := service.HandleWebhook(context.Background(), "gopay", payload, utils.SignHMAC(testWebhookSecret, payload))
+
+	assert.ErrorIs(t, err, ErrInvalidWebhookPayload)
+	mockPaymentRepo.AssertNotCalled(t, "GetByProviderReference", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_ProviderMismatch(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, new(MockBookingRepository), new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	// OVO signs a webhook for a GOPAY transaction with its own secret
+	payload, _ := signedWebhook("success")
+	signature := utils.SignHMAC("ovo-webhook-secret", payload)
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+
+	err := service.HandleWebhook(ctx, "ovo", payload, signature)
+
+	assert.ErrorIs(t, err, ErrPaymentNotFound)
+	mockPaymentRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_SuccessAfterExpiryRefunds(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+	logger := zap.NewNop()
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), logger)
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "expired"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, mock.AnythingOfType("*domain.Payment")).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentRepo.On("Update", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Status == "refunded" && *p.RefundAmount == domain.NewMoney(50000)
+	})).Return(nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "expired", booking.Status)
+	mockPaymentRepo.AssertExpectations(t)
+	mockBookingRepo.AssertNotCalled(t, "UpdateStatusIfPending", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleWebhook_ExpiredBeforeConfirmRefunds(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", BookingCode: "BK123"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(pendingGopayPayment(), nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, mock.AnythingOfType("*domain.Payment")).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil).Once()
+	// Expired between reading the booking and confirming it
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(false, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(&domain.Booking{ID: 1, UserID: 1, Status: "expired"}, nil).Once()
+	mockPaymentRepo.On("Update", ctx, mock.MatchedBy(func(p *domain.Payment) bool {
+		return p.Status == "refunded"
+	})).Return(nil)
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	mockPaymentRepo.AssertExpectations(t)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleWebhook_ConfirmRaceWithRedelivery(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), testGateways(), testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	payment := pendingGopayPayment()
+	payment.Status = "success"
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "pending", BookingCode: "BK123"}
+	payload, signature := signedWebhook("success")
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, "SIM-GOPAY-1-1").Return(payment, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil).Once()
+	// Another delivery confirmed the booking first
+	mockBookingRepo.On("UpdateStatusIfPending", ctx, booking, mock.Anything).Return(false, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(&domain.Booking{ID: 1, UserID: 1, Status: "confirmed"}, nil).Once()
+
+	err := service.HandleWebhook(ctx, "gopay", payload, signature)
+
+	assert.NoError(t, err)
+	mockPaymentRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleWebhook_SettledAsyncPaymentIsRefundable(t *testing.T) {
+	mockPaymentRepo := new(MockPaymentRepository)
+	mockBookingRepo := new(MockBookingRepository)
+
+	gateways := simulatorGateways(gateway.ModeAsync)
+	service := NewPaymentService(mockPaymentRepo, mockBookingRepo, new(MockPaymentMethodRepository), testPromotions(), gateways, testPaymentConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	auth, err := gateways.Get("GOPAY").Authorize(ctx, &gateway.AuthorizeRequest{BookingID: 1, Amount: domain.NewMoney(50000)})
+	require.NoError(t, err)
+
+	payment := pendingGopayPayment()
+	payment.ProviderReference = &auth.Reference
+	booking := &domain.Booking{ID: 1, UserID: 1, Status: "expired"}
+	payload := []byte(`{"reference": "` + auth.Reference + `", "status": "success"}`)
+
+	mockPaymentRepo.On("GetByProviderReference", ctx, auth.Reference).Return(payment, nil)
+	mockPaymentRepo.On("UpdateStatusIfPending", ctx, payment).Return(true, nil)
+	mockBookingRepo.On("GetByID", ctx, 1).Return(booking, nil)
+	mockPaymentRepo.On("Update", ctx, payment).Return(nil)
+
+	err = service.HandleWebhook(ctx, "gopay", payload, utils.SignHMAC(testWebhookSecret, payload))
+
+	require.NoError(t, err)
+	assert.Equal(t, "refunded", payment.Status)
+	status, err := gateways.Get("GOPAY").Status(ctx, auth.Reference)
+	require.NoError(t, err)
+	assert.Equal(t, gateway.StatusRefunded, status.Status)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ErrSeatPricingFailed dikembalikan ketika harga kursi tidak bisa dihitung
+var ErrSeatPricingFailed = errors.New("failed to calculate seat prices")
+
+type PricingService interface {
+	PriceSeats(ctx context.Context, showtime *domain.Showtime, seats []*domain.Seat) (map[int]*domain.SeatPrice, error)
+}
+
+type pricingService struct {
+	priceRuleRepo repository.PriceRuleRepository
+	logger        *zap.Logger
+}
+
+func NewPricingService(priceRuleRepo repository.PriceRuleRepository, logger *zap.Logger) PricingService {
+	return &pricingService{
+		priceRuleRepo: priceRuleRepo,
+		logger:        logger,
+	}
+}
+
+// PriceSeats menghitung harga setiap kursi (key: seat ID) untuk showtime
+func (s *pricingService) PriceSeats(ctx context.Context, showtime *domain.Showtime, seats []*domain.Seat) (map[int]*domain.SeatPrice, error) {
+	rules, err := s.priceRuleRepo.GetForShowtime(ctx, showtime.CinemaID, showtime.ID)
+	if err != nil {
+		s.logger.Error("Failed to get price rules", zap.Int("showtime_id", showtime.ID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get price rules: %w", err)
+	}
+
+	prices := make(map[int]*domain.SeatPrice, len(seats))
+	for _, seat := range seats {
+		prices[seat.ID] = resolveSeatPrice(rules, showtime, seat.SeatType)
+	}
+
+	return prices, nil
+}
+
+// resolveSeatPrice memilih rule paling spesifik untuk tipe kursi.
+// Tanpa rule yang cocok, harga kursi sama dengan harga showtime.
+func resolveSeatPrice(rules []*domain.SeatPriceRule, showtime *domain.Showtime, seatType string) *domain.SeatPrice {
+	price := &domain.SeatPrice{
+		SeatType:  seatType,
+		BasePrice: showtime.Price,
+		Price:     showtime.Price,
+	}
+
+	var best *domain.SeatPriceRule
+	bestScore := -1
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.SeatType, seatType) || !ruleMatchesShowtime(rule, showtime) {
+			continue
+		}
+
+		// Later rules win ties, so a newer rule overrides an older one with the same scope
+		if score := ruleSpecificity(rule, showtime); score >= bestScore {
+			best, bestScore = rule, score
+		}
+	}
+
+	if best == nil {
+		return price
+	}
+
+	ruleID := best.ID
+	price.RuleID = &ruleID
+	if best.Price != nil {
+		price.Price = *best.Price
+	} else if best.Multiplier != nil {
+		multiplier := *best.Multiplier
+		price.Multiplier = &multiplier
+		price.Price = showtime.Price.MulRate(multiplier)
+	}
+
+	return price
+}
+
+// ruleSpecificity: rule showtime > rule cinema > rule global, dan rule dengan batasan hari/jam menang di scope yang sama
+func ruleSpecificity(rule *domain.SeatPriceRule, showtime *domain.Showtime) int {
+	score := 0
+	switch {
+	case rule.ShowtimeID != nil && *rule.ShowtimeID == showtime.ID:
+		score = 4
+	case rule.CinemaID != nil && *rule.CinemaID == showtime.CinemaID:
+		score = 2
+	}
+	if len(rule.DaysOfWeek) > 0 || rule.StartTime != nil {
+		score++
+	}
+	return score
+}
+
+// ruleMatchesShowtime memeriksa scope, hari, dan jam tayang rule
+func ruleMatchesShowtime(rule *domain.SeatPriceRule, showtime *domain.Showtime) bool {
+	if rule.ShowtimeID != nil && *rule.ShowtimeID != showtime.ID {
+		return false
+	}
+	if rule.CinemaID != nil && *rule.CinemaID != showtime.CinemaID {
+		return false
+	}
+
+	if len(rule.DaysOfWeek) > 0 {
+		weekday := int(showtime.ShowDate.Weekday())
+		matched := false
+		for _, day := range rule.DaysOfWeek {
+			if day == weekday {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if rule.StartTime != nil && rule.EndTime != nil {
+		at := showtime.ShowTime.Format("15:04")
+		start, end := *rule.StartTime, *rule.EndTime
+		if start <= end {
+			return at >= start && at < end
+		}
+		// Band crosses midnight, e.g. 22:00-02:00
+		return at >= start || at < end
+	}
+
+	return true
+}
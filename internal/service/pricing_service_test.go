@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// staticPriceRules adalah PriceRuleRepository in-memory untuk test
+type staticPriceRules []*domain.SeatPriceRule
+
+func (r staticPriceRules) GetForShowtime(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatPriceRule, error) {
+	return r, nil
+}
+
+type failingPriceRules struct{}
+
+func (failingPriceRules) GetForShowtime(ctx context.Context, cinemaID, showtimeID int) ([]*domain.SeatPriceRule, error) {
+	return nil, errors.New("database error")
+}
+
+// testPricing mengembalikan pricing tanpa rule, jadi harga kursi = harga showtime
+func testPricing() PricingService {
+	return NewPricingService(staticPriceRules(nil), zap.NewNop())
+}
+
+func multiplierRule(id int, seatType string, multiplier float64) *domain.SeatPriceRule {
+	return &domain.SeatPriceRule{ID: id, SeatType: seatType, Multiplier: &multiplier}
+}
+
+func fixedRule(id int, seatType string, price domain.Money) *domain.SeatPriceRule {
+	return &domain.SeatPriceRule{ID: id, SeatType: seatType, Price: &price}
+}
+
+// pricingShowtime adalah showtime Sabtu 2024-01-20 jam 19:30 seharga 50000
+func pricingShowtime() *domain.Showtime {
+	return &domain.Showtime{
+		ID:       7,
+		CinemaID: 1,
+		ShowDate: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC),
+		ShowTime: time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+		Price:    domain.NewMoney(50000),
+	}
+}
+
+func TestPricingService_PriceSeats(t *testing.T) {
+	rules := staticPriceRules{
+		multiplierRule(1, "regular", 1),
+		multiplierRule(2, "premium", 1.25),
+		fixedRule(3, "vip", domain.NewMoney(100000)),
+	}
+	service := NewPricingService(rules, zap.NewNop())
+
+	seats := []*domain.Seat{
+		{ID: 10, SeatType: "regular"},
+		{ID: 11, SeatType: "premium"},
+		{ID: 12, SeatType: "vip"},
+		{ID: 13, SeatType: "couple"},
+	}
+
+	prices, err := service.PriceSeats(context.Background(), pricingShowtime(), seats)
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50000), prices[10].Price)
+	assert.Equal(t, domain.NewMoney(62500), prices[11].Price)
+	assert.Equal(t, 1.25, *prices[11].Multiplier)
+	assert.Equal(t, 2, *prices[11].RuleID)
+	assert.Equal(t, domain.NewMoney(100000), prices[12].Price)
+	assert.Nil(t, prices[12].Multiplier)
+	assert.Equal(t, domain.NewMoney(50000), prices[12].BasePrice)
+	// No rule for the seat type: showtime price
+	assert.Equal(t, domain.NewMoney(50000), prices[13].Price)
+	assert.Nil(t, prices[13].RuleID)
+}
+
+func TestPricingService_PriceSeats_RepositoryError(t *testing.T) {
+	service := NewPricingService(failingPriceRules{}, zap.NewNop())
+
+	prices, err := service.PriceSeats(context.Background(), pricingShowtime(), []*domain.Seat{{ID: 1}})
+
+	assert.Error(t, err)
+	assert.Nil(t, prices)
+}
+
+func TestResolveSeatPrice_MostSpecificRuleWins(t *testing.T) {
+	cinemaID, otherCinemaID, showtimeID := 1, 2, 7
+
+	cinemaRule := multiplierRule(2, "vip", 1.4)
+	cinemaRule.CinemaID = &cinemaID
+
+	otherCinemaRule := multiplierRule(3, "vip", 3)
+	otherCinemaRule.CinemaID = &otherCinemaID
+
+	weekendRule := multiplierRule(4, "vip", 1.6)
+	weekendRule.CinemaID = &cinemaID
+	weekendRule.DaysOfWeek = []int{0, 6}
+
+	showtimeRule := fixedRule(5, "vip", domain.NewMoney(90000))
+	showtimeRule.ShowtimeID = &showtimeID
+
+	tests := []struct {
+		name   string
+		rules  []*domain.SeatPriceRule
+		ruleID int
+		price  domain.Money
+	}{
+		{"global only", []*domain.SeatPriceRule{multiplierRule(1, "vip", 1.5)}, 1, domain.NewMoney(75000)},
+		{"cinema beats global", []*domain.SeatPriceRule{cinemaRule, multiplierRule(1, "vip", 1.5)}, 2, domain.NewMoney(70000)},
+		{"other cinema ignored", []*domain.SeatPriceRule{multiplierRule(1, "vip", 1.5), otherCinemaRule}, 1, domain.NewMoney(75000)},
+		{"weekday band beats plain cinema rule", []*domain.SeatPriceRule{cinemaRule, weekendRule}, 4, domain.NewMoney(80000)},
+		{"showtime beats everything", []*domain.SeatPriceRule{showtimeRule, weekendRule, cinemaRule}, 5, domain.NewMoney(90000)},
+		{"newer rule wins a tie", []*domain.SeatPriceRule{multiplierRule(1, "vip", 1.5), multiplierRule(6, "vip", 1.2)}, 6, domain.NewMoney(60000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := resolveSeatPrice(tt.rules, pricingShowtime(), "vip")
+
+			require.NotNil(t, price.RuleID)
+			assert.Equal(t, tt.ruleID, *price.RuleID)
+			assert.Equal(t, tt.price, price.Price)
+		})
+	}
+}
+
+func TestResolveSeatPrice_TimeBand(t *testing.T) {
+	band := func(start, end string) *domain.SeatPriceRule {
+		rule := multiplierRule(1, "regular", 0.8)
+		rule.StartTime, rule.EndTime = &start, &end
+		return rule
+	}
+
+	tests := []struct {
+		name    string
+		rule    *domain.SeatPriceRule
+		matches bool
+	}{
+		{"inside band", band("18:00", "21:00"), true},
+		{"start is inclusive", band("19:30", "21:00"), true},
+		{"end is exclusive", band("12:00", "19:30"), false},
+		{"matinee band", band("10:00", "15:00"), false},
+		{"band across midnight", band("19:00", "02:00"), true},
+		{"weekday only", func() *domain.SeatPriceRule {
+			rule := multiplierRule(1, "regular", 0.8)
+			rule.DaysOfWeek = []int{1, 2, 3, 4, 5}
+			return rule
+		}(), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := resolveSeatPrice([]*domain.SeatPriceRule{tt.rule}, pricingShowtime(), "regular")
+
+			if tt.matches {
+				assert.Equal(t, domain.NewMoney(40000), price.Price)
+			} else {
+				assert.Equal(t, domain.NewMoney(50000), price.Price)
+				assert.Nil(t, price.RuleID)
+			}
+		})
+	}
+}
+
+func TestResolveSeatPrice_RoundsToCents(t *testing.T) {
+	showtime := pricingShowtime()
+	showtime.Price = domain.Money(3333333)
+
+	price := resolveSeatPrice([]*domain.SeatPriceRule{multiplierRule(1, "premium", 1.15)}, showtime, "premium")
+
+	// 33333.33 x 1.15 = 38333.3295
+	assert.Equal(t, domain.Money(3833333), price.Price)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrPromotionNotFound dikembalikan ketika promo code tidak dikenal
+	ErrPromotionNotFound = errors.New("promo code not found")
+	// ErrPromotionLookupFailed dikembalikan ketika promo gagal dibaca dari database
+	ErrPromotionLookupFailed = errors.New("failed to get promo code")
+	// ErrPromotionNotActive dikembalikan ketika promo nonaktif atau di luar masa berlaku
+	ErrPromotionNotActive = errors.New("promo code is not active")
+	// ErrPromotionNotApplicable dikembalikan ketika promo tidak berlaku untuk film, cinema, atau payment method
+	ErrPromotionNotApplicable = errors.New("promo code is not applicable")
+	// ErrPromotionExhausted dikembalikan ketika kuota total promo sudah habis
+	ErrPromotionExhausted = repository.ErrPromotionExhausted
+	// ErrPromotionUserLimit dikembalikan ketika user sudah mencapai batas pemakaian promo
+	ErrPromotionUserLimit = repository.ErrPromotionUserLimit
+	// ErrPromotionAlreadyApplied dikembalikan ketika booking sudah memakai promo lain
+	ErrPromotionAlreadyApplied = repository.ErrPromotionAlreadyApplied
+)
+
+type PromotionService interface {
+	Quote(ctx context.Context, userID int, code string, showtime *domain.Showtime, paymentMethod string, subtotal domain.Money) (*domain.PromotionRedemption, error)
+	ApplyToBooking(ctx context.Context, booking *domain.Booking, code, paymentMethod string) error
+	CheckPaymentMethod(ctx context.Context, booking *domain.Booking, paymentMethod string) error
+	RemoveFromBooking(ctx context.Context, booking *domain.Booking) error
+}
+
+type promotionService struct {
+	promotionRepo repository.PromotionRepository
+	logger        *zap.Logger
+}
+
+func NewPromotionService(promotionRepo repository.PromotionRepository, logger *zap.Logger) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+		logger:        logger,
+	}
+}
+
+// Quote memvalidasi promo untuk showtime dan payment method lalu menghitung potongannya.
+// Kuota pemakaian diperiksa saat redemption dicatat, bukan di sini.
+func (s *promotionService) Quote(ctx context.Context, userID int, code string, showtime *domain.Showtime, paymentMethod string, subtotal domain.Money) (*domain.PromotionRedemption, error) {
+	promo, err := s.promotionRepo.GetByCode(ctx, strings.TrimSpace(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			s.logger.Warn("Promo code not found", zap.String("promo_code", code))
+			return nil, ErrPromotionNotFound
+		}
+		s.logger.Error("Failed to get promo code", zap.String("promo_code", code), zap.Error(err))
+		return nil, fmt.Errorf("%w: %w", ErrPromotionLookupFailed, err)
+	}
+
+	if !promo.IsValidAt(time.Now()) {
+		return nil, ErrPromotionNotActive
+	}
+
+	if !promo.AppliesTo(showtime) {
+		return nil, fmt.Errorf("%w to this movie or cinema", ErrPromotionNotApplicable)
+	}
+
+	if !promo.AllowsPaymentMethod(paymentMethod) {
+		return nil, fmt.Errorf("%w to payment method %s", ErrPromotionNotApplicable, paymentMethod)
+	}
+
+	return &domain.PromotionRedemption{
+		PromotionID:    promo.ID,
+		UserID:         userID,
+		DiscountAmount: promo.Discount(subtotal),
+		Promotion:      promo,
+	}, nil
+}
+
+// ApplyToBooking memasang promo ke booking pending yang belum memakai promo
+func (s *promotionService) ApplyToBooking(ctx context.Context, booking *domain.Booking, code, paymentMethod string) error {
+	// Same code sent again on payment: only the payment method needs checking
+	if booking.PromoCode != nil {
+		if !strings.EqualFold(*booking.PromoCode, strings.TrimSpace(code)) {
+			return ErrPromotionAlreadyApplied
+		}
+		return s.CheckPaymentMethod(ctx, booking, paymentMethod)
+	}
+
+	if booking.Showtime == nil {
+		return fmt.Errorf("booking showtime is not loaded")
+	}
+
+	redemption, err := s.Quote(ctx, booking.UserID, code, booking.Showtime, paymentMethod, booking.TotalPrice)
+	if err != nil {
+		return err
+	}
+
+	if err := s.promotionRepo.ApplyToBooking(ctx, booking, redemption); err != nil {
+		s.logger.Warn("Failed to apply promo code",
+			zap.Int("booking_id", booking.ID),
+			zap.String("promo_code", redemption.Promotion.Code),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	s.logger.Info("Promo code applied",
+		zap.Int("booking_id", booking.ID),
+		zap.String("promo_code", redemption.Promotion.Code),
+		zap.Stringer("discount_amount", redemption.DiscountAmount),
+	)
+
+	return nil
+}
+
+// RemoveFromBooking melepas promo yang dipasang saat pembayaran yang akhirnya tidak jadi
+func (s *promotionService) RemoveFromBooking(ctx context.Context, booking *domain.Booking) error {
+	if booking.PromoCode == nil {
+		return nil
+	}
+
+	code := *booking.PromoCode
+	if err := s.promotionRepo.RemoveFromBooking(ctx, booking); err != nil {
+		s.logger.Error("Failed to remove promo code", zap.Int("booking_id", booking.ID), zap.String("promo_code", code), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Promo code removed", zap.Int("booking_id", booking.ID), zap.String("promo_code", code))
+
+	return nil
+}
+
+// CheckPaymentMethod memastikan promo yang sudah terpasang di booking berlaku untuk payment method
+func (s *promotionService) CheckPaymentMethod(ctx context.Context, booking *domain.Booking, paymentMethod string) error {
+	if booking.PromoCode == nil {
+		return nil
+	}
+
+	promo, err := s.promotionRepo.GetByCode(ctx, *booking.PromoCode)
+	if err != nil {
+		s.logger.Error("Failed to get promo of booking", zap.Int("booking_id", booking.ID), zap.Error(err))
+		return fmt.Errorf("%w: %w", ErrPromotionLookupFailed, err)
+	}
+
+	if !promo.AllowsPaymentMethod(paymentMethod) {
+		return fmt.Errorf("%w to payment method %s", ErrPromotionNotApplicable, paymentMethod)
+	}
+
+	return nil
+}
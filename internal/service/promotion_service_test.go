@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockPromotionRepository struct {
+	mock.Mock
+}
+
+func (m *MockPromotionRepository) GetByCode(ctx context.Context, code string) (*domain.Promotion, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Promotion), args.Error(1)
+}
+
+func (m *MockPromotionRepository) ApplyToBooking(ctx context.Context, booking *domain.Booking, redemption *domain.PromotionRedemption) error {
+	args := m.Called(ctx, booking, redemption)
+	return args.Error(0)
+}
+
+func (m *MockPromotionRepository) RemoveFromBooking(ctx context.Context, booking *domain.Booking) error {
+	args := m.Called(ctx, booking)
+	return args.Error(0)
+}
+
+// testPromotions mengembalikan promotion service tanpa promo
+func testPromotions() PromotionService {
+	return NewPromotionService(new(MockPromotionRepository), zap.NewNop())
+}
+
+// testPromotion adalah promo 20% maksimal 15000 yang sedang berlaku
+func testPromotion() *domain.Promotion {
+	maxDiscount := domain.NewMoney(15000)
+	return &domain.Promotion{
+		ID:              1,
+		Code:            "HEMAT20",
+		DiscountType:    domain.DiscountTypePercent,
+		DiscountPercent: 20,
+		MaxDiscount:     &maxDiscount,
+		ValidFrom:       time.Now().Add(-time.Hour),
+		ValidUntil:      time.Now().Add(time.Hour),
+		IsActive:        true,
+	}
+}
+
+func TestPromotionService_Quote(t *testing.T) {
+	promo := testPromotion()
+
+	tests := []struct {
+		name     string
+		subtotal domain.Money
+		discount domain.Money
+	}{
+		{"percent of subtotal", domain.NewMoney(50000), domain.NewMoney(10000)},
+		{"capped by max discount", domain.NewMoney(100000), domain.NewMoney(15000)},
+		// 20% of 33333.33 = 6666.666
+		{"rounded to cents", domain.Money(3333333), domain.Money(666667)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPromotionRepo := new(MockPromotionRepository)
+			service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+			ctx := context.Background()
+			mockPromotionRepo.On("GetByCode", ctx, "hemat20").Return(promo, nil)
+
+			redemption, err := service.Quote(ctx, 5, " hemat20 ", &domain.Showtime{ID: 1, CinemaID: 1, MovieID: 1}, "CREDIT_CARD", tt.subtotal)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.discount, redemption.DiscountAmount)
+			assert.Equal(t, 1, redemption.PromotionID)
+			assert.Equal(t, 5, redemption.UserID)
+		})
+	}
+}
+
+func TestPromotionService_Quote_FixedNeverExceedsSubtotal(t *testing.T) {
+	mockPromotionRepo := new(MockPromotionRepository)
+	service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	promo := testPromotion()
+	promo.DiscountType = domain.DiscountTypeFixed
+	promo.DiscountAmount = domain.NewMoney(75000)
+
+	ctx := context.Background()
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(promo, nil)
+
+	redemption, err := service.Quote(ctx, 5, "HEMAT20", &domain.Showtime{ID: 1}, "CREDIT_CARD", domain.NewMoney(50000))
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50000), redemption.DiscountAmount)
+}
+
+func TestPromotionService_Quote_Rejected(t *testing.T) {
+	showtime := &domain.Showtime{ID: 1, CinemaID: 1, MovieID: 1}
+
+	tests := []struct {
+		name   string
+		modify func(p *domain.Promotion)
+		err    error
+	}{
+		{"inactive", func(p *domain.Promotion) { p.IsActive = false }, ErrPromotionNotActive},
+		{"not started", func(p *domain.Promotion) { p.ValidFrom = time.Now().Add(time.Hour) }, ErrPromotionNotActive},
+		{"expired", func(p *domain.Promotion) { p.ValidUntil = time.Now().Add(-time.Minute) }, ErrPromotionNotActive},
+		{"other movie", func(p *domain.Promotion) { p.MovieIDs = []int{2} }, ErrPromotionNotApplicable},
+		{"other cinema", func(p *domain.Promotion) { p.CinemaIDs = []int{2, 3} }, ErrPromotionNotApplicable},
+		{"other payment method", func(p *domain.Promotion) { p.PaymentMethods = []string{"GOPAY"} }, ErrPromotionNotApplicable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPromotionRepo := new(MockPromotionRepository)
+			service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+			promo := testPromotion()
+			tt.modify(promo)
+
+			ctx := context.Background()
+			mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(promo, nil)
+
+			redemption, err := service.Quote(ctx, 5, "HEMAT20", showtime, "CREDIT_CARD", domain.NewMoney(50000))
+
+			assert.ErrorIs(t, err, tt.err)
+			assert.Nil(t, redemption)
+		})
+	}
+}
+
+func TestPromotionService_Quote_NotFound(t *testing.T) {
+	mockPromotionRepo := new(MockPromotionRepository)
+	service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	ctx := context.Background()
+	mockPromotionRepo.On("GetByCode", ctx, "NOPE").Return(nil, fmt.Errorf("promotion %w", repository.ErrNotFound))
+
+	redemption, err := service.Quote(ctx, 5, "NOPE", &domain.Showtime{ID: 1}, "CREDIT_CARD", domain.NewMoney(50000))
+
+	assert.ErrorIs(t, err, ErrPromotionNotFound)
+	assert.Nil(t, redemption)
+}
+
+func TestPromotionService_Quote_LookupFails(t *testing.T) {
+	mockPromotionRepo := new(MockPromotionRepository)
+	service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	ctx := context.Background()
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(nil, errors.New("connection refused"))
+
+	redemption, err := service.Quote(ctx, 5, "HEMAT20", &domain.Showtime{ID: 1}, "CREDIT_CARD", domain.NewMoney(50000))
+
+	assert.ErrorIs(t, err, ErrPromotionLookupFailed)
+	assert.NotErrorIs(t, err, ErrPromotionNotFound)
+	assert.Nil(t, redemption)
+}
+
+func TestPromotionService_ApplyToBooking(t *testing.T) {
+	mockPromotionRepo := new(MockPromotionRepository)
+	service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(50000), Showtime: &domain.Showtime{ID: 1}}
+
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+	mockPromotionRepo.On("ApplyToBooking", ctx, booking, mock.MatchedBy(func(r *domain.PromotionRedemption) bool {
+		return r.PromotionID == 1 && r.UserID == 5 && r.DiscountAmount == domain.NewMoney(10000)
+	})).Return(nil)
+
+	err := service.ApplyToBooking(ctx, booking, "HEMAT20", "CREDIT_CARD")
+
+	assert.NoError(t, err)
+	mockPromotionRepo.AssertExpectations(t)
+}
+
+func TestPromotionService_ApplyToBooking_Exhausted(t *testing.T) {
+	mockPromotionRepo := new(MockPromotionRepository)
+	service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+	ctx := context.Background()
+	booking := &domain.Booking{ID: 9, UserID: 5, TotalPrice: domain.NewMoney(50000), Showtime: &domain.Showtime{ID: 1}}
+
+	mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(testPromotion(), nil)
+	mockPromotionRepo.On("ApplyToBooking", ctx, booking, mock.AnythingOfType("*domain.PromotionRedemption")).Return(ErrPromotionExhausted)
+
+	err := service.ApplyToBooking(ctx, booking, "HEMAT20", "CREDIT_CARD")
+
+	assert.ErrorIs(t, err, ErrPromotionExhausted)
+}
+
+func TestPromotionService_ApplyToBooking_AlreadyHasPromo(t *testing.T) {
+	code := "HEMAT20"
+
+	t.Run("same code only checks payment method", func(t *testing.T) {
+		mockPromotionRepo := new(MockPromotionRepository)
+		service := NewPromotionService(mockPromotionRepo, zap.NewNop())
+
+		ctx := context.Background()
+		promo := testPromotion()
+		promo.PaymentMethods = []string{"GOPAY"}
+		mockPromotionRepo.On("GetByCode", ctx, "HEMAT20").Return(promo, nil)
+
+		booking := &domain.Booking{ID: 9, PromoCode: &code}
+
+		assert.NoError(t, service.ApplyToBooking(ctx, booking, "hemat20", "gopay"))
+		assert.ErrorIs(t, service.ApplyToBooking(ctx, booking, "hemat20", "CREDIT_CARD"), ErrPromotionNotApplicable)
+		mockPromotionRepo.AssertNotCalled(t, "ApplyToBooking", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("different code", func(t *testing.T) {
+		service := NewPromotionService(new(MockPromotionRepository), zap.NewNop())
+
+		err := service.ApplyToBooking(context.Background(), &domain.Booking{ID: 9, PromoCode: &code}, "OTHER", "CREDIT_CARD")
+
+		assert.ErrorIs(t, err, ErrPromotionAlreadyApplied)
+	})
+}
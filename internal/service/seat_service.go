@@ -2,40 +2,75 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/config"
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 	"project-app-bioskop-golang-homework-anas/internal/repository"
 
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrScreenNotFound dikembalikan ketika studio tidak ada di cinema tersebut
+	ErrScreenNotFound = errors.New("screen not found")
+	// ErrInvalidSeatLayout dikembalikan ketika denah kursi yang diupload tidak konsisten
+	ErrInvalidSeatLayout = errors.New("invalid seat layout")
+	// ErrSeatNotFound dikembalikan ketika kursi tidak ada
+	ErrSeatNotFound = errors.New("seat not found")
+	// ErrSeatExists dikembalikan ketika baris dan nomor kursi sudah dipakai di studio yang sama
+	ErrSeatExists = repository.ErrSeatExists
+	// ErrSeatPositionTaken dikembalikan ketika sel grid sudah ditempati kursi lain
+	ErrSeatPositionTaken = repository.ErrSeatPositionTaken
+	// ErrSeatHasBookings dikembalikan ketika kursi yang akan dihapus masih dibooking untuk showtime mendatang
+	ErrSeatHasBookings = repository.ErrSeatHasBookings
+)
+
 type SeatService interface {
-	GetSeatAvailability(ctx context.Context, cinemaID int, date, time string) ([]*domain.SeatAvailability, *domain.Showtime, error)
+	GetSeatAvailability(ctx context.Context, cinemaID, showtimeID int, date, time string) ([]*domain.SeatAvailability, *domain.Showtime, error)
+	ImportLayout(ctx context.Context, cinemaID, screenID int, layout *domain.SeatLayout) ([]*domain.Seat, error)
+	CreateSeat(ctx context.Context, cinemaID, screenID int, req *domain.SeatRequest) (*domain.Seat, error)
+	UpdateSeat(ctx context.Context, id int, req *domain.SeatUpdateRequest) (*domain.Seat, error)
+	DeleteSeat(ctx context.Context, id int) error
 }
 
 type seatService struct {
-	seatRepo     repository.SeatRepository
-	showtimeRepo repository.ShowtimeRepository
-	cinemaRepo   repository.CinemaRepository
-	logger       *zap.Logger
+	seatRepo       repository.SeatRepository
+	showtimeRepo   repository.ShowtimeRepository
+	cinemaRepo     repository.CinemaRepository
+	screenRepo     repository.ScreenRepository
+	pricingService PricingService
+	config         *config.Config
+	logger         *zap.Logger
+	now            func() time.Time // jam sekarang, bisa diganti di test
 }
 
 func NewSeatService(
 	seatRepo repository.SeatRepository,
 	showtimeRepo repository.ShowtimeRepository,
 	cinemaRepo repository.CinemaRepository,
+	screenRepo repository.ScreenRepository,
+	pricingService PricingService,
+	config *config.Config,
 	logger *zap.Logger,
 ) SeatService {
 	return &seatService{
-		seatRepo:     seatRepo,
-		showtimeRepo: showtimeRepo,
-		cinemaRepo:   cinemaRepo,
-		logger:       logger,
+		seatRepo:       seatRepo,
+		showtimeRepo:   showtimeRepo,
+		cinemaRepo:     cinemaRepo,
+		screenRepo:     screenRepo,
+		pricingService: pricingService,
+		config:         config,
+		logger:         logger,
+		now:            time.Now,
 	}
 }
 
-func (s *seatService) GetSeatAvailability(ctx context.Context, cinemaID int, date, time string) ([]*domain.SeatAvailability, *domain.Showtime, error) {
+// GetSeatAvailability mengambil status kursi untuk showtime_id, atau cinema/date/time (legacy)
+func (s *seatService) GetSeatAvailability(ctx context.Context, cinemaID, showtimeID int, date, time string) ([]*domain.SeatAvailability, *domain.Showtime, error) {
 	// Validate cinema exists
 	_, err := s.cinemaRepo.GetByID(ctx, cinemaID)
 	if err != nil {
@@ -45,30 +80,325 @@ func (s *seatService) GetSeatAvailability(ctx context.Context, cinemaID int, dat
 	// Log untuk debugging
 	s.logger.Info("Getting showtime",
 		zap.Int("cinema_id", cinemaID),
+		zap.Int("showtime_id", showtimeID),
 		zap.String("date", date),
 		zap.String("time", time),
 	)
 
 	// Get showtime
-	showtime, err := s.showtimeRepo.GetByCinemaDateTime(ctx, cinemaID, date, time)
+	showtime, err := resolveShowtime(ctx, s.showtimeRepo, showtimeID, cinemaID, date, time)
 	if err != nil {
 		s.logger.Error("Showtime not found",
 			zap.Int("cinema_id", cinemaID),
+			zap.Int("showtime_id", showtimeID),
 			zap.String("date", date),
 			zap.String("time", time),
 			zap.Error(err),
 		)
-		return nil, nil, fmt.Errorf("no showtime found for the specified date and time")
+		if errors.Is(err, ErrAmbiguousShowtime) || showtimeID > 0 {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("no showtime found for the specified date and time: %w", ErrShowtimeNotFound)
 	}
 
 	s.logger.Info("Showtime found", zap.Int("showtime_id", showtime.ID))
 
-	// Get seat availability
-	seats, err := s.seatRepo.GetAvailableSeats(ctx, cinemaID, showtime.ID)
+	// Seats are no longer shown once ticket sales for the showtime have closed
+	if err := checkSalesOpen(showtime, s.now(), s.config.Booking.SalesCutoff); err != nil {
+		return nil, nil, err
+	}
+
+	// Get seat availability for the screen the showtime plays in
+	seats, err := s.seatRepo.GetAvailableSeats(ctx, showtime.ScreenID, showtime.ID)
 	if err != nil {
 		s.logger.Error("Failed to get seat availability", zap.Error(err))
 		return nil, nil, fmt.Errorf("failed to get seat availability: %w", err)
 	}
 
+	// Price each seat by its type for this showtime
+	seatList := make([]*domain.Seat, 0, len(seats))
+	for _, sa := range seats {
+		seatList = append(seatList, sa.Seat)
+	}
+	prices, err := s.pricingService.PriceSeats(ctx, showtime, seatList)
+	if err != nil {
+		s.logger.Error("Failed to price seats", zap.Int("showtime_id", showtime.ID), zap.Error(err))
+		return nil, nil, fmt.Errorf("%w: %v", ErrSeatPricingFailed, err)
+	}
+	for _, sa := range seats {
+		sa.Price = prices[sa.Seat.ID]
+	}
+
+	// Attach the screen's seat map so clients can draw aisles and gaps
+	screen, err := s.screenRepo.GetByID(ctx, showtime.ScreenID)
+	if err != nil {
+		s.logger.Warn("Failed to get screen layout", zap.Int("screen_id", showtime.ScreenID), zap.Error(err))
+	} else {
+		showtime.Screen = screen
+	}
+
 	return seats, showtime, nil
 }
+
+// ImportLayout memvalidasi lalu menyimpan denah kursi baru untuk studio
+func (s *seatService) ImportLayout(ctx context.Context, cinemaID, screenID int, layout *domain.SeatLayout) ([]*domain.Seat, error) {
+	screen, err := s.screenRepo.GetByID(ctx, screenID)
+	if err != nil || screen.CinemaID != cinemaID {
+		return nil, ErrScreenNotFound
+	}
+
+	if err := normalizeSeatLayout(layout); err != nil {
+		return nil, err
+	}
+
+	seats, err := s.screenRepo.ImportLayout(ctx, screen, layout)
+	if err != nil {
+		s.logger.Error("Failed to import seat layout", zap.Int("screen_id", screenID), zap.Error(err))
+		return nil, fmt.Errorf("failed to import seat layout: %w", err)
+	}
+
+	s.logger.Info("Seat layout imported",
+		zap.Int("cinema_id", cinemaID),
+		zap.Int("screen_id", screenID),
+		zap.Int("seat_count", len(seats)),
+	)
+
+	return seats, nil
+}
+
+// CreateSeat menambah satu kursi ke studio (admin)
+func (s *seatService) CreateSeat(ctx context.Context, cinemaID, screenID int, req *domain.SeatRequest) (*domain.Seat, error) {
+	screen, err := s.screenRepo.GetByID(ctx, screenID)
+	if err != nil || screen.CinemaID != cinemaID {
+		return nil, ErrScreenNotFound
+	}
+
+	seat := &domain.Seat{
+		CinemaID:     cinemaID,
+		ScreenID:     screenID,
+		SeatRow:      strings.ToUpper(strings.TrimSpace(req.Row)),
+		SeatNumber:   req.Number,
+		SeatType:     req.Type,
+		GridX:        req.X,
+		GridY:        req.Y,
+		IsAccessible: req.Accessible,
+		IsBlocked:    req.Blocked,
+	}
+	if seat.SeatType == "" {
+		seat.SeatType = "regular"
+	}
+
+	if err := s.checkSeatPlacement(ctx, screen, seat); err != nil {
+		return nil, err
+	}
+
+	if err := s.seatRepo.Create(ctx, seat); err != nil {
+		if errors.Is(err, ErrSeatExists) || errors.Is(err, ErrSeatPositionTaken) {
+			return nil, fmt.Errorf("%w: %s", err, seat.Label())
+		}
+		s.logger.Error("Failed to create seat", zap.Int("screen_id", screenID), zap.Error(err))
+		return nil, fmt.Errorf("failed to create seat: %w", err)
+	}
+
+	s.logger.Info("Seat created", zap.Int("seat_id", seat.ID), zap.Int("screen_id", screenID), zap.String("seat", seat.Label()))
+	return seat, nil
+}
+
+// UpdateSeat mengubah tipe, posisi, dan status kursi (admin)
+func (s *seatService) UpdateSeat(ctx context.Context, id int, req *domain.SeatUpdateRequest) (*domain.Seat, error) {
+	seat, err := s.seatRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrSeatNotFound
+	}
+
+	screen, err := s.screenRepo.GetByID(ctx, seat.ScreenID)
+	if err != nil {
+		return nil, ErrScreenNotFound
+	}
+
+	seat.SeatType = req.Type
+	seat.GridX = req.X
+	seat.GridY = req.Y
+	seat.IsAccessible = req.Accessible
+	seat.IsBlocked = req.Blocked
+
+	if err := s.checkSeatPlacement(ctx, screen, seat); err != nil {
+		return nil, err
+	}
+
+	// The repository blocks/unblocks the couple partner together with this seat
+	if err := s.seatRepo.Update(ctx, seat); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrSeatNotFound
+		case errors.Is(err, ErrSeatPositionTaken):
+			return nil, fmt.Errorf("%w: %s", err, seat.Label())
+		}
+		s.logger.Error("Failed to update seat", zap.Int("seat_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to update seat: %w", err)
+	}
+
+	s.logger.Info("Seat updated", zap.Int("seat_id", id))
+	return seat, nil
+}
+
+// DeleteSeat menghapus kursi (soft delete), ditolak selama masih dibooking untuk showtime mendatang
+func (s *seatService) DeleteSeat(ctx context.Context, id int) error {
+	if err := s.seatRepo.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrSeatNotFound
+		case errors.Is(err, ErrSeatHasBookings):
+			return err
+		}
+		s.logger.Error("Failed to delete seat", zap.Int("seat_id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete seat: %w", err)
+	}
+
+	s.logger.Info("Seat deleted", zap.Int("seat_id", id))
+	return nil
+}
+
+// checkSeatPlacement menerapkan aturan denah (normalizeSeatLayout) untuk satu kursi:
+// di dalam grid, tidak di lorong/sel yang diblokir, tidak menempati sel kursi lain,
+// dan tetap bersebelahan dengan pasangan couple seat-nya.
+func (s *seatService) checkSeatPlacement(ctx context.Context, screen *domain.Screen, seat *domain.Seat) error {
+	if seat.GridX == nil || seat.GridY == nil {
+		return nil
+	}
+	x, y := *seat.GridX, *seat.GridY
+
+	if layout := screen.Layout; layout != nil {
+		if x >= layout.Columns || y >= layout.Rows {
+			return invalidSeatLayout("seat %s at (%d,%d) is outside the %dx%d grid", seat.Label(), x, y, layout.Columns, layout.Rows)
+		}
+
+		closed, err := closedSeatCells(layout)
+		if err != nil {
+			return err
+		}
+		if what, ok := closed[domain.SeatLayoutCell{X: x, Y: y}]; ok {
+			return invalidSeatLayout("seat %s at (%d,%d) is on %s", seat.Label(), x, y, what)
+		}
+	}
+
+	others, err := s.seatRepo.GetByScreenID(ctx, seat.ScreenID)
+	if err != nil {
+		return fmt.Errorf("failed to get seats: %w", err)
+	}
+
+	for _, other := range others {
+		if other.ID == seat.ID || other.GridX == nil || other.GridY == nil {
+			continue
+		}
+		if *other.GridX == x && *other.GridY == y {
+			return fmt.Errorf("%w: seat %s already sits at (%d,%d)", ErrSeatPositionTaken, other.Label(), x, y)
+		}
+		if seat.PairSeatID != nil && other.ID == *seat.PairSeatID {
+			if *other.GridY != y || (*other.GridX-x != 1 && x-*other.GridX != 1) {
+				return invalidSeatLayout("couple seats %s and %s must sit next to each other in the same row", seat.Label(), other.Label())
+			}
+		}
+	}
+
+	return nil
+}
+
+func invalidSeatLayout(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidSeatLayout, fmt.Sprintf(format, args...))
+}
+
+// closedSeatCells mengembalikan sel grid yang tidak boleh ditempati kursi (lorong dan sel yang diblokir)
+func closedSeatCells(layout *domain.SeatLayout) (map[domain.SeatLayoutCell]string, error) {
+	closed := make(map[domain.SeatLayoutCell]string)
+	for _, y := range layout.Aisles.Rows {
+		if y < 0 || y >= layout.Rows {
+			return nil, invalidSeatLayout("aisle row %d is outside the grid", y)
+		}
+		for x := 0; x < layout.Columns; x++ {
+			closed[domain.SeatLayoutCell{X: x, Y: y}] = "an aisle"
+		}
+	}
+	for _, x := range layout.Aisles.Columns {
+		if x < 0 || x >= layout.Columns {
+			return nil, invalidSeatLayout("aisle column %d is outside the grid", x)
+		}
+		for y := 0; y < layout.Rows; y++ {
+			closed[domain.SeatLayoutCell{X: x, Y: y}] = "an aisle"
+		}
+	}
+	for _, cell := range layout.BlockedCells {
+		if cell.X < 0 || cell.X >= layout.Columns || cell.Y < 0 || cell.Y >= layout.Rows {
+			return nil, invalidSeatLayout("blocked cell (%d,%d) is outside the grid", cell.X, cell.Y)
+		}
+		closed[cell] = "a blocked cell"
+	}
+
+	return closed, nil
+}
+
+// normalizeSeatLayout merapikan label kursi dan memastikan denah konsisten:
+// kursi berada di dalam grid, tidak di lorong/sel yang diblokir, tidak bertumpuk,
+// dan couple seat saling menunjuk, bersebelahan langsung di baris yang sama,
+// serta diblokir bersamaan.
+func normalizeSeatLayout(layout *domain.SeatLayout) error {
+	inGrid := func(x, y int) bool {
+		return x >= 0 && x < layout.Columns && y >= 0 && y < layout.Rows
+	}
+
+	// Cells that cannot hold a seat
+	closed, err := closedSeatCells(layout)
+	if err != nil {
+		return err
+	}
+
+	byCell := make(map[domain.SeatLayoutCell]string, len(layout.Seats))
+	byLabel := make(map[string]*domain.SeatLayoutSeat, len(layout.Seats))
+	for _, seat := range layout.Seats {
+		seat.Row = strings.ToUpper(strings.TrimSpace(seat.Row))
+		seat.Pair = strings.ToUpper(strings.TrimSpace(seat.Pair))
+		if seat.Type == "" {
+			seat.Type = "regular"
+		}
+
+		label := seat.Label()
+		if _, ok := byLabel[label]; ok {
+			return invalidSeatLayout("seat %s appears more than once", label)
+		}
+		byLabel[label] = seat
+
+		cell := domain.SeatLayoutCell{X: seat.X, Y: seat.Y}
+		if !inGrid(cell.X, cell.Y) {
+			return invalidSeatLayout("seat %s at (%d,%d) is outside the %dx%d grid", label, cell.X, cell.Y, layout.Columns, layout.Rows)
+		}
+		if what, ok := closed[cell]; ok {
+			return invalidSeatLayout("seat %s at (%d,%d) is on %s", label, cell.X, cell.Y, what)
+		}
+		if other, ok := byCell[cell]; ok {
+			return invalidSeatLayout("seats %s and %s share cell (%d,%d)", other, label, cell.X, cell.Y)
+		}
+		byCell[cell] = label
+	}
+
+	for _, seat := range layout.Seats {
+		if seat.Pair == "" {
+			continue
+		}
+		label := seat.Label()
+		pair, ok := byLabel[seat.Pair]
+		switch {
+		case !ok:
+			return invalidSeatLayout("seat %s is paired with unknown seat %s", label, seat.Pair)
+		case pair == seat:
+			return invalidSeatLayout("seat %s cannot be paired with itself", label)
+		case pair.Pair != label:
+			return invalidSeatLayout("seat %s is paired with %s but %s is not paired back", label, seat.Pair, seat.Pair)
+		case pair.Y != seat.Y || (pair.X-seat.X != 1 && seat.X-pair.X != 1):
+			return invalidSeatLayout("couple seats %s and %s must sit next to each other in the same row", label, seat.Pair)
+		case pair.Blocked != seat.Blocked:
+			return invalidSeatLayout("couple seats %s and %s must be blocked together", label, seat.Pair)
+		}
+	}
+
+	return nil
+}
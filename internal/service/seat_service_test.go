@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"project-app-bioskop-golang-homework-anas/internal/domain"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 )
 
@@ -15,9 +17,11 @@ func TestSeatService_GetSeatAvailability_Success(t *testing.T) {
 	mockSeatRepo := new(MockSeatRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
 	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
 	logger := zap.NewNop()
 
-	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, logger)
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger).(*seatService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
@@ -29,7 +33,9 @@ func TestSeatService_GetSeatAvailability_Success(t *testing.T) {
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
+		ScreenID: 1,
 		MovieID:  1,
+		ShowDate: upcomingShowDate,
 	}
 
 	seats := []*domain.SeatAvailability{
@@ -56,8 +62,9 @@ func TestSeatService_GetSeatAvailability_Success(t *testing.T) {
 	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetAvailableSeats", ctx, 1, 1).Return(seats, nil)
+	mockScreenRepo.On("GetByID", ctx, 1).Return(&domain.Screen{ID: 1, CinemaID: 1, Layout: &domain.SeatLayout{Rows: 5, Columns: 8}}, nil)
 
-	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, "2024-01-15", "14:00")
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 0, "2024-01-15", "14:00")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resultSeats)
@@ -65,6 +72,7 @@ func TestSeatService_GetSeatAvailability_Success(t *testing.T) {
 	assert.Len(t, resultSeats, 2)
 	assert.False(t, resultSeats[0].IsBooked)
 	assert.True(t, resultSeats[1].IsBooked)
+	assert.Equal(t, 8, resultShowtime.Screen.Layout.Columns)
 	mockCinemaRepo.AssertExpectations(t)
 	mockShowtimeRepo.AssertExpectations(t)
 	mockSeatRepo.AssertExpectations(t)
@@ -74,15 +82,16 @@ func TestSeatService_GetSeatAvailability_CinemaNotFound(t *testing.T) {
 	mockSeatRepo := new(MockSeatRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
 	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
 	logger := zap.NewNop()
 
-	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, logger)
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 
 	mockCinemaRepo.On("GetByID", ctx, 999).Return(nil, errors.New("not found"))
 
-	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 999, "2024-01-15", "14:00")
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 999, 0, "2024-01-15", "14:00")
 
 	assert.Error(t, err)
 	assert.Nil(t, resultSeats)
@@ -95,9 +104,10 @@ func TestSeatService_GetSeatAvailability_ShowtimeNotFound(t *testing.T) {
 	mockSeatRepo := new(MockSeatRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
 	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
 	logger := zap.NewNop()
 
-	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, logger)
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger)
 
 	ctx := context.Background()
 
@@ -109,7 +119,7 @@ func TestSeatService_GetSeatAvailability_ShowtimeNotFound(t *testing.T) {
 	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(nil, errors.New("not found"))
 
-	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, "2024-01-15", "14:00")
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 0, "2024-01-15", "14:00")
 
 	assert.Error(t, err)
 	assert.Nil(t, resultSeats)
@@ -123,9 +133,11 @@ func TestSeatService_GetSeatAvailability_EmptySeats(t *testing.T) {
 	mockSeatRepo := new(MockSeatRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
 	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
 	logger := zap.NewNop()
 
-	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, logger)
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger).(*seatService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
@@ -137,7 +149,9 @@ func TestSeatService_GetSeatAvailability_EmptySeats(t *testing.T) {
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
+		ScreenID: 1,
 		MovieID:  1,
+		ShowDate: upcomingShowDate,
 	}
 
 	emptySeats := []*domain.SeatAvailability{}
@@ -145,8 +159,10 @@ func TestSeatService_GetSeatAvailability_EmptySeats(t *testing.T) {
 	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetAvailableSeats", ctx, 1, 1).Return(emptySeats, nil)
+	// Missing layout must not break seat availability
+	mockScreenRepo.On("GetByID", ctx, 1).Return(nil, errors.New("screen not found"))
 
-	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, "2024-01-15", "14:00")
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 0, "2024-01-15", "14:00")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resultSeats)
@@ -161,9 +177,11 @@ func TestSeatService_GetSeatAvailability_GetSeatsError(t *testing.T) {
 	mockSeatRepo := new(MockSeatRepository)
 	mockShowtimeRepo := new(MockShowtimeRepository)
 	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
 	logger := zap.NewNop()
 
-	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, logger)
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger).(*seatService)
+	service.now = fixedClock(bookingTestNow)
 
 	ctx := context.Background()
 
@@ -175,14 +193,16 @@ func TestSeatService_GetSeatAvailability_GetSeatsError(t *testing.T) {
 	showtime := &domain.Showtime{
 		ID:       1,
 		CinemaID: 1,
+		ScreenID: 1,
 		MovieID:  1,
+		ShowDate: upcomingShowDate,
 	}
 
 	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
 	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(showtime, nil)
 	mockSeatRepo.On("GetAvailableSeats", ctx, 1, 1).Return(nil, errors.New("database error"))
 
-	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, "2024-01-15", "14:00")
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 0, "2024-01-15", "14:00")
 
 	assert.Error(t, err)
 	assert.Nil(t, resultSeats)
@@ -192,3 +212,351 @@ func TestSeatService_GetSeatAvailability_GetSeatsError(t *testing.T) {
 	mockShowtimeRepo.AssertExpectations(t)
 	mockSeatRepo.AssertExpectations(t)
 }
+
+func TestSeatService_GetSeatAvailability_ByShowtimeID(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	logger := zap.NewNop()
+
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger).(*seatService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 2, MovieID: 2, ShowDate: upcomingShowDate}
+	seats := []*domain.SeatAvailability{
+		{Seat: &domain.Seat{ID: 1, CinemaID: 1, SeatRow: "A"}, ShowtimeID: 7},
+	}
+
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetAvailableSeats", ctx, 2, 7).Return(seats, nil)
+	mockScreenRepo.On("GetByID", ctx, 2).Return(&domain.Screen{ID: 2, CinemaID: 1}, nil)
+
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 7, "", "")
+
+	assert.NoError(t, err)
+	assert.Len(t, resultSeats, 1)
+	assert.Equal(t, 7, resultShowtime.ID)
+	mockShowtimeRepo.AssertExpectations(t)
+	mockSeatRepo.AssertExpectations(t)
+}
+
+func TestSeatService_GetSeatAvailability_AmbiguousShowtime(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	logger := zap.NewNop()
+
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, testPricing(), testBookingConfig(), logger)
+
+	ctx := context.Background()
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByCinemaDateTime", ctx, 1, "2024-01-15", "14:00").Return(nil, ErrAmbiguousShowtime)
+
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 0, "2024-01-15", "14:00")
+
+	assert.ErrorIs(t, err, ErrAmbiguousShowtime)
+	assert.Nil(t, resultSeats)
+	assert.Nil(t, resultShowtime)
+	mockSeatRepo.AssertNotCalled(t, "GetAvailableSeats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func testImportLayout() *domain.SeatLayout {
+	return &domain.SeatLayout{
+		Rows:         3,
+		Columns:      5,
+		Aisles:       domain.SeatLayoutAisles{Columns: []int{2}},
+		BlockedCells: []domain.SeatLayoutCell{{X: 0, Y: 2}},
+		Seats: []*domain.SeatLayoutSeat{
+			{Row: "a", Number: 1, X: 0, Y: 0, Accessible: true},
+			{Row: "A", Number: 2, X: 1, Y: 0},
+			{Row: "B", Number: 1, X: 3, Y: 1, Type: "premium", Pair: "b2"},
+			{Row: "B", Number: 2, X: 4, Y: 1, Type: "premium", Pair: "B1"},
+		},
+	}
+}
+
+func TestSeatService_ImportLayout_Success(t *testing.T) {
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(new(MockSeatRepository), new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	screen := &domain.Screen{ID: 2, CinemaID: 1}
+	layout := testImportLayout()
+	imported := []*domain.Seat{{ID: 1, ScreenID: 2, SeatRow: "A", SeatNumber: 1}}
+
+	mockScreenRepo.On("GetByID", ctx, 2).Return(screen, nil)
+	mockScreenRepo.On("ImportLayout", ctx, screen, layout).Return(imported, nil)
+
+	seats, err := service.ImportLayout(ctx, 1, 2, layout)
+
+	assert.NoError(t, err)
+	assert.Equal(t, imported, seats)
+	// Labels are normalised and the default seat type is filled in
+	assert.Equal(t, "A", layout.Seats[0].Row)
+	assert.Equal(t, "regular", layout.Seats[0].Type)
+	assert.Equal(t, "B2", layout.Seats[2].Pair)
+	mockScreenRepo.AssertExpectations(t)
+}
+
+func TestSeatService_ImportLayout_ScreenOfOtherCinema(t *testing.T) {
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(new(MockSeatRepository), new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	mockScreenRepo.On("GetByID", ctx, 2).Return(&domain.Screen{ID: 2, CinemaID: 3}, nil)
+
+	seats, err := service.ImportLayout(ctx, 1, 2, testImportLayout())
+
+	assert.ErrorIs(t, err, ErrScreenNotFound)
+	assert.Nil(t, seats)
+	mockScreenRepo.AssertNotCalled(t, "ImportLayout", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSeatService_ImportLayout_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(l *domain.SeatLayout)
+		message string
+	}{
+		{"outside grid", func(l *domain.SeatLayout) { l.Seats[1].X = 5 }, "outside the 5x3 grid"},
+		{"on aisle", func(l *domain.SeatLayout) { l.Seats[1].X = 2 }, "on an aisle"},
+		{"on blocked cell", func(l *domain.SeatLayout) { l.Seats[1].X, l.Seats[1].Y = 0, 2 }, "on a blocked cell"},
+		{"same cell", func(l *domain.SeatLayout) { l.Seats[1].X = 0 }, "share cell (0,0)"},
+		{"duplicate label", func(l *domain.SeatLayout) { l.Seats[1].Number = 1 }, "A1 appears more than once"},
+		{"aisle outside grid", func(l *domain.SeatLayout) { l.Aisles.Rows = []int{3} }, "aisle row 3"},
+		{"unknown pair", func(l *domain.SeatLayout) { l.Seats[2].Pair = "C1" }, "unknown seat C1"},
+		{"one-way pair", func(l *domain.SeatLayout) { l.Seats[3].Pair = "" }, "not paired back"},
+		{"pair not adjacent", func(l *domain.SeatLayout) { l.Seats[3].X, l.Seats[3].Y = 4, 0 }, "next to each other"},
+		{"half of couple blocked", func(l *domain.SeatLayout) { l.Seats[2].Blocked = true }, "must be blocked together"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockScreenRepo := new(MockScreenRepository)
+			service := NewSeatService(new(MockSeatRepository), new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+			ctx := context.Background()
+			mockScreenRepo.On("GetByID", ctx, 2).Return(&domain.Screen{ID: 2, CinemaID: 1}, nil)
+
+			layout := testImportLayout()
+			tt.modify(layout)
+
+			seats, err := service.ImportLayout(ctx, 1, 2, layout)
+
+			assert.ErrorIs(t, err, ErrInvalidSeatLayout)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.Nil(t, seats)
+			mockScreenRepo.AssertNotCalled(t, "ImportLayout", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSeatService_GetSeatAvailability_Prices(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	pricing := NewPricingService(staticPriceRules{fixedRule(3, "vip", domain.NewMoney(75000))}, zap.NewNop())
+
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, pricing, testBookingConfig(), zap.NewNop()).(*seatService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 2, Price: domain.NewMoney(50000), ShowDate: upcomingShowDate}
+	seats := []*domain.SeatAvailability{
+		{Seat: &domain.Seat{ID: 1, SeatType: "regular"}, ShowtimeID: 7},
+		{Seat: &domain.Seat{ID: 2, SeatType: "vip"}, ShowtimeID: 7},
+	}
+
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetAvailableSeats", ctx, 2, 7).Return(seats, nil)
+	mockScreenRepo.On("GetByID", ctx, 2).Return(&domain.Screen{ID: 2, CinemaID: 1}, nil)
+
+	resultSeats, _, err := service.GetSeatAvailability(ctx, 1, 7, "", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(50000), resultSeats[0].Price.Price)
+	assert.Nil(t, resultSeats[0].Price.RuleID)
+	assert.Equal(t, domain.NewMoney(75000), resultSeats[1].Price.Price)
+	assert.Equal(t, 3, *resultSeats[1].Price.RuleID)
+}
+
+func TestSeatService_GetSeatAvailability_PricingFails(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	pricing := NewPricingService(failingPriceRules{}, zap.NewNop())
+
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, mockScreenRepo, pricing, testBookingConfig(), zap.NewNop()).(*seatService)
+	service.now = fixedClock(bookingTestNow)
+
+	ctx := context.Background()
+	showtime := &domain.Showtime{ID: 7, CinemaID: 1, ScreenID: 2, Price: domain.NewMoney(50000), ShowDate: upcomingShowDate}
+	seats := []*domain.SeatAvailability{
+		{Seat: &domain.Seat{ID: 1, SeatType: "regular"}, ShowtimeID: 7},
+	}
+
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(showtime, nil)
+	mockSeatRepo.On("GetAvailableSeats", ctx, 2, 7).Return(seats, nil)
+
+	resultSeats, resultShowtime, err := service.GetSeatAvailability(ctx, 1, 7, "", "")
+
+	assert.ErrorIs(t, err, ErrSeatPricingFailed)
+	assert.Nil(t, resultSeats)
+	assert.Nil(t, resultShowtime)
+	mockScreenRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestSeatService_GetSeatAvailability_SalesClosed(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+
+	service := NewSeatService(mockSeatRepo, mockShowtimeRepo, mockCinemaRepo, new(MockScreenRepository), testPricing(), testBookingConfig(), zap.NewNop()).(*seatService)
+	service.now = fixedClock(time.Date(2026, 1, 11, 21, 0, 0, 0, domain.TimezoneLocation(domain.TimezoneWITA)))
+
+	ctx := context.Background()
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByID", ctx, 7).Return(witaShowtime(), nil)
+
+	seats, showtime, err := service.GetSeatAvailability(ctx, 1, 7, "", "")
+
+	assert.ErrorIs(t, err, ErrSalesClosed)
+	assert.Nil(t, seats)
+	assert.Nil(t, showtime)
+	mockSeatRepo.AssertNotCalled(t, "GetAvailableSeats", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// testSeatScreen adalah studio dengan grid testImportLayout: lorong di kolom 2, sel kosong di (0,2)
+func testSeatScreen() *domain.Screen {
+	return &domain.Screen{ID: 2, CinemaID: 1, Layout: testImportLayout().Grid()}
+}
+
+// testScreenSeats adalah kursi yang sudah ada di testSeatScreen; B1 dan B2 adalah couple seat
+func testScreenSeats() []*domain.Seat {
+	x := func(v int) *int { return &v }
+	return []*domain.Seat{
+		{ID: 1, ScreenID: 2, SeatRow: "A", SeatNumber: 1, SeatType: "regular", GridX: x(0), GridY: x(0)},
+		{ID: 3, ScreenID: 2, SeatRow: "B", SeatNumber: 1, SeatType: "premium", GridX: x(3), GridY: x(1), PairSeatID: x(4)},
+		{ID: 4, ScreenID: 2, SeatRow: "B", SeatNumber: 2, SeatType: "premium", GridX: x(4), GridY: x(1), PairSeatID: x(3)},
+	}
+}
+
+func TestSeatService_CreateSeat_Success(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(mockSeatRepo, new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	x, y := 1, 0
+	mockScreenRepo.On("GetByID", ctx, 2).Return(testSeatScreen(), nil)
+	mockSeatRepo.On("GetByScreenID", ctx, 2).Return(testScreenSeats(), nil)
+	mockSeatRepo.On("Create", ctx, mock.AnythingOfType("*domain.Seat")).Return(nil)
+
+	seat, err := service.CreateSeat(ctx, 1, 2, &domain.SeatRequest{Row: "a", Number: 2, X: &x, Y: &y})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "A2", seat.Label())
+	assert.Equal(t, "regular", seat.SeatType)
+	mockSeatRepo.AssertExpectations(t)
+}
+
+func TestSeatService_CreateSeat_InvalidPlacement(t *testing.T) {
+	tests := []struct {
+		name    string
+		x, y    int
+		wantErr error
+		message string
+	}{
+		{"outside grid", 5, 0, ErrInvalidSeatLayout, "outside the 5x3 grid"},
+		{"on aisle", 2, 0, ErrInvalidSeatLayout, "on an aisle"},
+		{"on blocked cell", 0, 2, ErrInvalidSeatLayout, "on a blocked cell"},
+		{"cell taken", 0, 0, ErrSeatPositionTaken, "seat A1 already sits at (0,0)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSeatRepo := new(MockSeatRepository)
+			mockScreenRepo := new(MockScreenRepository)
+			service := NewSeatService(mockSeatRepo, new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+			ctx := context.Background()
+			mockScreenRepo.On("GetByID", ctx, 2).Return(testSeatScreen(), nil)
+			mockSeatRepo.On("GetByScreenID", ctx, 2).Return(testScreenSeats(), nil)
+
+			x, y := tt.x, tt.y
+			seat, err := service.CreateSeat(ctx, 1, 2, &domain.SeatRequest{Row: "C", Number: 1, X: &x, Y: &y})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.Nil(t, seat)
+			mockSeatRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSeatService_UpdateSeat_CoupleMovedApart(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(mockSeatRepo, new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	seats := testScreenSeats()
+	x, y := 1, 1
+	mockSeatRepo.On("GetByID", ctx, 3).Return(seats[1], nil)
+	mockScreenRepo.On("GetByID", ctx, 2).Return(testSeatScreen(), nil)
+	mockSeatRepo.On("GetByScreenID", ctx, 2).Return(testScreenSeats(), nil)
+
+	seat, err := service.UpdateSeat(ctx, 3, &domain.SeatUpdateRequest{Type: "premium", X: &x, Y: &y})
+
+	assert.ErrorIs(t, err, ErrInvalidSeatLayout)
+	assert.Contains(t, err.Error(), "next to each other")
+	assert.Nil(t, seat)
+	mockSeatRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestSeatService_UpdateSeat_BlockCouple(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(mockSeatRepo, new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	seats := testScreenSeats()
+	mockSeatRepo.On("GetByID", ctx, 3).Return(seats[1], nil)
+	mockScreenRepo.On("GetByID", ctx, 2).Return(testSeatScreen(), nil)
+	mockSeatRepo.On("GetByScreenID", ctx, 2).Return(testScreenSeats(), nil)
+	mockSeatRepo.On("Update", ctx, seats[1]).Return(nil)
+
+	// Blocking one half is allowed: the repository blocks the partner in the same statement
+	seat, err := service.UpdateSeat(ctx, 3, &domain.SeatUpdateRequest{Type: "premium", X: seats[1].GridX, Y: seats[1].GridY, Blocked: true})
+
+	assert.NoError(t, err)
+	assert.True(t, seat.IsBlocked)
+	mockSeatRepo.AssertExpectations(t)
+}
+
+func TestSeatService_UpdateSeat_PositionTakenConcurrently(t *testing.T) {
+	mockSeatRepo := new(MockSeatRepository)
+	mockScreenRepo := new(MockScreenRepository)
+	service := NewSeatService(mockSeatRepo, new(MockShowtimeRepository), new(MockCinemaRepository), mockScreenRepo, testPricing(), testBookingConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	seats := testScreenSeats()
+	x, y := 1, 0
+	mockSeatRepo.On("GetByID", ctx, 1).Return(seats[0], nil)
+	mockScreenRepo.On("GetByID", ctx, 2).Return(testSeatScreen(), nil)
+	mockSeatRepo.On("GetByScreenID", ctx, 2).Return(testScreenSeats(), nil)
+	mockSeatRepo.On("Update", ctx, seats[0]).Return(ErrSeatPositionTaken)
+
+	seat, err := service.UpdateSeat(ctx, 1, &domain.SeatUpdateRequest{Type: "regular", X: &x, Y: &y})
+
+	assert.ErrorIs(t, err, ErrSeatPositionTaken)
+	assert.Nil(t, seat)
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	scheduleDateLayout   = "2006-01-02"
+	defaultScheduleDays  = 7
+	maxScheduleRangeDays = 31
+
+	// maxGeneratedScheduleDays membatasi rentang satu kali generate jadwal berulang
+	maxGeneratedScheduleDays = 92
+)
+
+var (
+	// ErrInvalidScheduleRange dikembalikan ketika parameter tanggal jadwal tidak valid
+	ErrInvalidScheduleRange = errors.New("invalid date range")
+	// ErrShowtimeNotFound dikembalikan ketika showtime yang diminta tidak ada
+	ErrShowtimeNotFound = errors.New("showtime not found")
+	// ErrAmbiguousShowtime dikembalikan ketika cinema/date/time cocok dengan lebih dari satu showtime
+	ErrAmbiguousShowtime = repository.ErrAmbiguousShowtime
+	// ErrSalesClosed dikembalikan ketika showtime sudah lewat batas penjualan tiket (BOOKING_SALES_CUTOFF_MINUTES)
+	ErrSalesClosed = errors.New("ticket sales for this showtime are closed")
+	// ErrInvalidShowtime dikembalikan ketika jadwal showtime yang dibuat/diubah admin tidak valid
+	ErrInvalidShowtime = errors.New("invalid showtime")
+	// ErrShowtimeExists dikembalikan ketika film yang sama sudah dijadwalkan di studio dan jam yang sama
+	ErrShowtimeExists = repository.ErrShowtimeExists
+	// ErrShowtimeHasBookings dikembalikan ketika showtime yang masih punya booking aktif akan dihapus atau dijadwal ulang
+	ErrShowtimeHasBookings = repository.ErrShowtimeHasBookings
+	// ErrShowtimeOverlap dikembalikan ketika jadwal bentrok dengan showtime lain di studio yang sama
+	ErrShowtimeOverlap = repository.ErrShowtimeOverlap
+)
+
+type ShowtimeService interface {
+	GetCinemaShowtimes(ctx context.Context, cinemaID int, date string) ([]*domain.ShowtimeAvailability, error)
+	GetMovieShowtimes(ctx context.Context, movieID int, from, to string) ([]*domain.ShowtimeAvailability, error)
+	CreateShowtime(ctx context.Context, req *domain.ShowtimeRequest) (*domain.Showtime, error)
+	UpdateShowtime(ctx context.Context, id int, req *domain.ShowtimeRequest) (*domain.Showtime, error)
+	DeleteShowtime(ctx context.Context, id int) error
+	GenerateSchedule(ctx context.Context, req *domain.ScheduleRequest) (*domain.ScheduleResult, error)
+}
+
+type showtimeService struct {
+	showtimeRepo repository.ShowtimeRepository
+	cinemaRepo   repository.CinemaRepository
+	movieRepo    repository.MovieRepository
+	screenRepo   repository.ScreenRepository
+	config       *config.Config
+	logger       *zap.Logger
+	now          func() time.Time // jam sekarang, bisa diganti di test
+}
+
+func NewShowtimeService(
+	showtimeRepo repository.ShowtimeRepository,
+	cinemaRepo repository.CinemaRepository,
+	movieRepo repository.MovieRepository,
+	screenRepo repository.ScreenRepository,
+	config *config.Config,
+	logger *zap.Logger,
+) ShowtimeService {
+	return &showtimeService{
+		showtimeRepo: showtimeRepo,
+		cinemaRepo:   cinemaRepo,
+		movieRepo:    movieRepo,
+		screenRepo:   screenRepo,
+		config:       config,
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// GetCinemaShowtimes mengambil jadwal tayang cinema pada satu tanggal (default hari ini di zona waktu cinema)
+func (s *showtimeService) GetCinemaShowtimes(ctx context.Context, cinemaID int, date string) ([]*domain.ShowtimeAvailability, error) {
+	// Validated before the lookup, the day is placed in the cinema's timezone once the cinema is known
+	day, err := parseScheduleDate(date, time.UTC, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	cinema, err := s.cinemaRepo.GetByID(ctx, cinemaID)
+	if err != nil {
+		s.logger.Error("Failed to get cinema", zap.Int("cinema_id", cinemaID), zap.Error(err))
+		return nil, ErrCinemaNotFound
+	}
+
+	if date == "" {
+		day = s.today(cinema.TimeLocation())
+	} else {
+		day = dateIn(day, cinema.TimeLocation())
+	}
+
+	showtimes, err := s.showtimeRepo.GetByCinemaAndDate(ctx, cinemaID, day)
+	if err != nil {
+		s.logger.Error("Failed to get cinema showtimes", zap.Int("cinema_id", cinemaID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+
+	return showtimes, nil
+}
+
+// GetMovieShowtimes mengambil jadwal tayang film di semua cinema antara from dan to
+// (default hari ini di DefaultTimezone sampai 7 hari ke depan, maksimal 31 hari)
+func (s *showtimeService) GetMovieShowtimes(ctx context.Context, movieID int, from, to string) ([]*domain.ShowtimeAvailability, error) {
+	loc := domain.TimezoneLocation(domain.DefaultTimezone)
+	fromDate, err := parseScheduleDate(from, loc, s.today(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	toDate, err := parseScheduleDate(to, loc, fromDate.AddDate(0, 0, defaultScheduleDays-1))
+	if err != nil {
+		return nil, err
+	}
+
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidScheduleRange)
+	}
+	if toDate.Sub(fromDate) >= maxScheduleRangeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range must not exceed %d days", ErrInvalidScheduleRange, maxScheduleRangeDays)
+	}
+
+	if _, err := s.movieRepo.GetByID(ctx, movieID); err != nil {
+		s.logger.Error("Failed to get movie", zap.Int("movie_id", movieID), zap.Error(err))
+		return nil, ErrMovieNotFound
+	}
+
+	showtimes, err := s.showtimeRepo.GetByMovieAndDateRange(ctx, movieID, fromDate, toDate)
+	if err != nil {
+		s.logger.Error("Failed to get movie showtimes", zap.Int("movie_id", movieID), zap.Error(err))
+		return nil, fmt.Errorf("failed to get showtimes: %w", err)
+	}
+
+	return showtimes, nil
+}
+
+// CreateShowtime menjadwalkan film di studio (admin)
+func (s *showtimeService) CreateShowtime(ctx context.Context, req *domain.ShowtimeRequest) (*domain.Showtime, error) {
+	showtime, err := s.buildShowtime(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOverlap(ctx, showtime); err != nil {
+		return nil, err
+	}
+
+	if err := s.showtimeRepo.Create(ctx, showtime); err != nil {
+		if errors.Is(err, ErrShowtimeExists) || errors.Is(err, ErrShowtimeOverlap) {
+			return nil, err
+		}
+		s.logger.Error("Failed to create showtime", zap.Error(err))
+		return nil, fmt.Errorf("failed to create showtime: %w", err)
+	}
+
+	s.logger.Info("Showtime created",
+		zap.Int("showtime_id", showtime.ID),
+		zap.Int("screen_id", showtime.ScreenID),
+		zap.Int("movie_id", showtime.MovieID),
+		zap.Time("starts_at", showtime.StartsAt()),
+	)
+
+	return showtime, nil
+}
+
+// UpdateShowtime mengubah showtime (admin). Harga selalu boleh diubah, tapi jadwal, studio,
+// dan film tidak boleh diubah selama masih ada booking aktif.
+func (s *showtimeService) UpdateShowtime(ctx context.Context, id int, req *domain.ShowtimeRequest) (*domain.Showtime, error) {
+	existing, err := s.showtimeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, ErrShowtimeNotFound
+	}
+
+	showtime, err := s.buildShowtime(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	showtime.ID = id
+
+	rescheduled := showtime.ScreenID != existing.ScreenID ||
+		showtime.MovieID != existing.MovieID ||
+		!showtime.StartsAt().Equal(existing.StartsAt())
+	if rescheduled {
+		active, err := s.showtimeRepo.HasActiveBookings(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to check showtime bookings", zap.Int("showtime_id", id), zap.Error(err))
+			return nil, fmt.Errorf("failed to update showtime: %w", err)
+		}
+		if active {
+			return nil, ErrShowtimeHasBookings
+		}
+
+		if err := s.checkOverlap(ctx, showtime); err != nil {
+			return nil, err
+		}
+	} else {
+		// Jadwal tidak berubah: pertahankan buffer yang dipakai saat showtime dibuat
+		showtime.EndsAt = existing.EndsAt
+		showtime.CleaningBufferMinutes = existing.CleaningBufferMinutes
+	}
+
+	if err := s.showtimeRepo.Update(ctx, showtime); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, ErrShowtimeNotFound
+		case errors.Is(err, ErrShowtimeExists), errors.Is(err, ErrShowtimeOverlap):
+			return nil, err
+		}
+		s.logger.Error("Failed to update showtime", zap.Int("showtime_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to update showtime: %w", err)
+	}
+
+	s.logger.Info("Showtime updated", zap.Int("showtime_id", id), zap.Bool("rescheduled", rescheduled))
+	return showtime, nil
+}
+
+// DeleteShowtime menghapus showtime (soft delete), ditolak selama masih ada booking aktif
+func (s *showtimeService) DeleteShowtime(ctx context.Context, id int) error {
+	if err := s.showtimeRepo.Delete(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return ErrShowtimeNotFound
+		case errors.Is(err, ErrShowtimeHasBookings):
+			return err
+		}
+		s.logger.Error("Failed to delete showtime", zap.Int("showtime_id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete showtime: %w", err)
+	}
+
+	s.logger.Info("Showtime deleted", zap.Int("showtime_id", id))
+	return nil
+}
+
+// GenerateSchedule membuat showtime berulang dari pola jadwal (admin). Slot yang sudah lewat, sudah ada,
+// atau bentrok dengan showtime lain dilewati dan dilaporkan tanpa menggagalkan slot lainnya.
+// Jika berhenti karena error lain, result tetap dikembalikan berisi showtime yang sudah terlanjur dibuat.
+func (s *showtimeService) GenerateSchedule(ctx context.Context, req *domain.ScheduleRequest) (*domain.ScheduleResult, error) {
+	from, err := time.Parse(scheduleDateLayout, req.From)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidScheduleRange)
+	}
+
+	to, err := time.Parse(scheduleDateLayout, req.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidScheduleRange)
+	}
+
+	// from and to are calendar days (UTC), newShowtime places each day in the cinema's timezone
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidScheduleRange)
+	}
+	if to.Sub(from) >= maxGeneratedScheduleDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range must not exceed %d days", ErrInvalidScheduleRange, maxGeneratedScheduleDays)
+	}
+
+	clocks := make([]time.Time, 0, len(req.Times))
+	for _, value := range req.Times {
+		clock, err := parseShowClock(value)
+		if err != nil {
+			return nil, err
+		}
+		clocks = append(clocks, clock)
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i].Before(clocks[j]) })
+
+	target, err := s.resolveShowtimeTarget(ctx, req.ScreenID, req.MovieID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.ScheduleResult{
+		Created: make([]*domain.Showtime, 0),
+		Skipped: make([]domain.ScheduleSkip, 0),
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if !scheduledOn(day, req.DaysOfWeek) {
+			continue
+		}
+
+		for _, clock := range clocks {
+			showtime, err := s.newShowtime(target, day, clock, req.Price)
+			if err == nil {
+				err = s.checkOverlap(ctx, showtime)
+			}
+			if err == nil {
+				err = s.showtimeRepo.Create(ctx, showtime)
+			}
+
+			switch {
+			case err == nil:
+				// Cinema, studio, dan film sama untuk semua slot, tidak perlu diulang di response
+				showtime.Cinema, showtime.Screen, showtime.Movie = nil, nil, nil
+				result.Created = append(result.Created, showtime)
+			case errors.Is(err, ErrInvalidShowtime),
+				errors.Is(err, ErrShowtimeExists),
+				errors.Is(err, ErrShowtimeOverlap):
+				result.Skipped = append(result.Skipped, domain.ScheduleSkip{
+					Date:   day.Format(scheduleDateLayout),
+					Time:   clock.Format("15:04"),
+					Reason: err.Error(),
+				})
+			default:
+				s.logger.Error("Failed to generate schedule",
+					zap.Int("screen_id", req.ScreenID),
+					zap.Int("movie_id", req.MovieID),
+					zap.Int("created", len(result.Created)),
+					zap.Error(err),
+				)
+				return result, fmt.Errorf("failed to generate schedule: %w", err)
+			}
+		}
+	}
+
+	s.logger.Info("Schedule generated",
+		zap.Int("screen_id", req.ScreenID),
+		zap.Int("movie_id", req.MovieID),
+		zap.Int("created", len(result.Created)),
+		zap.Int("skipped", len(result.Skipped)),
+	)
+
+	return result, nil
+}
+
+// scheduledOn memeriksa apakah tanggal termasuk hari dalam pola (kosong = setiap hari)
+func scheduledOn(day time.Time, daysOfWeek []int) bool {
+	if len(daysOfWeek) == 0 {
+		return true
+	}
+
+	for _, weekday := range daysOfWeek {
+		if time.Weekday(weekday) == day.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildShowtime memvalidasi request admin: studio, cinema, dan film harus ada, dan jadwal belum lewat
+func (s *showtimeService) buildShowtime(ctx context.Context, req *domain.ShowtimeRequest) (*domain.Showtime, error) {
+	date, err := time.Parse(scheduleDateLayout, req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not a YYYY-MM-DD date", ErrInvalidShowtime, req.Date)
+	}
+
+	clock, err := parseShowClock(req.Time)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveShowtimeTarget(ctx, req.ScreenID, req.MovieID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newShowtime(target, date, clock, req.Price)
+}
+
+// showtimeTarget adalah studio, cinema, dan film yang akan dijadwalkan
+type showtimeTarget struct {
+	screen *domain.Screen
+	cinema *domain.Cinema
+	movie  *domain.Movie
+}
+
+func (s *showtimeService) resolveShowtimeTarget(ctx context.Context, screenID, movieID int) (*showtimeTarget, error) {
+	screen, err := s.screenRepo.GetByID(ctx, screenID)
+	if err != nil {
+		return nil, ErrScreenNotFound
+	}
+
+	cinema, err := s.cinemaRepo.GetByID(ctx, screen.CinemaID)
+	if err != nil {
+		return nil, ErrCinemaNotFound
+	}
+
+	movie, err := s.movieRepo.GetByID(ctx, movieID)
+	if err != nil {
+		return nil, ErrMovieNotFound
+	}
+
+	return &showtimeTarget{screen: screen, cinema: cinema, movie: movie}, nil
+}
+
+// newShowtime menyusun showtime beserta jam selesainya; jadwal yang sudah lewat ditolak
+func (s *showtimeService) newShowtime(target *showtimeTarget, date, clock time.Time, price domain.Money) (*domain.Showtime, error) {
+	showtime := &domain.Showtime{
+		CinemaID:              target.screen.CinemaID,
+		ScreenID:              target.screen.ID,
+		MovieID:               target.movie.ID,
+		ShowDate:              dateIn(date, target.cinema.TimeLocation()),
+		ShowTime:              clock,
+		CleaningBufferMinutes: int(s.config.Showtime.CleaningBuffer / time.Minute),
+		Price:                 price,
+		Timezone:              target.cinema.Timezone,
+		Cinema:                target.cinema,
+		Screen:                target.screen,
+		Movie:                 target.movie,
+	}
+	showtime.EndsAt = showtime.StartsAt().Add(time.Duration(target.movie.Duration) * time.Minute)
+
+	if !showtime.StartsAt().After(s.now()) {
+		return nil, fmt.Errorf("%w: showtime must start in the future", ErrInvalidShowtime)
+	}
+
+	return showtime, nil
+}
+
+// checkOverlap menolak showtime yang pemakaian studionya (durasi film + buffer) bentrok dengan showtime lain.
+// Exclusion constraint showtimes_screen_no_overlap tetap menjaga jika dua request masuk bersamaan.
+func (s *showtimeService) checkOverlap(ctx context.Context, showtime *domain.Showtime) error {
+	conflicts, err := s.showtimeRepo.GetOverlapping(ctx, showtime.ScreenID, showtime.StartsAt(), showtime.ScreenFreeAt(), showtime.ID)
+	if err != nil {
+		s.logger.Error("Failed to check showtime overlap", zap.Int("screen_id", showtime.ScreenID), zap.Error(err))
+		return fmt.Errorf("failed to check showtime overlap: %w", err)
+	}
+
+	if len(conflicts) > 0 {
+		return overlapError(conflicts[0])
+	}
+
+	return nil
+}
+
+// overlapError menjelaskan showtime yang bentrok, mis. "showtime 12 (Inception) 2026-01-11 19:00-21:43"
+func overlapError(conflict *domain.Showtime) error {
+	title := ""
+	if conflict.Movie != nil {
+		title = " (" + conflict.Movie.Title + ")"
+	}
+
+	return fmt.Errorf("%w: showtime %d%s %s-%s",
+		ErrShowtimeOverlap,
+		conflict.ID,
+		title,
+		conflict.StartsAt().Format("2006-01-02 15:04"),
+		conflict.ScreenFreeAt().Format("15:04"),
+	)
+}
+
+func parseShowClock(value string) (time.Time, error) {
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.Parse(layout, value); err == nil {
+			return clock, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %s is not a HH:MM time", ErrInvalidShowtime, value)
+}
+
+// resolveShowtime mencari showtime berdasarkan showtime_id, atau cinema/date/time (legacy)
+// jika showtime_id kosong. Jika keduanya dikirim, showtime harus milik cinema tersebut.
+func resolveShowtime(ctx context.Context, repo repository.ShowtimeRepository, showtimeID, cinemaID int, date, showTime string) (*domain.Showtime, error) {
+	if showtimeID > 0 {
+		showtime, err := repo.GetByID(ctx, showtimeID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrShowtimeNotFound, err)
+		}
+		if cinemaID > 0 && showtime.CinemaID != cinemaID {
+			return nil, fmt.Errorf("%w: showtime %d does not play in cinema %d", ErrShowtimeNotFound, showtimeID, cinemaID)
+		}
+		return showtime, nil
+	}
+
+	if cinemaID == 0 || date == "" || showTime == "" {
+		return nil, fmt.Errorf("%w: showtime_id or cinema, date and time are required", ErrShowtimeNotFound)
+	}
+
+	showtime, err := repo.GetByCinemaDateTime(ctx, cinemaID, date, showTime)
+	if err != nil {
+		if errors.Is(err, ErrAmbiguousShowtime) {
+			return nil, fmt.Errorf("%w, use showtime_id instead", ErrAmbiguousShowtime)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrShowtimeNotFound, err)
+	}
+
+	return showtime, nil
+}
+
+// checkSalesOpen menolak showtime yang sudah lewat batas penjualan tiket pada waktu now
+func checkSalesOpen(showtime *domain.Showtime, now time.Time, cutoff time.Duration) error {
+	closesAt := showtime.SalesCloseAt(cutoff)
+	if now.Before(closesAt) {
+		return nil
+	}
+
+	return fmt.Errorf("%w (closed at %s)", ErrSalesClosed, closesAt.Format(time.RFC3339))
+}
+
+// parseScheduleDate membaca tanggal YYYY-MM-DD sebagai tengah malam di zona waktu loc, atau fallback jika kosong
+func parseScheduleDate(value string, loc *time.Location, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	date, err := time.ParseInLocation(scheduleDateLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s is not a YYYY-MM-DD date", ErrInvalidScheduleRange, value)
+	}
+
+	return date, nil
+}
+
+// today adalah tanggal hari ini menurut zona waktu loc, sebagai tengah malam di zona tersebut
+func (s *showtimeService) today(loc *time.Location) time.Time {
+	return dateIn(s.now().In(loc), loc)
+}
+
+// dateIn adalah tanggal kalender day (tahun, bulan, hari) pada tengah malam di zona waktu loc
+func dateIn(day time.Time, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+}
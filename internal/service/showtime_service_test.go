@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testShowtimeConfig() *config.Config {
+	return &config.Config{
+		Showtime: config.ShowtimeConfig{CleaningBuffer: 15 * time.Minute},
+	}
+}
+
+// showtimeTestNow adalah jam "sekarang" untuk test showtime: Sabtu, 10 Januari 2026 10:00 WITA
+var showtimeTestNow = time.Date(2026, 1, 10, 10, 0, 0, 0, domain.TimezoneLocation(domain.TimezoneWITA))
+
+func TestShowtimeService_GetCinemaShowtimes_Success(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	date := time.Date(2026, 1, 11, 0, 0, 0, 0, domain.TimezoneLocation(domain.DefaultTimezone))
+	showtimes := []*domain.ShowtimeAvailability{
+		{Showtime: &domain.Showtime{ID: 1, CinemaID: 1}, TotalSeats: 50, AvailableSeats: 48},
+	}
+
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1}, nil)
+	mockShowtimeRepo.On("GetByCinemaAndDate", ctx, 1, date).Return(showtimes, nil)
+
+	result, err := service.GetCinemaShowtimes(ctx, 1, "2026-01-11")
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, 48, result[0].AvailableSeats)
+	mockCinemaRepo.AssertExpectations(t)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_GetCinemaShowtimes_DateInCinemaTimezone(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	cinema := &domain.Cinema{ID: 1, Timezone: domain.TimezoneWITA}
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
+	mockShowtimeRepo.On("GetByCinemaAndDate", ctx, 1, time.Date(2026, 1, 11, 0, 0, 0, 0, domain.TimezoneLocation(domain.TimezoneWITA))).Return([]*domain.ShowtimeAvailability{}, nil)
+
+	_, err := service.GetCinemaShowtimes(ctx, 1, "2026-01-11")
+
+	assert.NoError(t, err)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_GetCinemaShowtimes_DefaultsToToday(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	// 23:30 WITA is already 00:30 the next day in WIT
+	service.now = fixedClock(time.Date(2026, 1, 10, 23, 30, 0, 0, domain.TimezoneLocation(domain.TimezoneWITA)))
+
+	ctx := context.Background()
+	cinema := &domain.Cinema{ID: 1, Timezone: domain.TimezoneWIT}
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(cinema, nil)
+	mockShowtimeRepo.On("GetByCinemaAndDate", ctx, 1, time.Date(2026, 1, 11, 0, 0, 0, 0, domain.TimezoneLocation(domain.TimezoneWIT))).Return([]*domain.ShowtimeAvailability{}, nil)
+
+	_, err := service.GetCinemaShowtimes(ctx, 1, "")
+
+	assert.NoError(t, err)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_GetCinemaShowtimes_InvalidDate(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	_, err := service.GetCinemaShowtimes(context.Background(), 1, "11-01-2026")
+
+	assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+	mockCinemaRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestShowtimeService_GetCinemaShowtimes_CinemaNotFound(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	mockCinemaRepo.On("GetByID", ctx, 999).Return(nil, errors.New("cinema not found"))
+
+	_, err := service.GetCinemaShowtimes(ctx, 999, "2026-01-11")
+
+	assert.ErrorIs(t, err, ErrCinemaNotFound)
+}
+
+func TestShowtimeService_GetMovieShowtimes_DefaultRange(t *testing.T) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockMovieRepo := new(MockMovieRepository)
+	service := NewShowtimeService(mockShowtimeRepo, new(MockCinemaRepository), mockMovieRepo, new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	ctx := context.Background()
+	wib := domain.TimezoneLocation(domain.DefaultTimezone)
+	from := time.Date(2026, 1, 11, 0, 0, 0, 0, wib)
+	to := time.Date(2026, 1, 17, 0, 0, 0, 0, wib)
+
+	mockMovieRepo.On("GetByID", ctx, 3).Return(&domain.Movie{ID: 3}, nil)
+	mockShowtimeRepo.On("GetByMovieAndDateRange", ctx, 3, from, to).Return([]*domain.ShowtimeAvailability{}, nil)
+
+	_, err := service.GetMovieShowtimes(ctx, 3, "2026-01-11", "")
+
+	assert.NoError(t, err)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_GetMovieShowtimes_InvalidRange(t *testing.T) {
+	service := NewShowtimeService(new(MockShowtimeRepository), new(MockCinemaRepository), new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop())
+
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{name: "to before from", from: "2026-01-11", to: "2026-01-10"},
+		{name: "range too long", from: "2026-01-01", to: "2026-03-01"},
+		{name: "malformed to", from: "2026-01-11", to: "tomorrow"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.GetMovieShowtimes(context.Background(), 3, tt.from, tt.to)
+			assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+		})
+	}
+}
+
+func TestShowtimeService_GetMovieShowtimes_MovieNotFound(t *testing.T) {
+	mockMovieRepo := new(MockMovieRepository)
+	service := NewShowtimeService(new(MockShowtimeRepository), new(MockCinemaRepository), mockMovieRepo, new(MockScreenRepository), testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	ctx := context.Background()
+	mockMovieRepo.On("GetByID", ctx, 999).Return(nil, errors.New("movie not found"))
+
+	_, err := service.GetMovieShowtimes(ctx, 999, "", "")
+
+	assert.ErrorIs(t, err, ErrMovieNotFound)
+}
+
+// newShowtimeAdminFixture menyiapkan studio, cinema, dan film yang valid untuk request admin
+func newShowtimeAdminFixture(ctx context.Context) (*MockShowtimeRepository, *MockCinemaRepository, *MockMovieRepository, *MockScreenRepository) {
+	mockShowtimeRepo := new(MockShowtimeRepository)
+	mockCinemaRepo := new(MockCinemaRepository)
+	mockMovieRepo := new(MockMovieRepository)
+	mockScreenRepo := new(MockScreenRepository)
+
+	mockScreenRepo.On("GetByID", ctx, 2).Return(&domain.Screen{ID: 2, CinemaID: 1, Name: "Studio 1"}, nil)
+	mockCinemaRepo.On("GetByID", ctx, 1).Return(&domain.Cinema{ID: 1, Timezone: domain.TimezoneWITA}, nil)
+	mockMovieRepo.On("GetByID", ctx, 3).Return(&domain.Movie{ID: 3, Duration: 120}, nil)
+
+	return mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo
+}
+
+func TestShowtimeService_CreateShowtime_Success(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	wita := domain.TimezoneLocation(domain.TimezoneWITA)
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	startsAt := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 19, 30, 0, 0, wita)
+	// film 120 menit selesai 21:30 WITA, studio bisa dipakai lagi 21:45
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, startsAt, startsAt.Add(135*time.Minute), 0).Return([]*domain.Showtime{}, nil)
+	mockShowtimeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Showtime")).Return(nil)
+
+	showtime, err := service.CreateShowtime(ctx, &domain.ShowtimeRequest{
+		ScreenID: 2, MovieID: 3, Date: tomorrow.Format("2006-01-02"), Time: "19:30", Price: domain.NewMoney(50000),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, showtime.CinemaID)
+	assert.Equal(t, domain.TimezoneWITA, showtime.Timezone)
+	assert.Equal(t, "19:30+08:00", showtime.StartsAt().Format("15:04Z07:00"))
+	assert.Equal(t, time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, wita), showtime.ShowDate)
+	assert.Equal(t, startsAt.Add(120*time.Minute), showtime.EndsAt)
+	assert.Equal(t, 15, showtime.CleaningBufferMinutes)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_CreateShowtime_Overlap(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	wita := domain.TimezoneLocation(domain.TimezoneWITA)
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	existing := &domain.Showtime{
+		ID: 12, ScreenID: 2, MovieID: 4,
+		ShowDate:              tomorrow,
+		ShowTime:              time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC),
+		EndsAt:                time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 19, 28, 0, 0, wita),
+		Timezone:              domain.TimezoneWITA,
+		CleaningBufferMinutes: 15,
+		Movie:                 &domain.Movie{ID: 4, Title: "Inception"},
+	}
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, mock.Anything, mock.Anything, 0).Return([]*domain.Showtime{existing}, nil)
+
+	showtime, err := service.CreateShowtime(ctx, &domain.ShowtimeRequest{
+		ScreenID: 2, MovieID: 3, Date: tomorrow.Format("2006-01-02"), Time: "19:30", Price: domain.NewMoney(50000),
+	})
+
+	assert.ErrorIs(t, err, ErrShowtimeOverlap)
+	assert.Contains(t, err.Error(), "showtime 12 (Inception)")
+	assert.Contains(t, err.Error(), "17:00-19:43")
+	assert.Nil(t, showtime)
+	mockShowtimeRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestShowtimeService_CreateShowtime_InPast(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	date := showtimeTestNow.AddDate(0, 0, -1).Format("2006-01-02")
+
+	showtime, err := service.CreateShowtime(ctx, &domain.ShowtimeRequest{
+		ScreenID: 2, MovieID: 3, Date: date, Time: "19:30", Price: domain.NewMoney(50000),
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidShowtime)
+	assert.Nil(t, showtime)
+	mockShowtimeRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestShowtimeService_UpdateShowtime_RescheduleWithBookings(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	existing := &domain.Showtime{
+		ID: 10, CinemaID: 1, ScreenID: 2, MovieID: 3,
+		ShowDate: tomorrow, ShowTime: time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+	}
+	mockShowtimeRepo.On("GetByID", ctx, 10).Return(existing, nil)
+	mockShowtimeRepo.On("HasActiveBookings", ctx, 10).Return(true, nil)
+
+	showtime, err := service.UpdateShowtime(ctx, 10, &domain.ShowtimeRequest{
+		ScreenID: 2, MovieID: 3, Date: tomorrow.Format("2006-01-02"), Time: "21:00", Price: domain.NewMoney(50000),
+	})
+
+	assert.ErrorIs(t, err, ErrShowtimeHasBookings)
+	assert.Nil(t, showtime)
+	mockShowtimeRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestShowtimeService_UpdateShowtime_PriceOnly(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	existing := &domain.Showtime{
+		ID: 10, CinemaID: 1, ScreenID: 2, MovieID: 3,
+		ShowDate: tomorrow, ShowTime: time.Date(0, 1, 1, 19, 30, 0, 0, time.UTC),
+		Timezone: domain.TimezoneWITA,
+	}
+	mockShowtimeRepo.On("GetByID", ctx, 10).Return(existing, nil)
+	mockShowtimeRepo.On("Update", ctx, mock.AnythingOfType("*domain.Showtime")).Return(nil)
+
+	showtime, err := service.UpdateShowtime(ctx, 10, &domain.ShowtimeRequest{
+		ScreenID: 2, MovieID: 3, Date: tomorrow.Format("2006-01-02"), Time: "19:30", Price: domain.NewMoney(65000),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewMoney(65000), showtime.Price)
+	mockShowtimeRepo.AssertNotCalled(t, "HasActiveBookings", mock.Anything, mock.Anything)
+	mockShowtimeRepo.AssertExpectations(t)
+}
+
+func TestShowtimeService_GenerateSchedule_SkipsConflicts(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	wita := domain.TimezoneLocation(domain.TimezoneWITA)
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	from := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, wita)
+	blocked := from.AddDate(0, 0, 1).Add(19 * time.Hour)
+
+	existing := &domain.Showtime{ID: 40, ShowDate: blocked, ShowTime: time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC), EndsAt: blocked.Add(time.Hour)}
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, blocked, mock.Anything, 0).Return([]*domain.Showtime{existing}, nil)
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, mock.Anything, mock.Anything, 0).Return([]*domain.Showtime{}, nil)
+	mockShowtimeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Showtime")).Return(nil)
+
+	result, err := service.GenerateSchedule(ctx, &domain.ScheduleRequest{
+		ScreenID: 2,
+		MovieID:  3,
+		From:     from.Format("2006-01-02"),
+		To:       from.AddDate(0, 0, 2).Format("2006-01-02"),
+		Times:    []string{"19:00", "13:00"},
+		Price:    domain.NewMoney(50000),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Created, 5)
+	assert.Equal(t, from.Add(13*time.Hour), result.Created[0].StartsAt())
+	assert.Nil(t, result.Created[0].Movie)
+	require.Len(t, result.Skipped, 1)
+	assert.Equal(t, blocked.Format("2006-01-02"), result.Skipped[0].Date)
+	assert.Equal(t, "19:00", result.Skipped[0].Time)
+	assert.Contains(t, result.Skipped[0].Reason, "showtime 40")
+	mockShowtimeRepo.AssertNumberOfCalls(t, "Create", 5)
+}
+
+func TestShowtimeService_GenerateSchedule_DaysOfWeek(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, mock.Anything, mock.Anything, 0).Return([]*domain.Showtime{}, nil)
+	mockShowtimeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Showtime")).Return(nil)
+
+	result, err := service.GenerateSchedule(ctx, &domain.ScheduleRequest{
+		ScreenID:   2,
+		MovieID:    3,
+		From:       tomorrow.Format("2006-01-02"),
+		To:         tomorrow.AddDate(0, 0, 13).Format("2006-01-02"),
+		Times:      []string{"13:00"},
+		DaysOfWeek: []int{int(time.Saturday), int(time.Sunday)},
+		Price:      domain.NewMoney(50000),
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Created, 4)
+	for _, showtime := range result.Created {
+		weekday := showtime.StartsAt().Weekday()
+		assert.True(t, weekday == time.Saturday || weekday == time.Sunday)
+	}
+	assert.Empty(t, result.Skipped)
+}
+
+func TestShowtimeService_GenerateSchedule_ErrorReturnsPartialResult(t *testing.T) {
+	ctx := context.Background()
+	mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo := newShowtimeAdminFixture(ctx)
+	service := NewShowtimeService(mockShowtimeRepo, mockCinemaRepo, mockMovieRepo, mockScreenRepo, testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	tomorrow := showtimeTestNow.AddDate(0, 0, 1)
+	mockShowtimeRepo.On("GetOverlapping", ctx, 2, mock.Anything, mock.Anything, 0).Return([]*domain.Showtime{}, nil)
+	mockShowtimeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Showtime")).Return(nil).Twice()
+	mockShowtimeRepo.On("Create", ctx, mock.AnythingOfType("*domain.Showtime")).Return(errors.New("connection reset"))
+
+	result, err := service.GenerateSchedule(ctx, &domain.ScheduleRequest{
+		ScreenID: 2,
+		MovieID:  3,
+		From:     tomorrow.Format("2006-01-02"),
+		To:       tomorrow.AddDate(0, 0, 6).Format("2006-01-02"),
+		Times:    []string{"13:00"},
+		Price:    domain.NewMoney(50000),
+	})
+
+	assert.Error(t, err)
+	require.NotNil(t, result)
+	assert.Len(t, result.Created, 2)
+	mockShowtimeRepo.AssertNumberOfCalls(t, "Create", 3)
+}
+
+func TestShowtimeService_GenerateSchedule_InvalidRange(t *testing.T) {
+	service := NewShowtimeService(new(MockShowtimeRepository), new(MockCinemaRepository), new(MockMovieRepository), new(MockScreenRepository), testShowtimeConfig(), zap.NewNop()).(*showtimeService)
+	service.now = fixedClock(showtimeTestNow)
+
+	_, err := service.GenerateSchedule(context.Background(), &domain.ScheduleRequest{
+		ScreenID: 2,
+		MovieID:  3,
+		From:     "2026-11-30",
+		To:       "2026-11-01",
+		Times:    []string{"13:00"},
+		Price:    domain.NewMoney(50000),
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidScheduleRange)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+	"project-app-bioskop-golang-homework-anas/internal/repository"
+	"project-app-bioskop-golang-homework-anas/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ticketPayloadVersion = "TKT1"
+	ticketQRCodeSize     = 512
+)
+
+var (
+	// ErrTicketNotAvailable dikembalikan ketika booking belum dibayar
+	ErrTicketNotAvailable = errors.New("ticket is only available for confirmed bookings")
+	// ErrInvalidTicket dikembalikan ketika payload atau signature tiket tidak valid
+	ErrInvalidTicket = errors.New("invalid ticket")
+	// ErrTicketAlreadyUsed dikembalikan ketika tiket sudah dipakai check-in
+	ErrTicketAlreadyUsed = errors.New("ticket has already been used")
+	// ErrWrongShowtime dikembalikan ketika tiket bukan untuk showtime yang sedang check-in
+	ErrWrongShowtime = errors.New("ticket is not valid for this showtime")
+)
+
+type TicketService interface {
+	GetTicketQRCode(ctx context.Context, userID int, code string) ([]byte, error)
+	CheckIn(ctx context.Context, req *domain.CheckInRequest) (*domain.Booking, error)
+}
+
+type ticketService struct {
+	bookingRepo repository.BookingRepository
+	config      *config.Config
+	logger      *zap.Logger
+}
+
+func NewTicketService(
+	bookingRepo repository.BookingRepository,
+	config *config.Config,
+	logger *zap.Logger,
+) TicketService {
+	return &ticketService{
+		bookingRepo: bookingRepo,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+// GetTicketQRCode membuat QR code (PNG) berisi payload tiket yang ditandatangani
+func (s *ticketService) GetTicketQRCode(ctx context.Context, userID int, code string) ([]byte, error) {
+	booking, err := s.bookingRepo.GetByCode(ctx, code)
+	if err != nil {
+		s.logger.Error("Failed to get booking", zap.String("booking_code", code), zap.Error(err))
+		return nil, ErrBookingNotFound
+	}
+
+	if booking.UserID != userID {
+		return nil, ErrBookingNotOwned
+	}
+
+	if booking.Status != "confirmed" {
+		return nil, ErrTicketNotAvailable
+	}
+
+	payload := s.signTicket(&domain.TicketClaims{
+		BookingCode: booking.BookingCode,
+		ShowtimeID:  booking.ShowtimeID,
+		Seats:       seatLabels(booking),
+	})
+
+	return utils.GenerateQRCode(payload, ticketQRCodeSize)
+}
+
+// CheckIn memverifikasi tiket yang di-scan petugas dan mencatat checked_in_at
+func (s *ticketService) CheckIn(ctx context.Context, req *domain.CheckInRequest) (*domain.Booking, error) {
+	claims, err := s.parseTicket(req.Payload)
+	if err != nil {
+		s.logger.Warn("Rejected ticket", zap.Error(err))
+		return nil, ErrInvalidTicket
+	}
+
+	if claims.ShowtimeID != req.ShowtimeID {
+		return nil, ErrWrongShowtime
+	}
+
+	booking, err := s.bookingRepo.GetByCode(ctx, claims.BookingCode)
+	if err != nil {
+		s.logger.Warn("Ticket for unknown booking", zap.String("booking_code", claims.BookingCode), zap.Error(err))
+		return nil, ErrInvalidTicket
+	}
+
+	// Signed ticket must still describe the booking as it is now
+	if booking.ShowtimeID != claims.ShowtimeID || strings.Join(seatLabels(booking), ",") != strings.Join(claims.Seats, ",") {
+		return nil, ErrInvalidTicket
+	}
+
+	if booking.Status != "confirmed" {
+		return nil, fmt.Errorf("booking is %s", booking.Status)
+	}
+
+	if booking.CheckedInAt != nil {
+		return nil, ErrTicketAlreadyUsed
+	}
+
+	now := time.Now()
+	checkedIn, err := s.bookingRepo.MarkCheckedIn(ctx, booking.ID, now)
+	if err != nil {
+		s.logger.Error("Failed to check in booking", zap.Int("booking_id", booking.ID), zap.Error(err))
+		return nil, fmt.Errorf("failed to check in: %w", err)
+	}
+	if !checkedIn {
+		// Same ticket scanned at another door at the same moment
+		return nil, ErrTicketAlreadyUsed
+	}
+
+	booking.CheckedInAt = &now
+
+	s.logger.Info("Booking checked in",
+		zap.Int("booking_id", booking.ID),
+		zap.String("booking_code", booking.BookingCode),
+		zap.Int("showtime_id", booking.ShowtimeID),
+	)
+
+	// GOROUTINE: Async logging to file
+	utils.LogBookingAsync(s.logger, booking.UserID, booking.BookingCode, "checked_in")
+
+	return booking, nil
+}
+
+// signTicket menghasilkan payload: TKT1|<booking code>|<showtime id>|<kursi>|<hmac>
+func (s *ticketService) signTicket(claims *domain.TicketClaims) string {
+	body := strings.Join([]string{
+		ticketPayloadVersion,
+		claims.BookingCode,
+		strconv.Itoa(claims.ShowtimeID),
+		strings.Join(claims.Seats, ","),
+	}, "|")
+
+	return body + "|" + utils.SignHMAC(s.config.Booking.TicketSecret, []byte(body))
+}
+
+// parseTicket memverifikasi signature dan membaca isi payload tiket
+func (s *ticketService) parseTicket(payload string) (*domain.TicketClaims, error) {
+	parts := strings.Split(payload, "|")
+	if len(parts) != 5 || parts[0] != ticketPayloadVersion {
+		return nil, fmt.Errorf("malformed ticket payload")
+	}
+
+	body := strings.Join(parts[:4], "|")
+	if !utils.VerifyHMAC(s.config.Booking.TicketSecret, []byte(body), parts[4]) {
+		return nil, fmt.Errorf("invalid ticket signature")
+	}
+
+	showtimeID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid showtime in ticket: %w", err)
+	}
+
+	return &domain.TicketClaims{
+		BookingCode: parts[1],
+		ShowtimeID:  showtimeID,
+		Seats:       strings.Split(parts[3], ","),
+	}, nil
+}
+
+// seatLabels mengembalikan label kursi booking (mis. A1, A2) sesuai urutan booking_seats
+func seatLabels(booking *domain.Booking) []string {
+	labels := make([]string, 0, len(booking.Seats))
+	for _, bs := range booking.Seats {
+		if bs.Seat != nil {
+			labels = append(labels, fmt.Sprintf("%s%d", bs.Seat.SeatRow, bs.Seat.SeatNumber))
+		} else {
+			labels = append(labels, strconv.Itoa(bs.SeatID))
+		}
+	}
+	return labels
+}
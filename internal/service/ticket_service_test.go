@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"project-app-bioskop-golang-homework-anas/internal/config"
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func testTicketConfig() *config.Config {
+	return &config.Config{
+		Booking: config.BookingConfig{
+			TicketSecret: "test-ticket-secret",
+		},
+	}
+}
+
+// ticketBooking membuat booking confirmed dengan dua kursi (A1, A2)
+func ticketBooking() *domain.Booking {
+	return &domain.Booking{
+		ID:          1,
+		UserID:      1,
+		ShowtimeID:  7,
+		BookingCode: "BK001",
+		Status:      "confirmed",
+		Seats: []*domain.BookingSeat{
+			{SeatID: 10, Seat: &domain.Seat{ID: 10, SeatRow: "A", SeatNumber: 1}},
+			{SeatID: 11, Seat: &domain.Seat{ID: 11, SeatRow: "A", SeatNumber: 2}},
+		},
+	}
+}
+
+func newTestTicketService(repo *MockBookingRepository) *ticketService {
+	return NewTicketService(repo, testTicketConfig(), zap.NewNop()).(*ticketService)
+}
+
+func TestTicketService_GetTicketQRCode_Success(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(ticketBooking(), nil)
+
+	png, err := service.GetTicketQRCode(ctx, 1, "BK001")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("\x89PNG"), png[:4])
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestTicketService_GetTicketQRCode_NotConfirmed(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	booking := ticketBooking()
+	booking.Status = "pending"
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(booking, nil)
+
+	png, err := service.GetTicketQRCode(ctx, 1, "BK001")
+
+	assert.ErrorIs(t, err, ErrTicketNotAvailable)
+	assert.Nil(t, png)
+}
+
+func TestTicketService_GetTicketQRCode_NotOwner(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(ticketBooking(), nil)
+
+	_, err := service.GetTicketQRCode(ctx, 2, "BK001")
+
+	assert.ErrorIs(t, err, ErrBookingNotOwned)
+}
+
+func TestTicketService_CheckIn_Success(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1", "A2"}})
+
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(ticketBooking(), nil)
+	mockBookingRepo.On("MarkCheckedIn", ctx, 1, mock.AnythingOfType("time.Time")).Return(true, nil)
+
+	result, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: payload, ShowtimeID: 7})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result.CheckedInAt)
+	mockBookingRepo.AssertExpectations(t)
+}
+
+func TestTicketService_CheckIn_TamperedPayload(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1"}})
+	// Add a seat to the signed ticket
+	tampered := "TKT1|BK001|7|A1,A2|" + payload[len("TKT1|BK001|7|A1|"):]
+
+	result, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: tampered, ShowtimeID: 7})
+
+	assert.ErrorIs(t, err, ErrInvalidTicket)
+	assert.Nil(t, result)
+	mockBookingRepo.AssertNotCalled(t, "GetByCode", mock.Anything, mock.Anything)
+}
+
+func TestTicketService_CheckIn_WrongShowtime(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1", "A2"}})
+
+	_, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: payload, ShowtimeID: 8})
+
+	assert.ErrorIs(t, err, ErrWrongShowtime)
+}
+
+func TestTicketService_CheckIn_AlreadyUsed(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1", "A2"}})
+
+	booking := ticketBooking()
+	usedAt := time.Now().Add(-time.Minute)
+	booking.CheckedInAt = &usedAt
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(booking, nil)
+
+	_, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: payload, ShowtimeID: 7})
+
+	assert.ErrorIs(t, err, ErrTicketAlreadyUsed)
+	mockBookingRepo.AssertNotCalled(t, "MarkCheckedIn", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTicketService_CheckIn_ConcurrentScan(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1", "A2"}})
+
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(ticketBooking(), nil)
+	mockBookingRepo.On("MarkCheckedIn", ctx, 1, mock.AnythingOfType("time.Time")).Return(false, nil)
+
+	_, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: payload, ShowtimeID: 7})
+
+	assert.ErrorIs(t, err, ErrTicketAlreadyUsed)
+}
+
+func TestTicketService_CheckIn_CancelledBooking(t *testing.T) {
+	mockBookingRepo := new(MockBookingRepository)
+	service := newTestTicketService(mockBookingRepo)
+
+	ctx := context.Background()
+	payload := service.signTicket(&domain.TicketClaims{BookingCode: "BK001", ShowtimeID: 7, Seats: []string{"A1", "A2"}})
+
+	booking := ticketBooking()
+	booking.Status = "cancelled"
+	mockBookingRepo.On("GetByCode", ctx, "BK001").Return(booking, nil)
+
+	_, err := service.CheckIn(ctx, &domain.CheckInRequest{Payload: payload, ShowtimeID: 7})
+
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrTicketAlreadyUsed))
+	assert.Contains(t, err.Error(), "cancelled")
+}
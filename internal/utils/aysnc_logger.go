@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"project-app-bioskop-golang-homework-anas/internal/domain"
+
 	"go.uber.org/zap"
 )
 
@@ -54,11 +56,11 @@ func LogBookingAsync(logger *zap.Logger, userID int, bookingCode string, action
 }
 
 // LogPaymentAsync mencatat payment activity secara async
-func LogPaymentAsync(logger *zap.Logger, bookingID int, amount float64, paymentMethod string) {
+func LogPaymentAsync(logger *zap.Logger, bookingID int, amount domain.Money, paymentMethod string) {
 	go func() {
 		data := map[string]interface{}{
 			"booking_id":     bookingID,
-			"amount":         amount,
+			"amount":         amount.String(),
 			"payment_method": paymentMethod,
 			"timestamp":      time.Now().Format(time.RFC3339),
 		}
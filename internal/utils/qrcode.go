@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// GenerateQRCode meng-encode content menjadi gambar PNG QR code
+func GenerateQRCode(content string, size int) ([]byte, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+	return png, nil
+}
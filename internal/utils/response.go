@@ -74,11 +74,26 @@ func SendUnauthorized(w http.ResponseWriter, message string) {
 	SendError(w, http.StatusUnauthorized, message, nil)
 }
 
+// SendForbidden mengirim response forbidden (403)
+func SendForbidden(w http.ResponseWriter, message string) {
+	SendError(w, http.StatusForbidden, message, nil)
+}
+
 // SendNotFound mengirim response not found (404)
 func SendNotFound(w http.ResponseWriter, message string) {
 	SendError(w, http.StatusNotFound, message, nil)
 }
 
+// SendConflict mengirim response conflict (409)
+func SendConflict(w http.ResponseWriter, message string) {
+	SendError(w, http.StatusConflict, message, nil)
+}
+
+// SendGone mengirim response gone (410)
+func SendGone(w http.ResponseWriter, message string) {
+	SendError(w, http.StatusGone, message, nil)
+}
+
 // SendInternalServerError mengirim response internal server error (500)
 func SendInternalServerError(w http.ResponseWriter, message string, err error) {
 	SendError(w, http.StatusInternalServerError, message, err)
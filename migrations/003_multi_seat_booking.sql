@@ -0,0 +1,26 @@
+-- ================================================
+-- Multi-seat booking: satu booking (order) bisa berisi banyak kursi
+-- ================================================
+
+-- Table: booking_seats
+CREATE TABLE IF NOT EXISTS booking_seats (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    showtime_id INTEGER NOT NULL REFERENCES showtimes(id) ON DELETE CASCADE,
+    seat_id INTEGER NOT NULL REFERENCES seats(id) ON DELETE CASCADE,
+    price DECIMAL(10, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(showtime_id, seat_id) -- prevent double booking untuk showtime yang sama
+);
+
+-- Pindahkan kursi dari booking lama ke booking_seats
+INSERT INTO booking_seats (booking_id, showtime_id, seat_id, price, created_at)
+SELECT id, showtime_id, seat_id, total_price, created_at
+FROM bookings;
+
+-- Kursi sekarang disimpan di booking_seats
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_showtime_id_seat_id_key;
+ALTER TABLE bookings DROP COLUMN IF EXISTS seat_id;
+
+CREATE INDEX idx_booking_seats_booking_id ON booking_seats(booking_id);
+CREATE INDEX idx_booking_seats_showtime_id ON booking_seats(showtime_id);
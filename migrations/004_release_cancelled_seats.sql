@@ -0,0 +1,20 @@
+-- ================================================
+-- Kursi dari booking yang cancelled/expired kembali tersedia
+-- ================================================
+
+-- Kursi yang sudah dilepas ditandai dengan released_at (history tetap tersimpan)
+ALTER TABLE booking_seats ADD COLUMN IF NOT EXISTS released_at TIMESTAMP;
+
+-- Lepas kursi dari booking yang sudah tidak aktif
+UPDATE booking_seats bs
+SET released_at = b.updated_at
+FROM bookings b
+WHERE bs.booking_id = b.id
+  AND b.status NOT IN ('pending', 'confirmed')
+  AND bs.released_at IS NULL;
+
+-- Unique hanya untuk kursi yang masih aktif
+ALTER TABLE booking_seats DROP CONSTRAINT IF EXISTS booking_seats_showtime_id_seat_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_booking_seats_active
+    ON booking_seats(showtime_id, seat_id)
+    WHERE released_at IS NULL;
@@ -0,0 +1,10 @@
+-- ================================================
+-- Batas waktu pembayaran untuk booking pending
+-- ================================================
+
+-- status booking: pending, confirmed, cancelled, expired
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_pending_expires_at
+    ON bookings(expires_at)
+    WHERE status = 'pending';
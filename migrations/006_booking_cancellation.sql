@@ -0,0 +1,7 @@
+-- ================================================
+-- Pembatalan booking dan refund
+-- ================================================
+
+-- status payment: pending, success, failed, refunded
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refund_amount DECIMAL(10, 2);
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
@@ -0,0 +1,7 @@
+-- ================================================
+-- Payment gateway: simpan reference transaksi dari provider
+-- ================================================
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS provider_reference VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_payments_provider_reference ON payments(provider_reference);
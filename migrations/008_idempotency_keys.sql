@@ -0,0 +1,44 @@
+-- ================================================
+-- Idempotency key: request yang di-retry dengan key yang sama
+-- mendapat response awal, bukan dieksekusi ulang
+-- ================================================
+
+-- Table: idempotency_keys
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL, -- sha256 dari method, path dan body
+    status_code INTEGER NOT NULL DEFAULT 0, -- 0 selama request masih diproses
+    response_body BYTEA,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Satu booking hanya boleh punya satu payment.
+-- Duplikat lama tidak dihapus: dipindahkan ke payments_duplicate_archive supaya tetap
+-- bisa direkonsiliasi (misalnya user yang terlanjur terdebit dua kali). Payment yang
+-- berhasil (atau yang pertama) tetap di tabel payments.
+CREATE TABLE IF NOT EXISTS payments_duplicate_archive (
+    LIKE payments INCLUDING DEFAULTS,
+    archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+WITH duplicates AS (
+    DELETE FROM payments p
+    WHERE p.id NOT IN (
+        SELECT DISTINCT ON (booking_id) id
+        FROM payments
+        ORDER BY booking_id, (status IN ('success', 'refunded')) DESC, id
+    )
+    RETURNING p.*
+)
+INSERT INTO payments_duplicate_archive
+SELECT d.*, CURRENT_TIMESTAMP
+FROM duplicates d;
+
+ALTER TABLE payments ADD CONSTRAINT payments_booking_id_key UNIQUE (booking_id);
@@ -0,0 +1,5 @@
+-- ================================================
+-- E-ticket: catat waktu check-in di pintu studio
+-- ================================================
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
@@ -0,0 +1,61 @@
+-- ================================================
+-- Studio (screen) di dalam cinema
+-- Kursi dan showtime sekarang milik studio, bukan langsung milik cinema
+-- ================================================
+
+CREATE TABLE IF NOT EXISTS screens (
+    id SERIAL PRIMARY KEY,
+    cinema_id INTEGER NOT NULL REFERENCES cinemas(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL, -- Studio 1, Studio 2, IMAX, dll
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(cinema_id, name),
+    UNIQUE(id, cinema_id) -- target foreign key (screen_id, cinema_id)
+);
+
+-- Satu studio default untuk setiap cinema yang sudah ada
+INSERT INTO screens (cinema_id, name)
+SELECT id, 'Studio 1' FROM cinemas
+ON CONFLICT (cinema_id, name) DO NOTHING;
+
+-- Seats: pindahkan kursi lama ke studio default
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS screen_id INTEGER;
+
+UPDATE seats st
+SET screen_id = sc.id
+FROM screens sc
+WHERE sc.cinema_id = st.cinema_id
+  AND sc.name = 'Studio 1'
+  AND st.screen_id IS NULL;
+
+ALTER TABLE seats ALTER COLUMN screen_id SET NOT NULL;
+
+-- cinema_id tetap disimpan, dan harus sama dengan cinema milik studio
+ALTER TABLE seats ADD CONSTRAINT seats_screen_id_fkey
+    FOREIGN KEY (screen_id, cinema_id) REFERENCES screens(id, cinema_id) ON DELETE CASCADE;
+
+-- Nomor kursi unik per studio (bukan per cinema)
+ALTER TABLE seats DROP CONSTRAINT IF EXISTS seats_cinema_id_seat_row_seat_number_key;
+ALTER TABLE seats ADD CONSTRAINT seats_screen_id_seat_row_seat_number_key
+    UNIQUE (screen_id, seat_row, seat_number);
+
+-- Showtimes: jadwal lama tayang di studio default
+ALTER TABLE showtimes ADD COLUMN IF NOT EXISTS screen_id INTEGER;
+
+UPDATE showtimes s
+SET screen_id = sc.id
+FROM screens sc
+WHERE sc.cinema_id = s.cinema_id
+  AND sc.name = 'Studio 1'
+  AND s.screen_id IS NULL;
+
+ALTER TABLE showtimes ALTER COLUMN screen_id SET NOT NULL;
+
+ALTER TABLE showtimes ADD CONSTRAINT showtimes_screen_id_fkey
+    FOREIGN KEY (screen_id, cinema_id) REFERENCES screens(id, cinema_id) ON DELETE CASCADE;
+
+ALTER TABLE showtimes DROP CONSTRAINT IF EXISTS showtimes_cinema_id_movie_id_show_date_show_time_key;
+ALTER TABLE showtimes ADD CONSTRAINT showtimes_screen_id_movie_id_show_date_show_time_key
+    UNIQUE (screen_id, movie_id, show_date, show_time);
+
+CREATE INDEX IF NOT EXISTS idx_seats_screen_id ON seats(screen_id);
+CREATE INDEX IF NOT EXISTS idx_showtimes_screen_id ON showtimes(screen_id);
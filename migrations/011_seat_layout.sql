@@ -0,0 +1,21 @@
+-- ================================================
+-- Denah kursi (seat map) per studio
+-- Grid studio (ukuran, lorong, sel kosong) disimpan di screens.layout,
+-- posisi dan atribut setiap kursi disimpan di tabel seats
+-- ================================================
+
+-- {"rows": 10, "columns": 14, "aisles": {"rows": [], "columns": [4, 11]}, "blocked_cells": [{"x": 0, "y": 9}]}
+ALTER TABLE screens ADD COLUMN IF NOT EXISTS layout JSONB;
+
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS grid_x INTEGER; -- kolom di grid (0-based)
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS grid_y INTEGER; -- baris di grid (0-based)
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS is_accessible BOOLEAN NOT NULL DEFAULT FALSE; -- kursi roda
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS is_blocked BOOLEAN NOT NULL DEFAULT FALSE; -- tidak dijual
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS pair_seat_id INTEGER REFERENCES seats(id) ON DELETE SET NULL; -- couple seat
+
+ALTER TABLE seats ADD CONSTRAINT seats_grid_position_check
+    CHECK ((grid_x IS NULL) = (grid_y IS NULL) AND (grid_x IS NULL OR (grid_x >= 0 AND grid_y >= 0)));
+
+-- Satu sel grid hanya untuk satu kursi. Deferred supaya import bisa menukar posisi kursi.
+ALTER TABLE seats ADD CONSTRAINT seats_screen_id_grid_position_key
+    UNIQUE (screen_id, grid_y, grid_x) DEFERRABLE INITIALLY DEFERRED;
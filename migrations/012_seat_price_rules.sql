@@ -0,0 +1,37 @@
+-- ================================================
+-- Harga kursi berdasarkan tipe kursi (regular, premium, vip)
+-- Rule paling spesifik yang dipakai: showtime > cinema > global,
+-- dan rule dengan batasan hari/jam lebih diutamakan dari rule tanpa batasan
+-- ================================================
+
+CREATE TABLE IF NOT EXISTS seat_price_rules (
+    id SERIAL PRIMARY KEY,
+    cinema_id INTEGER REFERENCES cinemas(id) ON DELETE CASCADE, -- NULL = semua cinema
+    showtime_id INTEGER REFERENCES showtimes(id) ON DELETE CASCADE, -- NULL = semua showtime
+    seat_type VARCHAR(20) NOT NULL, -- regular, vip, premium
+    multiplier DECIMAL(5,2), -- dikalikan dengan showtimes.price
+    price DECIMAL(10,2), -- harga absolut, mengabaikan showtimes.price
+    days_of_week INTEGER[], -- 0 = Minggu ... 6 = Sabtu, NULL = setiap hari
+    start_time TIME, -- jam tayang mulai (inklusif), NULL = sepanjang hari
+    end_time TIME, -- jam tayang selesai (eksklusif), boleh melewati tengah malam
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((multiplier IS NULL) <> (price IS NULL)),
+    CHECK (multiplier IS NULL OR multiplier > 0),
+    CHECK (price IS NULL OR price >= 0),
+    CHECK ((start_time IS NULL) = (end_time IS NULL)),
+    CHECK (days_of_week IS NULL OR days_of_week <@ ARRAY[0, 1, 2, 3, 4, 5, 6])
+);
+
+CREATE INDEX IF NOT EXISTS idx_seat_price_rules_cinema_id ON seat_price_rules(cinema_id);
+CREATE INDEX IF NOT EXISTS idx_seat_price_rules_showtime_id ON seat_price_rules(showtime_id);
+
+-- Rule global default
+INSERT INTO seat_price_rules (seat_type, multiplier) VALUES
+('regular', 1.00),
+('premium', 1.25),
+('vip', 1.50);
+
+-- Rincian harga yang dipakai saat booking dibuat
+ALTER TABLE booking_seats ADD COLUMN IF NOT EXISTS base_price DECIMAL(10,2);
+ALTER TABLE booking_seats ADD COLUMN IF NOT EXISTS price_multiplier DECIMAL(5,2);
+ALTER TABLE booking_seats ADD COLUMN IF NOT EXISTS price_rule_id INTEGER REFERENCES seat_price_rules(id) ON DELETE SET NULL;
@@ -0,0 +1,52 @@
+-- ================================================
+-- Promo code / voucher
+-- Pemakaian dicatat di promotion_redemptions. Batas pemakaian dihitung dari
+-- redemption yang masih aktif (booking cancelled/expired mengembalikan kuota).
+-- ================================================
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL, -- percent, fixed
+    discount_percent DECIMAL(5,2), -- untuk tipe percent
+    discount_amount DECIMAL(10,2), -- rupiah, untuk tipe fixed
+    max_discount DECIMAL(10,2), -- batas potongan untuk tipe percent
+    valid_from TIMESTAMP NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+    max_uses INTEGER, -- total pemakaian, NULL = tidak dibatasi
+    max_uses_per_user INTEGER, -- pemakaian per user, NULL = tidak dibatasi
+    movie_ids INTEGER[], -- NULL = semua film
+    cinema_ids INTEGER[], -- NULL = semua cinema
+    payment_methods VARCHAR(50)[], -- kode payment method, NULL = semua
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type IN ('percent', 'fixed')),
+    CHECK (
+        (discount_type = 'percent' AND discount_percent > 0 AND discount_percent <= 100 AND discount_amount IS NULL)
+        OR (discount_type = 'fixed' AND discount_amount > 0 AND discount_percent IS NULL)
+    ),
+    CHECK (valid_until > valid_from),
+    CHECK (max_uses IS NULL OR max_uses > 0),
+    CHECK (max_uses_per_user IS NULL OR max_uses_per_user > 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_code ON promotions(UPPER(code));
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id SERIAL PRIMARY KEY,
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    discount_amount DECIMAL(10,2) NOT NULL,
+    released_at TIMESTAMP, -- diisi saat booking cancelled/expired
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(booking_id) -- satu promo per booking
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_active
+    ON promotion_redemptions(promotion_id, user_id) WHERE released_at IS NULL;
+
+-- total_price sudah termasuk potongan
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS promo_code VARCHAR(50);
@@ -0,0 +1,15 @@
+-- ================================================
+-- Soft delete untuk data katalog yang dikelola admin
+-- Data lama tetap ada supaya riwayat booking dan e-ticket tidak rusak
+-- ================================================
+
+ALTER TABLE cinemas ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE movies ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE showtimes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE seats ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Jadwal yang dihapus tidak boleh menghalangi jadwal baru di slot yang sama
+ALTER TABLE showtimes DROP CONSTRAINT IF EXISTS showtimes_screen_id_movie_id_show_date_show_time_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_showtimes_screen_movie_slot
+    ON showtimes(screen_id, movie_id, show_date, show_time)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,49 @@
+-- ================================================
+-- Showtime tidak boleh bentrok di studio yang sama
+-- Studio terpakai dari jam mulai sampai film selesai (ends_at) ditambah buffer bersih-bersih
+-- ================================================
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE showtimes ADD COLUMN IF NOT EXISTS ends_at TIMESTAMP; -- jam mulai + durasi film
+ALTER TABLE showtimes ADD COLUMN IF NOT EXISTS cleaning_buffer_minutes INTEGER NOT NULL DEFAULT 0;
+
+-- Jadwal lama memakai buffer default SHOWTIME_CLEANING_BUFFER_MINUTES (15 menit)
+UPDATE showtimes s
+SET ends_at = s.show_date + s.show_time + m.duration * INTERVAL '1 minute',
+    cleaning_buffer_minutes = 15
+FROM movies m
+WHERE m.id = s.movie_id
+  AND s.ends_at IS NULL;
+
+ALTER TABLE showtimes ALTER COLUMN ends_at SET NOT NULL;
+ALTER TABLE showtimes ADD CONSTRAINT showtimes_cleaning_buffer_check CHECK (cleaning_buffer_minutes >= 0);
+
+-- Jadwal lama yang sudah bentrok tidak diubah otomatis: booking yang sudah dibayar tidak boleh
+-- dipindahkan diam-diam. Migration dibatalkan dan daftar showtime yang bentrok ditampilkan,
+-- admin memindahkan/menghapus salah satunya lalu menjalankan migration ini lagi.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(format('screen %s: showtime %s <-> %s', a.screen_id, a.id, b.id), E'\n' ORDER BY a.screen_id, a.id, b.id)
+    INTO conflicts
+    FROM showtimes a
+    JOIN showtimes b ON b.screen_id = a.screen_id
+                    AND b.id > a.id
+                    AND b.deleted_at IS NULL
+    WHERE a.deleted_at IS NULL
+      AND tsrange(a.show_date + a.show_time, a.ends_at + a.cleaning_buffer_minutes * INTERVAL '1 minute')
+          && tsrange(b.show_date + b.show_time, b.ends_at + b.cleaning_buffer_minutes * INTERVAL '1 minute');
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'overlapping showtimes must be resolved before adding showtimes_screen_no_overlap'
+            USING DETAIL = conflicts;
+    END IF;
+END $$;
+
+ALTER TABLE showtimes ADD CONSTRAINT showtimes_screen_no_overlap
+    EXCLUDE USING gist (
+        screen_id WITH =,
+        tsrange(show_date + show_time, ends_at + cleaning_buffer_minutes * INTERVAL '1 minute') WITH &&
+    ) WHERE (deleted_at IS NULL);
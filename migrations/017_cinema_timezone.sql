@@ -0,0 +1,10 @@
+-- ================================================
+-- Zona waktu per cinema (WIB, WITA, WIT)
+-- show_date/show_time tetap disimpan sebagai jam lokal cinema
+-- ================================================
+
+ALTER TABLE cinemas ADD COLUMN IF NOT EXISTS timezone VARCHAR(50) NOT NULL DEFAULT 'Asia/Jakarta';
+
+-- Contoh: cinema di Makassar (WITA) atau Jayapura (WIT)
+-- UPDATE cinemas SET timezone = 'Asia/Makassar' WHERE id = 6;
+-- UPDATE cinemas SET timezone = 'Asia/Jayapura' WHERE id = 7;